@@ -0,0 +1,67 @@
+package rules
+
+// AccessibleArea returns the number of free points that can be reached from a point by moving
+// up, down, left and right through free points, where a point is free if no snake that is
+// still in the game is on it. The point itself is only counted if it is free, so that it can
+// be the head of a snake. This is the flood fill that snakes commonly use to avoid moving into
+// spaces too small for them.
+func AccessibleArea(state *BoardState, from Point) int {
+	return accessibleArea(state, from, false)
+}
+
+// WrappedAccessibleArea is AccessibleArea on a wrapped board, where moving off one edge of the
+// board continues from the opposite edge.
+func WrappedAccessibleArea(state *BoardState, from Point) int {
+	return accessibleArea(state, from, true)
+}
+
+func accessibleArea(state *BoardState, from Point, wrap bool) int {
+	if state.Width <= 0 || state.Height <= 0 {
+		return 0
+	}
+	onBoard := func(p Point) bool {
+		return p.X >= 0 && p.X < state.Width && p.Y >= 0 && p.Y < state.Height
+	}
+	if !onBoard(from) {
+		return 0
+	}
+
+	visited := make([]bool, state.Width*state.Height)
+	index := func(p Point) int32 { return p.X*state.Height + p.Y }
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != NotEliminated {
+			continue
+		}
+		for _, p := range snake.Body {
+			if onBoard(p) {
+				visited[index(p)] = true
+			}
+		}
+	}
+
+	area := 0
+	if !visited[index(from)] {
+		area++
+	}
+	visited[index(from)] = true
+	queue := []Point{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, move := range directions {
+			dx, dy, _ := DirectionVector(move)
+			next := Point{X: p.X + dx, Y: p.Y + dy}
+			if wrap {
+				next.X = (next.X + state.Width) % state.Width
+				next.Y = (next.Y + state.Height) % state.Height
+			}
+			if !onBoard(next) || visited[index(next)] {
+				continue
+			}
+			visited[index(next)] = true
+			area++
+			queue = append(queue, next)
+		}
+	}
+	return area
+}
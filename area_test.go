@@ -0,0 +1,41 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessibleArea(t *testing.T) {
+	// One snake walls off the left of the board from the right, and another splits the left in two:
+	//
+	//   . . 1 . .
+	//   . . 1 . .
+	//   2 2 1 . .
+	//   . . 1 . .
+	//   . . 1 . .
+	state := &BoardState{
+		Width:  5,
+		Height: 5,
+		Food:   []Point{{0, 0}, {4, 4}},
+		Snakes: []Snake{
+			{ID: "one", Body: []Point{{2, 4}, {2, 3}, {2, 2}, {2, 1}, {2, 0}}},
+			{ID: "two", Body: []Point{{1, 2}, {0, 2}}},
+			{ID: "dead", Body: []Point{{4, 0}, {3, 0}}, EliminatedCause: EliminatedByOutOfHealth},
+		},
+	}
+
+	require.Equal(t, 4, AccessibleArea(state, Point{0, 0}))
+	require.Equal(t, 4, AccessibleArea(state, Point{1, 4}))
+	require.Equal(t, 10, AccessibleArea(state, Point{4, 0}))
+	// Heads aren't counted, only what they can reach.
+	require.Equal(t, 14, AccessibleArea(state, Point{2, 4}))
+	require.Equal(t, 8, AccessibleArea(state, Point{1, 2}))
+	require.Equal(t, 0, AccessibleArea(state, Point{5, 0}))
+
+	// On a wrapped board, the bottom left reaches the top left and the right of the board.
+	require.Equal(t, 18, WrappedAccessibleArea(state, Point{0, 0}))
+	require.Equal(t, 18, WrappedAccessibleArea(state, Point{4, 4}))
+
+	require.Equal(t, 0, AccessibleArea(&BoardState{}, Point{0, 0}))
+}
@@ -0,0 +1,61 @@
+package rules
+
+// AllowBodyCollisionsRuleset wraps another ruleset and lets snakes move through each other's bodies.
+// Self collisions, head-to-head collisions and leaving the board still eliminate snakes.
+type AllowBodyCollisionsRuleset struct {
+	Ruleset
+}
+
+func (r *AllowBodyCollisionsRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	nextBoardState, err := r.Ruleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.resurrectBodyCollisions(nextBoardState)
+	if err != nil {
+		return nil, err
+	}
+
+	return nextBoardState, nil
+}
+
+func (r *AllowBodyCollisionsRuleset) resurrectBodyCollisions(b *BoardState) error {
+	standard := StandardRuleset{}
+
+	var resurrected []int
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		if snake.EliminatedCause == EliminatedByCollision {
+			snake.EliminatedCause = NotEliminated
+			snake.EliminatedBy = ""
+			resurrected = append(resurrected, i)
+		}
+	}
+
+	// Body collisions are checked before head-to-heads, so resurrected snakes
+	// still need to be checked for head-to-heads they would have lost.
+	for _, i := range resurrected {
+		snake := &b.Snakes[i]
+		if len(snake.Body) == 0 {
+			return ErrorZeroLengthSnake
+		}
+		for j := 0; j < len(b.Snakes); j++ {
+			other := &b.Snakes[j]
+			if i == j || len(other.Body) == 0 {
+				continue
+			}
+			if other.EliminatedCause != NotEliminated && other.EliminatedCause != EliminatedByHeadToHeadCollision {
+				continue
+			}
+			if standard.snakeHasLostHeadToHead(snake, other) {
+				snake.EliminatedCause = EliminatedByHeadToHeadCollision
+				snake.EliminatedBy = other.ID
+				break
+			}
+		}
+	}
+
+	return nil
+}
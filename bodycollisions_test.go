@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowBodyCollisionsRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*AllowBodyCollisionsRuleset)(nil)
+}
+
+func TestAllowBodyCollisions(t *testing.T) {
+	// Each snake moves its head into the other snake's body.
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "one", Body: []Point{{1, 1}, {1, 0}, {0, 0}}, Health: 100},
+			{ID: "two", Body: []Point{{2, 0}, {2, 1}, {2, 2}}, Health: 100},
+		},
+	}
+	moves := []SnakeMove{
+		{ID: "one", Move: MoveRight},
+		{ID: "two", Move: MoveLeft},
+	}
+
+	standard := &StandardRuleset{}
+	next, err := standard.CreateNextBoardState(prev, moves)
+	require.NoError(t, err)
+	require.Equal(t, EliminatedByCollision, next.Snakes[0].EliminatedCause)
+	require.Equal(t, "two", next.Snakes[0].EliminatedBy)
+	require.Equal(t, EliminatedByCollision, next.Snakes[1].EliminatedCause)
+	require.Equal(t, "one", next.Snakes[1].EliminatedBy)
+
+	r := AllowBodyCollisionsRuleset{Ruleset: standard}
+	next, err = r.CreateNextBoardState(prev, moves)
+	require.NoError(t, err)
+	for _, snake := range next.Snakes {
+		require.Equal(t, NotEliminated, snake.EliminatedCause)
+		require.Equal(t, "", snake.EliminatedBy)
+	}
+}
+
+func TestAllowBodyCollisionsHeadToHead(t *testing.T) {
+	// The short snake collides with the long snake's body and head at the same time.
+	b := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "short", Body: []Point{{2, 2}, {1, 2}}, Health: 100, EliminatedCause: EliminatedByCollision, EliminatedBy: "other"},
+			{ID: "long", Body: []Point{{2, 2}, {3, 2}, {4, 2}}, Health: 100},
+			{ID: "other", Body: []Point{{2, 3}, {2, 2}, {2, 1}}, Health: 100, EliminatedCause: EliminatedBySelfCollision},
+		},
+	}
+
+	r := AllowBodyCollisionsRuleset{Ruleset: &StandardRuleset{}}
+	require.NoError(t, r.resurrectBodyCollisions(b))
+	require.Equal(t, EliminatedByHeadToHeadCollision, b.Snakes[0].EliminatedCause)
+	require.Equal(t, "long", b.Snakes[0].EliminatedBy)
+	require.Equal(t, NotEliminated, b.Snakes[1].EliminatedCause)
+	require.Equal(t, EliminatedBySelfCollision, b.Snakes[2].EliminatedCause)
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+)
+
+// logHeadToHeadAudit logs every head-to-head collision, then how many each snake won, lost and drew.
+func logHeadToHeadAudit(o *Options, audit []rules.HeadToHead) {
+	type tally struct{ won, lost, drew int }
+	var ids []string
+	tallies := make(map[string]*tally)
+	for _, collision := range audit {
+		var losers []string
+		var winner string
+		for _, snake := range collision.Snakes {
+			label := fmt.Sprintf("%v (len=%v)", displayName(o.Battlesnakes[snake.ID]), snake.Length)
+			if _, ok := tallies[snake.ID]; !ok {
+				ids = append(ids, snake.ID)
+				tallies[snake.ID] = &tally{}
+			}
+			switch {
+			case collision.Winner == "":
+				tallies[snake.ID].drew++
+				losers = append(losers, label)
+			case snake.ID == collision.Winner:
+				tallies[snake.ID].won++
+				winner = label
+			default:
+				tallies[snake.ID].lost++
+				losers = append(losers, label)
+			}
+		}
+		if winner == "" {
+			o.Log("[AUDIT]: [%v]: %v drew head-to-head at (%v,%v)", collision.Turn, strings.Join(losers, " and "), collision.Point.X, collision.Point.Y)
+		} else {
+			o.Log("[AUDIT]: [%v]: %v beat %v head-to-head at (%v,%v) by length %v", collision.Turn, winner, strings.Join(losers, " and "), collision.Point.X, collision.Point.Y, collision.LengthDifference)
+		}
+	}
+	for _, id := range ids {
+		t := tallies[id]
+		o.Log("[AUDIT]: %v won %v, lost %v and drew %v head-to-heads", displayName(o.Battlesnakes[id]), t.won, t.lost, t.drew)
+	}
+}
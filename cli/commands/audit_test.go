@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogHeadToHeadAudit(t *testing.T) {
+	l := new(testLog)
+	o := &Options{
+		Log: l.Log,
+		Battlesnakes: map[string]Battlesnake{
+			"a": {Name: "alpha"},
+			"b": {Name: "beta"},
+		},
+	}
+	logHeadToHeadAudit(o, []rules.HeadToHead{
+		{Turn: 4, Point: rules.Point{X: 2, Y: 3}, Winner: "a", LengthDifference: 2, Snakes: []rules.HeadToHeadSnake{{ID: "a", Length: 5}, {ID: "b", Length: 3}}},
+		{Turn: 9, Point: rules.Point{X: 1, Y: 1}, Snakes: []rules.HeadToHeadSnake{{ID: "a", Length: 4}, {ID: "b", Length: 4}}},
+	})
+	require.Equal(t, []string{
+		"[AUDIT]: [4]: alpha (len=5) beat beta (len=3) head-to-head at (2,3) by length 2",
+		"[AUDIT]: [9]: alpha (len=4) and beta (len=4) drew head-to-head at (1,1)",
+		"[AUDIT]: alpha won 1, lost 0 and drew 1 head-to-heads",
+		"[AUDIT]: beta won 0, lost 1 and drew 1 head-to-heads",
+	}, l.lines)
+}
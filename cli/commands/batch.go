@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// RunBatch plays o.Games games one after another, using consecutive seeds starting at o.Seed.
+// With o.Parallel above one, up to that many games are played at once, each with its own copy
+// of the options, and the results are the same as playing them one after another. Games
+// played at once would write over each other's per-game files, so those outputs are refused.
+func RunBatch(o *Options) []Result {
+	games := o.Games
+	if games < 1 {
+		games = 1
+	}
+	if o.Parallel > 1 && games > 1 {
+		return runParallel(o, games)
+	}
+
+	seed := o.Seed
+	defer func() { o.Seed = seed }()
+
+	var results []Result
+	for i := 0; i < games; i++ {
+		o.Seed = seed + int64(i)
+		results = append(results, Run(o))
+	}
+	return results
+}
+
+func runParallel(o *Options, games int) []Result {
+	if flags := perGameOutputs(o); len(flags) > 0 {
+		log.Panicf("[PANIC]: %v Can't Be Used With --parallel Above 1", strings.Join(flags, ", "))
+	}
+
+	results := make([]Result, games)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.Parallel && w < games; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				game := *o
+				game.Seed = o.Seed + int64(i)
+				results[i] = Run(&game)
+			}
+		}()
+	}
+	for i := 0; i < games; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// perGameOutputs returns the flags set in o that write a file for a single game.
+func perGameOutputs(o *Options) []string {
+	var flags []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"--output", o.Output != ""},
+		{"--export-moves-only", o.ExportMovesOnly != ""},
+		{"--log-moves-csv", o.LogMovesCSV != ""},
+		{"--png-dir", o.PNGDir != ""},
+		{"--legend", o.Legend != ""},
+		{"--board-viewer", o.BoardViewer != ""},
+		{"--record-requests-responses", o.RecordHTTP != ""},
+		{"--snapshot", o.Snapshot != ""},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
+type WinnerStats struct {
+	Snakes []SnakeStats `json:"snakes"`
+	// HeadToHead counts the games in which the first snake outlasted the second.
+	HeadToHead map[string]map[string]int `json:"head_to_head"`
+}
+
+type SnakeStats struct {
+	Name         string  `json:"name"`
+	Games        int     `json:"games"`
+	Wins         int     `json:"wins"`
+	Losses       int     `json:"losses"`
+	Draws        int     `json:"draws"`
+	WinRate      float64 `json:"win_rate"`
+	AvgTurnsWon  float64 `json:"avg_turns_won"`
+	AvgTurnsLost float64 `json:"avg_turns_lost"`
+}
+
+// BuildWinnerStats aggregates the results of a batch by snake.
+func BuildWinnerStats(results []Result) WinnerStats {
+	return buildWinnerStats(results, false)
+}
+
+// BuildWinnerStatsDrawAsLoss is like BuildWinnerStats, but counts a draw as a loss for every
+// snake in the game, as with --draw-as-loss.
+func BuildWinnerStatsDrawAsLoss(results []Result) WinnerStats {
+	return buildWinnerStats(results, true)
+}
+
+func buildWinnerStats(results []Result, drawAsLoss bool) WinnerStats {
+	stats := WinnerStats{HeadToHead: make(map[string]map[string]int)}
+	index := make(map[string]int)
+	turnsWon := make(map[string]int32)
+	turnsLost := make(map[string]int32)
+
+	for _, res := range results {
+		for _, sr := range res.Snakes {
+			i, ok := index[sr.Name]
+			if !ok {
+				i = len(stats.Snakes)
+				index[sr.Name] = i
+				stats.Snakes = append(stats.Snakes, SnakeStats{Name: sr.Name})
+				stats.HeadToHead[sr.Name] = make(map[string]int)
+			}
+
+			s := &stats.Snakes[i]
+			s.Games++
+			switch {
+			case res.Winner == "" && !drawAsLoss:
+				s.Draws++
+			case res.Winner == sr.Name:
+				s.Wins++
+				turnsWon[sr.Name] += res.Turn
+			default:
+				s.Losses++
+				turnsLost[sr.Name] += res.Turn
+			}
+
+			for _, other := range res.Snakes {
+				if other.Name != sr.Name && survivalTurn(res, sr) > survivalTurn(res, other) {
+					stats.HeadToHead[sr.Name][other.Name]++
+				}
+			}
+		}
+	}
+
+	for i := range stats.Snakes {
+		s := &stats.Snakes[i]
+		s.WinRate = float64(s.Wins) / float64(s.Games)
+		if s.Wins > 0 {
+			s.AvgTurnsWon = float64(turnsWon[s.Name]) / float64(s.Wins)
+		}
+		if s.Losses > 0 {
+			s.AvgTurnsLost = float64(turnsLost[s.Name]) / float64(s.Losses)
+		}
+	}
+
+	return stats
+}
+
+// survivalTurn returns the turn a snake was eliminated on, or a turn after the end of the game if it survived.
+func survivalTurn(res Result, sr SnakeResult) int32 {
+	if sr.EliminatedCause == "" {
+		return res.Turn + 1
+	}
+	return sr.EliminatedTurn
+}
+
+// GameLengthStats describes the distribution of the number of turns games lasted.
+// Percentiles use the nearest rank.
+type GameLengthStats struct {
+	Games  int     `json:"games"`
+	Min    int32   `json:"min"`
+	P10    int32   `json:"p10"`
+	P25    int32   `json:"p25"`
+	Median float64 `json:"median"`
+	P75    int32   `json:"p75"`
+	P90    int32   `json:"p90"`
+	Max    int32   `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+func BuildGameLengthStats(results []Result) GameLengthStats {
+	stats := GameLengthStats{Games: len(results)}
+	if len(results) == 0 {
+		return stats
+	}
+	turns := make([]int32, len(results))
+	var total int64
+	for i, res := range results {
+		turns[i] = res.Turn
+		total += int64(res.Turn)
+	}
+	sort.Slice(turns, func(i, j int) bool { return turns[i] < turns[j] })
+
+	n := len(turns)
+	percentile := func(p int) int32 {
+		rank := (p*n + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return turns[rank-1]
+	}
+	stats.Min, stats.Max = turns[0], turns[n-1]
+	stats.P10, stats.P25, stats.P75, stats.P90 = percentile(10), percentile(25), percentile(75), percentile(90)
+	if n%2 == 1 {
+		stats.Median = float64(turns[n/2])
+	} else {
+		stats.Median = float64(turns[n/2-1]+turns[n/2]) / 2
+	}
+	stats.Mean = float64(total) / float64(n)
+	return stats
+}
+
+func printGameLengthStats(o *Options, stats GameLengthStats) {
+	if o.WinnerStatsFormat == "json" {
+		b, err := json.Marshal(stats)
+		if err != nil {
+			o.Log("[WARN]: Unable to marshal game length stats: %v", err)
+			return
+		}
+		o.Log("%s", b)
+		return
+	}
+	o.Log("Game Lengths: games=%v min=%v p10=%v p25=%v median=%v p75=%v p90=%v max=%v mean=%.1f",
+		stats.Games, stats.Min, stats.P10, stats.P25, stats.Median, stats.P75, stats.P90, stats.Max, stats.Mean)
+}
+
+func printWinnerStats(o *Options, stats WinnerStats) {
+	if o.WinnerStatsFormat == "json" {
+		b, err := json.Marshal(stats)
+		if err != nil {
+			o.Log("[WARN]: Unable to marshal winner stats: %v", err)
+			return
+		}
+		o.Log("%s", b)
+		return
+	}
+	o.Log("%s", formatWinnerStats(stats))
+}
+
+func formatWinnerStats(stats WinnerStats) string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Snake\tGames\tWins\tLosses\tDraws\tWin Rate\tAvg Turns Won\tAvg Turns Lost")
+	for _, s := range stats.Snakes {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%.1f%%\t%.1f\t%.1f\n", s.Name, s.Games, s.Wins, s.Losses, s.Draws, s.WinRate*100, s.AvgTurnsWon, s.AvgTurnsLost)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprint(w, "Outlasted")
+	for _, s := range stats.Snakes {
+		fmt.Fprintf(w, "\t%v", s.Name)
+	}
+	fmt.Fprintln(w)
+	for _, s := range stats.Snakes {
+		fmt.Fprint(w, s.Name)
+		for _, other := range stats.Snakes {
+			if other.Name == s.Name {
+				fmt.Fprint(w, "\t-")
+			} else {
+				fmt.Fprintf(w, "\t%v", stats.HeadToHead[s.Name][other.Name])
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	_ = w.Flush()
+	return b.String()
+}
@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+// squareMove moves a snake around a 2x2 square, which keeps it alive until it eats twice.
+func squareMove(p ResponsePayload) string {
+	return []string{rules.MoveLeft, rules.MoveUp, rules.MoveRight, rules.MoveDown}[p.Turn%4]
+}
+
+func upMove(p ResponsePayload) string {
+	return rules.MoveUp
+}
+
+func TestRunBatchWinnerStats(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up1 := newTestSnake(t, upMove)
+	up2 := newTestSnake(t, upMove)
+
+	o := &Options{
+		Width:      rules.BoardSizeSmall,
+		Height:     rules.BoardSizeSmall,
+		Names:      []string{"square", "up1", "up2"},
+		URLs:       []string{square.URL, up1.URL, up2.URL},
+		GameType:   "standard",
+		Sequential: true,
+		Seed:       10,
+		Games:      6,
+		Log:        new(testLog).Log,
+	}
+	results := RunBatch(o)
+	require.Len(t, results, 6)
+	require.Equal(t, int64(10), o.Seed)
+
+	stats := BuildWinnerStats(results)
+	require.Len(t, stats.Snakes, 3)
+
+	var wins, draws int
+	for _, res := range results {
+		if res.Winner == "" {
+			draws++
+		}
+	}
+	for _, s := range stats.Snakes {
+		require.Equal(t, 6, s.Games)
+		require.Equal(t, s.Games, s.Wins+s.Losses+s.Draws)
+		require.Equal(t, draws, s.Draws)
+		require.InDelta(t, float64(s.Wins)/6, s.WinRate, 1e-9)
+		wins += s.Wins
+	}
+	require.Equal(t, 6, wins+draws)
+	require.Equal(t, "square", stats.Snakes[0].Name)
+	require.Equal(t, 6, stats.Snakes[0].Wins)
+
+	for _, a := range stats.Snakes {
+		for _, b := range stats.Snakes {
+			if a.Name == b.Name {
+				continue
+			}
+			// Each pair either has a clear winner or a tie in every game.
+			require.LessOrEqual(t, stats.HeadToHead[a.Name][b.Name]+stats.HeadToHead[b.Name][a.Name], 6)
+			// A snake that won a game outlasted every other snake in it.
+			require.GreaterOrEqual(t, stats.HeadToHead[a.Name][b.Name], a.Wins)
+		}
+	}
+
+	table := formatWinnerStats(stats)
+	require.Contains(t, table, "square  6      6     0       0      100.0%")
+	require.Contains(t, table, "square     -       6    6")
+}
+
+func TestRunBatchDrawAsLoss(t *testing.T) {
+	// Snakes start one cell from an edge of the small board and move out over it, so both are
+	// eliminated on the same turn and every game is a draw.
+	wall := newTestSnake(t, func(p ResponsePayload) string {
+		switch head := p.You.Head; {
+		case head.X <= 1:
+			return rules.MoveLeft
+		case head.X >= p.Board.Width-2:
+			return rules.MoveRight
+		case head.Y <= 1:
+			return rules.MoveDown
+		default:
+			return rules.MoveUp
+		}
+	})
+	o := &Options{
+		Width:      rules.BoardSizeSmall,
+		Height:     rules.BoardSizeSmall,
+		Names:      []string{"one", "two"},
+		URLs:       []string{wall.URL, wall.URL},
+		GameType:   "standard",
+		Sequential: true,
+		Seed:       1,
+		Games:      3,
+		Log:        new(testLog).Log,
+	}
+	results := RunBatch(o)
+	require.Len(t, results, 3)
+	for _, res := range results {
+		require.Empty(t, res.Winner)
+	}
+
+	stats := BuildWinnerStats(results)
+	for _, s := range stats.Snakes {
+		require.Equal(t, SnakeStats{Name: s.Name, Games: 3, Draws: 3}, s)
+	}
+
+	stats = BuildWinnerStatsDrawAsLoss(results)
+	require.Len(t, stats.Snakes, 2)
+	for _, s := range stats.Snakes {
+		require.Equal(t, SnakeStats{Name: s.Name, Games: 3, Losses: 3, AvgTurnsLost: 2}, s)
+	}
+}
+
+func TestRunBatchParallel(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	newOptions := func(parallel int) *Options {
+		return &Options{
+			Width:        rules.BoardSizeSmall,
+			Height:       rules.BoardSizeSmall,
+			Names:        []string{"square"},
+			URLs:         []string{square.URL},
+			RandomSnakes: 3,
+			GameType:     "standard",
+			Seed:         20,
+			Games:        8,
+			Parallel:     parallel,
+			Log:          new(testLog).Log,
+		}
+	}
+	// Snake IDs are random, so only compare what doesn't depend on them.
+	summarize := func(results []Result) []interface{} {
+		var summary []interface{}
+		for _, res := range results {
+			summary = append(summary, res.Turn, res.Winner, res.Snakes, res.EndReason, res.Board.Food)
+		}
+		return summary
+	}
+
+	sequential := RunBatch(newOptions(1))
+	parallel := RunBatch(newOptions(4))
+	require.Len(t, parallel, 8)
+	require.Equal(t, summarize(sequential), summarize(parallel))
+
+	// Games played at once can't share per-game files.
+	o := newOptions(4)
+	o.Output = filepath.Join(t.TempDir(), "game.ndjson")
+	o.LogMovesCSV = filepath.Join(t.TempDir(), "moves.csv")
+	require.PanicsWithValue(t, "[PANIC]: --output, --log-moves-csv Can't Be Used With --parallel Above 1", func() { RunBatch(o) })
+
+	o = newOptions(4)
+	o.RecordHTTP = filepath.Join(t.TempDir(), "requests.jsonl")
+	o.Snapshot = filepath.Join(t.TempDir(), "snapshot.json")
+	require.PanicsWithValue(t, "[PANIC]: --record-requests-responses, --snapshot Can't Be Used With --parallel Above 1", func() { RunBatch(o) })
+	_, err := os.Stat(o.Output)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestPlayGamesSummaryOnly(t *testing.T) {
+	l := new(testLog)
+	o := &Options{
+		Width:        rules.BoardSizeSmall,
+		Height:       rules.BoardSizeSmall,
+		GameType:     "standard",
+		Seed:         1,
+		Games:        10,
+		RandomSnakes: 3,
+		SummaryOnly:  true,
+		Log:          l.Log,
+	}
+	playGames(o)
+
+	require.Len(t, l.lines, 1)
+	require.True(t, strings.HasPrefix(l.lines[0], "Snake    Games  Wins"))
+	for _, name := range []string{"Random1", "Random2", "Random3"} {
+		require.Contains(t, l.lines[0], "\n"+name+"  10     ")
+	}
+
+	l = new(testLog)
+	o.WinnerStatsFormat = "json"
+	o.Log = l.Log
+	playGames(o)
+	require.Len(t, l.lines, 1)
+	var stats WinnerStats
+	require.NoError(t, json.Unmarshal([]byte(l.lines[0]), &stats))
+	require.Len(t, stats.Snakes, 3)
+	for _, s := range stats.Snakes {
+		require.Equal(t, 10, s.Games)
+	}
+}
+
+func TestPlayGamesLengthStats(t *testing.T) {
+	newOptions := func(l *testLog) *Options {
+		return &Options{
+			Width:             rules.BoardSizeSmall,
+			Height:            rules.BoardSizeSmall,
+			GameType:          "standard",
+			Seed:              5,
+			Games:             7,
+			RandomSnakes:      2,
+			SummaryOnly:       true,
+			LengthStats:       true,
+			WinnerStatsFormat: "json",
+			Log:               l.Log,
+		}
+	}
+	l := new(testLog)
+	playGames(newOptions(l))
+	require.Len(t, l.lines, 2)
+	var stats GameLengthStats
+	require.NoError(t, json.Unmarshal([]byte(l.lines[1]), &stats))
+
+	var turns []int
+	for _, res := range RunBatch(newOptions(new(testLog))) {
+		turns = append(turns, int(res.Turn))
+	}
+	sort.Ints(turns)
+	require.Equal(t, 7, stats.Games)
+	require.Equal(t, float64(turns[3]), stats.Median)
+	require.Equal(t, int32(turns[0]), stats.Min)
+	require.Equal(t, int32(turns[6]), stats.Max)
+}
+
+func TestBuildGameLengthStats(t *testing.T) {
+	var results []Result
+	for _, turn := range []int32{40, 10, 30, 20} {
+		results = append(results, Result{Turn: turn})
+	}
+	require.Equal(t, GameLengthStats{
+		Games:  4,
+		Min:    10,
+		P10:    10,
+		P25:    10,
+		Median: 25,
+		P75:    30,
+		P90:    40,
+		Max:    40,
+		Mean:   25,
+	}, BuildGameLengthStats(results))
+	require.Equal(t, GameLengthStats{}, BuildGameLengthStats(nil))
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Exchange is a request to a snake and its response, as recorded with --record-requests-responses.
+type Exchange struct {
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers"`
+	RequestBody     string      `json:"request_body"`
+	Status          int         `json:"status,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	LatencyMs       int64       `json:"latency_ms"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// captureTransport records every request and response as a line of JSON.
+type captureTransport struct {
+	base http.RoundTripper
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newCaptureTransport(base http.RoundTripper, w io.Writer) *captureTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &captureTransport{base: base, enc: json.NewEncoder(w)}
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := Exchange{
+		Time:           time.Now(),
+		Method:         req.Method,
+		URL:            req.URL.Redacted(),
+		RequestHeaders: redactHeaders(req.Header),
+	}
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		ex.RequestBody = string(b)
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		var b []byte
+		b, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+		ex.Status = resp.StatusCode
+		ex.ResponseHeaders = redactHeaders(resp.Header)
+		ex.ResponseBody = string(b)
+	}
+	ex.LatencyMs = time.Since(ex.Time).Milliseconds()
+	if err != nil {
+		ex.Error = err.Error()
+	}
+
+	t.mu.Lock()
+	_ = t.enc.Encode(ex)
+	t.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// redactHeaders copies headers, hiding the values of those that may hold credentials.
+func redactHeaders(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if isSecretHeader(name) {
+			values = []string{redacted}
+		}
+		out[name] = values
+	}
+	return out
+}
+
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "authorization", "proxy-authorization", "cookie", "set-cookie":
+		return true
+	}
+	for _, s := range []string{"token", "secret", "key", "password"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRecordHTTP(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	o := &Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"circler"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   3,
+		RecordHTTP: path,
+		Log:        new(testLog).Log,
+	}
+	client := &http.Client{}
+	o.HttpClient = client
+	Run(o)
+	require.Equal(t, client, o.HttpClient)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var moves []Exchange
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ex Exchange
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ex))
+		if strings.HasSuffix(ex.URL, "/move") {
+			moves = append(moves, ex)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, moves, 3)
+	for i, ex := range moves {
+		require.Equal(t, http.MethodPost, ex.Method)
+		require.Equal(t, http.StatusOK, ex.Status)
+
+		var payload ResponsePayload
+		require.NoError(t, json.Unmarshal([]byte(ex.RequestBody), &payload))
+		require.Equal(t, int32(i+1), payload.Turn)
+
+		var response PlayerResponse
+		require.NoError(t, json.Unmarshal([]byte(ex.ResponseBody), &response))
+		require.Equal(t, circleMove(payload), response.Move)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{
+		"Authorization":  {"Bearer abc"},
+		"X-Api-Key":      {"abc"},
+		"X-Snake-Token":  {"abc"},
+		"Content-Type":   {"application/json"},
+		"Content-Length": {"2"},
+	}
+	require.Equal(t, http.Header{
+		"Authorization":  {redacted},
+		"X-Api-Key":      {redacted},
+		"X-Snake-Token":  {redacted},
+		"Content-Type":   {"application/json"},
+		"Content-Length": {"2"},
+	}, redactHeaders(h))
+	require.Equal(t, "Bearer abc", h.Get("Authorization"))
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+)
+
+// ConformanceError lists the snakes that failed to move in a turn, with --fail-on-timeout.
+type ConformanceError struct {
+	Failures []string
+}
+
+func (e *ConformanceError) Error() string {
+	return "conformance failure: " + strings.Join(e.Failures, "; ")
+}
+
+// checkConformance returns a *ConformanceError if any snake still in the game didn't respond
+// with a valid move, instead of letting it fall back to its last move.
+func checkConformance(o *Options, state *rules.BoardState, results []moveResult) error {
+	alive := make(map[string]bool)
+	for _, snake := range aliveSnakes(state) {
+		alive[snake.ID] = true
+	}
+
+	var failures []string
+	for _, result := range results {
+		if !alive[result.ID] {
+			continue
+		}
+		name := o.Battlesnakes[result.ID].Name
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("%v failed to respond with a move: %v", name, result.Err))
+		} else if result.Fallback {
+			failures = append(failures, fmt.Sprintf("%v failed to respond with a move", name))
+		} else if _, _, ok := rules.DirectionVector(result.Move); !ok {
+			failures = append(failures, fmt.Sprintf("%v responded with invalid move %q", name, result.Move))
+		}
+	}
+	if len(failures) > 0 {
+		return &ConformanceError{Failures: failures}
+	}
+	return nil
+}
+
+// exitOnConformanceFailure exits with an error if any game was aborted by --fail-on-timeout,
+// so that CI fails.
+func exitOnConformanceFailure(results []Result) {
+	var failed int
+	for _, res := range results {
+		if res.EndReason == EndReasonConformanceFailure {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Fatalf("[FAIL]: Snakes failed conformance in %v of %v games", failed, len(results))
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFailOnTimeout(t *testing.T) {
+	fast := newTestSnake(t, squareMove)
+	slow := newTestSnake(t, func(p ResponsePayload) string {
+		if p.Turn == 2 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return squareMove(p)
+	})
+	l := new(testLog)
+	res := Run(&Options{
+		Width:         rules.BoardSizeMedium,
+		Height:        rules.BoardSizeMedium,
+		Names:         []string{"fast", "slow"},
+		URLs:          []string{fast.URL, slow.URL},
+		GameType:      "standard",
+		Sequential:    true,
+		Seed:          1,
+		Timeout:       100,
+		MaxTurns:      10,
+		FailOnTimeout: true,
+		Log:           l.Log,
+	})
+
+	require.Equal(t, EndReasonConformanceFailure, res.EndReason)
+	require.Equal(t, int32(1), res.Turn)
+	require.Len(t, res.ConformanceFailures, 1)
+	require.Contains(t, res.ConformanceFailures[0], "slow failed to respond with a move: ")
+	require.Equal(t, 1, l.Count("[CONFORMANCE]: [2]: "+res.ConformanceFailures[0]))
+	require.Equal(t, 1, l.Count("[DONE]: Game stopped (conformance-failure) after 1 turns."))
+}
+
+func TestCheckConformance(t *testing.T) {
+	o := &Options{Battlesnakes: map[string]Battlesnake{
+		"one": {ID: "one", Name: "one"},
+		"two": {ID: "two", Name: "two"},
+		"out": {ID: "out", Name: "out"},
+	}}
+	state := &rules.BoardState{Snakes: []rules.Snake{
+		{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}},
+		{ID: "two", Body: []rules.Point{{X: 3, Y: 3}}},
+		{ID: "out", Body: []rules.Point{{X: 5, Y: 5}}, EliminatedCause: rules.EliminatedByOutOfBounds},
+	}}
+	valid := []moveResult{
+		{SnakeMove: rules.SnakeMove{ID: "one", Move: rules.MoveUp}},
+		{SnakeMove: rules.SnakeMove{ID: "two", Move: rules.MoveLeft}},
+		// Eliminated snakes aren't checked.
+		{SnakeMove: rules.SnakeMove{ID: "out", Move: "sideways"}, Fallback: true},
+	}
+	require.NoError(t, checkConformance(o, state, valid))
+
+	invalid := []moveResult{
+		{SnakeMove: rules.SnakeMove{ID: "one", Move: "north"}},
+		{SnakeMove: rules.SnakeMove{ID: "two", Move: rules.MoveUp}, Fallback: true},
+	}
+	require.EqualError(t, checkConformance(o, state, invalid),
+		`conformance failure: one responded with invalid move "north"; two failed to respond with a move`)
+}
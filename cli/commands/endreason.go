@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"github.com/corverroos/bsrules"
+)
+
+// EndReason classifies why a game ended.
+type EndReason int
+
+const (
+	EndReasonUnknown EndReason = iota
+	// EndReasonLastSnakeStanding means a single snake, or a single squad, remains.
+	EndReasonLastSnakeStanding
+	// EndReasonAllEliminated means every snake was eliminated, so the game is a draw.
+	EndReasonAllEliminated
+	// EndReasonSoloEliminated means the snake in a solo game was eliminated.
+	EndReasonSoloEliminated
+	// EndReasonTurnLimit means the game was stopped by --max-turns before it was over.
+	EndReasonTurnLimit
+	// EndReasonInterrupted means the game was stopped with Ctrl-C before it was over.
+	EndReasonInterrupted
+	// EndReasonStalemate means no snake changed for --stalemate-turns turns, so the game is a draw.
+	EndReasonStalemate
+	// EndReasonTimedOut means the game ran longer than --max-duration before it was over.
+	EndReasonTimedOut
+	// EndReasonMinSnakesAlive means fewer snakes than --min-snakes-alive remained before the game was over.
+	EndReasonMinSnakesAlive
+	// EndReasonConformanceFailure means a snake timed out or returned an invalid move with --fail-on-timeout.
+	EndReasonConformanceFailure
+)
+
+func (r EndReason) String() string {
+	switch r {
+	case EndReasonLastSnakeStanding:
+		return "last-snake-standing"
+	case EndReasonAllEliminated:
+		return "all-eliminated"
+	case EndReasonSoloEliminated:
+		return "solo-eliminated"
+	case EndReasonTurnLimit:
+		return "turn-limit"
+	case EndReasonInterrupted:
+		return "interrupted"
+	case EndReasonStalemate:
+		return "stalemate"
+	case EndReasonTimedOut:
+		return "timed-out"
+	case EndReasonMinSnakesAlive:
+		return "min-snakes-alive"
+	case EndReasonConformanceFailure:
+		return "conformance-failure"
+	default:
+		return "unknown"
+	}
+}
+
+func (r EndReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func classifyEndReason(o *Options, ruleset rules.Ruleset, state *rules.BoardState) EndReason {
+	isGameOver, err := ruleset.IsGameOver(state)
+	if err != nil {
+		return EndReasonUnknown
+	}
+	if !isGameOver {
+		return EndReasonTurnLimit
+	}
+	if o.GameType == "solo" {
+		return EndReasonSoloEliminated
+	}
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			return EndReasonLastSnakeStanding
+		}
+	}
+	return EndReasonAllEliminated
+}
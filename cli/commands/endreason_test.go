@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyEndReason(t *testing.T) {
+	alive := rules.Snake{ID: "alive", Body: []rules.Point{{X: 1, Y: 1}}}
+	dead := rules.Snake{ID: "dead", Body: []rules.Point{{X: 2, Y: 2}}, EliminatedCause: rules.EliminatedByOutOfBounds}
+
+	tests := []struct {
+		GameType string
+		Snakes   []rules.Snake
+		Expected EndReason
+	}{
+		{"standard", []rules.Snake{alive, dead}, EndReasonLastSnakeStanding},
+		{"standard", []rules.Snake{dead, dead}, EndReasonAllEliminated},
+		{"standard", []rules.Snake{alive, alive}, EndReasonTurnLimit},
+		{"solo", []rules.Snake{dead}, EndReasonSoloEliminated},
+		{"solo", []rules.Snake{alive}, EndReasonTurnLimit},
+	}
+	for _, test := range tests {
+		o := &Options{GameType: test.GameType}
+		ruleset, _ := getRuleset(o, nil)
+		state := &rules.BoardState{Width: 5, Height: 5, Snakes: test.Snakes}
+		require.Equal(t, test.Expected, classifyEndReason(o, ruleset, state), test.Expected.String())
+	}
+}
+
+func TestEndReasonString(t *testing.T) {
+	require.Equal(t, "unknown", EndReasonUnknown.String())
+	require.Equal(t, "last-snake-standing", EndReasonLastSnakeStanding.String())
+	require.Equal(t, "all-eliminated", EndReasonAllEliminated.String())
+	require.Equal(t, "solo-eliminated", EndReasonSoloEliminated.String())
+	require.Equal(t, "turn-limit", EndReasonTurnLimit.String())
+	require.Equal(t, "timed-out", EndReasonTimedOut.String())
+	require.Equal(t, "min-snakes-alive", EndReasonMinSnakesAlive.String())
+	require.Equal(t, "conformance-failure", EndReasonConformanceFailure.String())
+}
+
+func TestRunMaxTurns(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	o := &Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"circler"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   10,
+		Log:        new(testLog).Log,
+	}
+	res := Run(o)
+	require.Equal(t, int32(10), res.Turn)
+	require.Equal(t, EndReasonTurnLimit, res.EndReason)
+
+	o.MaxTurns = 0
+	res = Run(o)
+	require.Equal(t, EndReasonSoloEliminated, res.EndReason)
+}
+
+func TestRunMaxDuration(t *testing.T) {
+	mux := newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		time.Sleep(20 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	})
+	var ends int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/end" {
+			atomic.AddInt32(&ends, 1)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	l := new(testLog)
+	res := Run(&Options{
+		Width:       2,
+		Height:      2,
+		Names:       []string{"slow"},
+		URLs:        []string{srv.URL},
+		GameType:    "solo",
+		Sequential:  true,
+		Seed:        1,
+		Timeout:     500,
+		MaxTurns:    100,
+		MaxDuration: 100 * time.Millisecond,
+		Log:         l.Log,
+	})
+	require.Equal(t, EndReasonTimedOut, res.EndReason)
+	require.Less(t, res.Turn, int32(100))
+	require.Equal(t, int32(1), atomic.LoadInt32(&ends))
+	require.Equal(t, 1, l.Count(fmt.Sprintf("[DONE]: Game stopped (timed-out) after %v turns.", res.Turn)))
+}
+
+func TestRunMinSnakesAlive(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	l := new(testLog)
+	res := Run(&Options{
+		Width:          rules.BoardSizeMedium,
+		Height:         rules.BoardSizeMedium,
+		Names:          []string{"square1", "square2", "square3", "up"},
+		URLs:           []string{square.URL, square.URL, square.URL, up.URL},
+		GameType:       "standard",
+		Sequential:     true,
+		Seed:           1,
+		MinSnakesAlive: 4,
+		Log:            l.Log,
+	})
+	require.Equal(t, EndReasonMinSnakesAlive, res.EndReason)
+	require.Empty(t, res.Winner)
+
+	// The game stops on the turn the up snake hits the wall, with the others still alive.
+	var survivors []string
+	for _, sr := range res.Snakes {
+		if sr.EliminatedCause == rules.NotEliminated {
+			survivors = append(survivors, sr.Name)
+		} else {
+			require.Equal(t, "up", sr.Name)
+			require.Equal(t, rules.EliminatedByOutOfBounds, sr.EliminatedCause)
+			require.Equal(t, res.Turn, sr.EliminatedTurn)
+		}
+	}
+	require.Equal(t, []string{"square1", "square2", "square3"}, survivors)
+	require.Equal(t, 1, l.Count(fmt.Sprintf("[DONE]: Game stopped (min-snakes-alive) after %v turns with 3 snakes alive: square1, square2, square3.", res.Turn)))
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+
+	"github.com/corverroos/bsrules"
+)
+
+// Event is a scripted change to a game at a turn, loaded from a JSON array with --events.
+// Events apply once the board of their turn has been created, so food appears on that turn.
+type Event struct {
+	Turn int32 `json:"turn"`
+	// Seed re-seeds the placement of food for the following turns. Royale hazards keep
+	// shrinking from the board seed.
+	Seed *int64 `json:"seed,omitempty"`
+	// Food is placed on the board, unless it is already there.
+	Food []rules.Point `json:"food,omitempty"`
+}
+
+func loadEvents(filename string) ([]Event, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	if err := json.Unmarshal(b, &events); err != nil {
+		return nil, fmt.Errorf("invalid events %v: %v", filename, err)
+	}
+	for i, event := range events {
+		if event.Turn < 0 {
+			return nil, fmt.Errorf("invalid events %v: event %v has negative turn %v", filename, i+1, event.Turn)
+		}
+		if event.Seed == nil && len(event.Food) == 0 {
+			return nil, fmt.Errorf("invalid events %v: event %v has no seed or food", filename, i+1)
+		}
+	}
+	return events, nil
+}
+
+// applyEvents applies the events of the current turn to the state.
+func applyEvents(o *Options, events []Event, state *rules.BoardState) {
+	for _, event := range events {
+		if event.Turn != o.Turn {
+			continue
+		}
+		if event.Seed != nil {
+			// Draws are counted from the new seed on.
+			o.randDraws = newCountingSource(*event.Seed)
+			o.rand = rand.New(o.randDraws)
+			o.Log("[EVENT]: [%v]: Re-seeded with %v", o.Turn, *event.Seed)
+		}
+		for _, p := range event.Food {
+			if p.X < 0 || p.Y < 0 || p.X >= state.Width || p.Y >= state.Height {
+				o.Log("[WARN]: [%v]: Scripted food at (%v,%v) is off the board", o.Turn, p.X, p.Y)
+				continue
+			}
+			if !containsPoint(state.Food, p) {
+				state.Food = append(state.Food, p)
+				o.Log("[EVENT]: [%v]: Placed food at (%v,%v)", o.Turn, p.X, p.Y)
+			}
+		}
+	}
+}
+
+func containsPoint(points []rules.Point, p rules.Point) bool {
+	for _, q := range points {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
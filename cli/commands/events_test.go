@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunEvents(t *testing.T) {
+	dir := t.TempDir()
+	events := filepath.Join(dir, "events.json")
+	require.NoError(t, ioutil.WriteFile(events, []byte(`[
+		{"turn": 2, "seed": 42},
+		{"turn": 3, "food": [{"X": 10, "Y": 0}, {"X": 0, "Y": 10}]}
+	]`), 0644))
+	output := filepath.Join(dir, "game.ndjson")
+
+	srv := newTestSnake(t, squareMove)
+	l := new(testLog)
+	Run(&Options{
+		Width:      rules.BoardSizeMedium,
+		Height:     rules.BoardSizeMedium,
+		Names:      []string{"square"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   4,
+		EventsFile: events,
+		Output:     output,
+		Log:        l.Log,
+	})
+
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	_, frames, err := readRecording(f)
+	require.NoError(t, err)
+	require.Len(t, frames, 5)
+	for _, c := range []Coord{{10, 0}, {0, 10}} {
+		require.NotContains(t, frames[2].Board.Food, c)
+		require.Contains(t, frames[3].Board.Food, c)
+	}
+	require.Equal(t, 1, l.Count("[EVENT]: [2]: Re-seeded with 42"))
+	require.Equal(t, 1, l.Count("[EVENT]: [3]: Placed food at (10,0)"))
+}
+
+func TestApplyEventsSeed(t *testing.T) {
+	o := &Options{Turn: 2, Log: new(testLog).Log}
+	o.randDraws = newCountingSource(1)
+	o.rand = rand.New(o.randDraws)
+	o.rand.Intn(10)
+
+	seed := int64(42)
+	applyEvents(o, []Event{{Turn: 2, Seed: &seed}}, &rules.BoardState{})
+	require.Equal(t, int64(0), o.randDraws.draws)
+	got := o.rand.Int63()
+	require.Equal(t, int64(1), o.randDraws.draws)
+	require.Equal(t, rand.New(rand.NewSource(seed)).Int63(), got)
+}
+
+func TestLoadEvents(t *testing.T) {
+	dir := t.TempDir()
+	write := func(s string) string {
+		path := filepath.Join(dir, "events.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(s), 0644))
+		return path
+	}
+
+	path := write(`[{"turn": 1, "seed": 0}]`)
+	events, err := loadEvents(path)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, int64(0), *events[0].Seed)
+
+	path = write(`[{"turn": 1}]`)
+	_, err = loadEvents(path)
+	require.EqualError(t, err, "invalid events "+path+": event 1 has no seed or food")
+
+	path = write(`[{"turn": -1, "seed": 1}]`)
+	_, err = loadEvents(path)
+	require.EqualError(t, err, "invalid events "+path+": event 1 has negative turn -1")
+}
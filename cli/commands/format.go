@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+// Output formats of recorded games. All formats hold the same header and frames,
+// with MessagePack using the JSON field names.
+const (
+	FormatNDJSON    = "ndjson"
+	FormatJSONLGzip = "jsonl-gzip"
+	FormatMsgpack   = "msgpack"
+)
+
+func isOutputFormat(format string) bool {
+	switch format {
+	case "", FormatNDJSON, FormatJSONLGzip, FormatMsgpack:
+		return true
+	}
+	return false
+}
+
+// recorder writes the header and frames of a recorded game in one of the output formats.
+type recorder struct {
+	encode  func(v interface{}) error
+	closers []io.Closer
+}
+
+func newRecorder(w io.WriteCloser, format string) (*recorder, error) {
+	switch format {
+	case "", FormatNDJSON:
+		return &recorder{encode: json.NewEncoder(w).Encode, closers: []io.Closer{w}}, nil
+	case FormatJSONLGzip:
+		gz := gzip.NewWriter(w)
+		return &recorder{encode: json.NewEncoder(gz).Encode, closers: []io.Closer{gz, w}}, nil
+	case FormatMsgpack:
+		enc := msgpack.NewEncoder(w).UseJSONTag(true)
+		return &recorder{encode: enc.Encode, closers: []io.Closer{w}}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %v", format)
+	}
+}
+
+func (r *recorder) writeHeader(header Header) error {
+	return r.encode(headerLine{Header: &header})
+}
+
+func (r *recorder) writeFrame(frame Frame) error {
+	return r.encode(frame)
+}
+
+// Close flushes compressed output and closes the underlying file.
+func (r *recorder) Close() error {
+	var firstErr error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// recordingLine is either a header or a frame of a MessagePack recording.
+type recordingLine struct {
+	Header *Header        `json:"header"`
+	Turn   int32          `json:"turn"`
+	Board  *BoardResponse `json:"board"`
+}
+
+// detectRecording returns a reader of the NDJSON of a recording, decompressing gzip,
+// or reports that the recording is MessagePack.
+func detectRecording(r io.Reader) (io.Reader, bool, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, false, err
+		}
+		return gz, false, nil
+	}
+	// Every MessagePack record is a map, which never starts with JSON's '{' or whitespace.
+	if len(magic) > 0 && (magic[0]&0xf0 == 0x80 || magic[0] == 0xde || magic[0] == 0xdf) {
+		return br, true, nil
+	}
+	return br, false, nil
+}
+
+func readMsgpackRecording(r io.Reader) (*Header, []Frame, error) {
+	var header *Header
+	var frames []Frame
+	dec := msgpack.NewDecoder(r).UseJSONTag(true)
+	for {
+		var line recordingLine
+		if err := dec.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if line.Header != nil {
+			if header != nil || len(frames) > 0 {
+				return nil, nil, fmt.Errorf("unexpected header after the start of the recording")
+			}
+			header = line.Header
+			continue
+		}
+		if line.Board == nil {
+			return nil, nil, fmt.Errorf("recording line %v is neither a header nor a frame", len(frames))
+		}
+		frames = append(frames, Frame{Turn: line.Turn, Board: *line.Board})
+	}
+	return header, frames, nil
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingFormats(t *testing.T) {
+	header := Header{
+		GameType: "standard",
+		Seed:     3,
+		Width:    7,
+		Height:   7,
+		Timeout:  500,
+		Ruleset:  []byte(`{"FoodSpawnChance":15,"MinimumFood":1}`),
+		Snakes:   []HeaderSnake{{ID: "a", Name: "one", URL: "http://one"}},
+	}
+	frames := []Frame{
+		{Turn: 0, Board: BoardResponse{
+			Width:   7,
+			Height:  7,
+			Food:    []Coord{{X: 3, Y: 3}},
+			Hazards: []Coord{},
+			Snakes:  []SnakeResponse{{Id: "a", Name: "one", Health: 100, Body: []Coord{{X: 1, Y: 1}, {X: 1, Y: 1}}, Latency: "0", Head: Coord{X: 1, Y: 1}, Length: 2}},
+		}},
+		{Turn: 1, Board: BoardResponse{
+			Width:   7,
+			Height:  7,
+			Food:    []Coord{{X: 3, Y: 3}},
+			Hazards: []Coord{{X: 0, Y: 0}},
+			Snakes:  []SnakeResponse{{Id: "a", Name: "one", Health: 99, Body: []Coord{{X: 1, Y: 2}, {X: 1, Y: 1}}, Latency: "0", Head: Coord{X: 1, Y: 2}, Length: 2}},
+		}},
+	}
+
+	for _, format := range []string{FormatNDJSON, FormatJSONLGzip, FormatMsgpack} {
+		t.Run(format, func(t *testing.T) {
+			path := writeTestFrames(t, nil)
+			f, err := os.Create(path)
+			require.NoError(t, err)
+			rec, err := newRecorder(f, format)
+			require.NoError(t, err)
+			require.NoError(t, rec.writeHeader(header))
+			for _, frame := range frames {
+				require.NoError(t, rec.writeFrame(frame))
+			}
+			require.NoError(t, rec.Close())
+
+			f, err = os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			readHeader, readFrames, err := readRecording(f)
+			require.NoError(t, err)
+			require.Equal(t, &header, readHeader)
+			require.Equal(t, frames, readFrames)
+		})
+	}
+
+	_, err := newRecorder(nil, "xml")
+	require.EqualError(t, err, "unknown output format xml")
+}
+
+func TestRunOutputFormat(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	path := writeTestFrames(t, nil)
+	res := Run(&Options{
+		Width:        2,
+		Height:       2,
+		Names:        []string{"packed"},
+		URLs:         []string{srv.URL},
+		GameType:     "solo",
+		Sequential:   true,
+		Seed:         1,
+		MaxTurns:     3,
+		Output:       path,
+		OutputFormat: FormatMsgpack,
+		Log:          new(testLog).Log,
+	})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	header, frames, err := readRecording(f)
+	require.NoError(t, err)
+	require.Equal(t, "solo", header.GameType)
+	require.Len(t, frames, int(res.Turn)+1)
+	require.Equal(t, "packed", frames[0].Board.Snakes[0].Name)
+}
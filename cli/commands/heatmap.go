@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"encoding/csv"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+)
+
+// Heatmap counts how often each cell of the board was occupied by a snake, over every
+// turn of one or more games.
+type Heatmap struct {
+	Width  int32
+	Height int32
+	// Counts are indexed by y*Width+x.
+	Counts []int
+}
+
+func newHeatmap(width, height int32) *Heatmap {
+	return &Heatmap{Width: width, Height: height, Counts: make([]int, width*height)}
+}
+
+// addState counts the cells occupied by each snake still in the game. Stacked segments
+// of a snake count once.
+func (h *Heatmap) addState(state *rules.BoardState) {
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		seen := make(map[rules.Point]bool, len(snake.Body))
+		for _, p := range snake.Body {
+			if seen[p] || p.X < 0 || p.Y < 0 || p.X >= h.Width || p.Y >= h.Height {
+				continue
+			}
+			seen[p] = true
+			h.Counts[p.Y*h.Width+p.X]++
+		}
+	}
+}
+
+func (h *Heatmap) At(x, y int32) int {
+	return h.Counts[y*h.Width+x]
+}
+
+func (h *Heatmap) Total() int {
+	var total int
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+// BuildHeatmap adds up the heatmaps of games on boards of the same size. Games on boards
+// of a different size than the first are skipped.
+func BuildHeatmap(results []Result) *Heatmap {
+	var heatmap *Heatmap
+	for _, res := range results {
+		if res.Occupancy == nil {
+			continue
+		}
+		if heatmap == nil {
+			heatmap = newHeatmap(res.Occupancy.Width, res.Occupancy.Height)
+		}
+		if res.Occupancy.Width != heatmap.Width || res.Occupancy.Height != heatmap.Height {
+			continue
+		}
+		for i, c := range res.Occupancy.Counts {
+			heatmap.Counts[i] += c
+		}
+	}
+	return heatmap
+}
+
+// writeHeatmap writes the heatmap as a PNG if the filename ends in .png, otherwise as a CSV
+// with a row per line of the board, top line first.
+func writeHeatmap(o *Options, h *Heatmap) {
+	if h == nil {
+		return
+	}
+	f, err := os.Create(o.Heatmap)
+	if err != nil {
+		o.Log("[WARN]: Unable to create heatmap %v: %v", o.Heatmap, err)
+		return
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(o.Heatmap), ".png") {
+		err = png.Encode(f, renderHeatmap(h, o.PNGCell))
+	} else {
+		w := csv.NewWriter(f)
+		for y := h.Height - 1; y >= 0; y-- {
+			row := make([]string, h.Width)
+			for x := int32(0); x < h.Width; x++ {
+				row[x] = strconv.Itoa(h.At(x, y))
+			}
+			_ = w.Write(row)
+		}
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		o.Log("[WARN]: Unable to write heatmap %v: %v", o.Heatmap, err)
+	}
+}
+
+// renderHeatmap shades each cell from white, never occupied, to red, the most occupied.
+func renderHeatmap(h *Heatmap, cell int) image.Image {
+	if cell < 1 {
+		cell = 1
+	}
+	var max int
+	for _, c := range h.Counts {
+		if c > max {
+			max = c
+		}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, int(h.Width)*cell, int(h.Height)*cell))
+	for y := int32(0); y < h.Height; y++ {
+		for x := int32(0); x < h.Width; x++ {
+			shade := uint8(0xff)
+			if max > 0 {
+				shade = uint8(0xff - 0xff*h.At(x, y)/max)
+			}
+			c := color.RGBA{R: 0xff, G: shade, B: shade, A: 0xff}
+			// Board coordinates have y pointing up, image coordinates have y pointing down.
+			top := int(h.Height-1-y) * cell
+			for dy := 0; dy < cell; dy++ {
+				for dx := 0; dx < cell; dx++ {
+					img.Set(int(x)*cell+dx, top+dy, c)
+				}
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHeatmap(t *testing.T) {
+	dir := t.TempDir()
+	var results []Result
+	var cells int
+	for seed := int64(1); seed <= 3; seed++ {
+		output := filepath.Join(dir, fmt.Sprintf("game-%d.ndjson", seed))
+		res := Run(&Options{
+			Width:        rules.BoardSizeSmall,
+			Height:       rules.BoardSizeSmall,
+			GameType:     "standard",
+			Seed:         seed,
+			RandomSnakes: 3,
+			Output:       output,
+			Heatmap:      filepath.Join(dir, "unused.csv"),
+			Log:          new(testLog).Log,
+		})
+		results = append(results, res)
+
+		f, err := os.Open(output)
+		require.NoError(t, err)
+		_, frames, err := readRecording(f)
+		f.Close()
+		require.NoError(t, err)
+		require.Len(t, frames, int(res.Turn)+1)
+		for _, frame := range frames {
+			for _, snake := range frame.Board.Snakes {
+				distinct := make(map[Coord]bool)
+				for _, c := range snake.Body {
+					distinct[c] = true
+				}
+				cells += len(distinct)
+			}
+		}
+	}
+
+	heatmap := BuildHeatmap(results)
+	require.Equal(t, int32(rules.BoardSizeSmall), heatmap.Width)
+	require.Equal(t, cells, heatmap.Total())
+}
+
+func TestWriteHeatmap(t *testing.T) {
+	// A snake circling a 2x2 board without food occupies 3 of its 4 cells every turn.
+	srv := newTestSnake(t, circleMove)
+	path := filepath.Join(t.TempDir(), "heatmap.csv")
+	o := &Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"circler"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   5,
+		Heatmap:    path,
+		Log:        new(testLog).Log,
+	}
+	res := Run(o)
+	require.Equal(t, int32(5), res.Turn)
+	require.Equal(t, 3*6, res.Occupancy.Total())
+
+	writeHeatmap(o, BuildHeatmap([]Result{res}))
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%v,%v\n%v,%v\n", res.Occupancy.At(0, 1), res.Occupancy.At(1, 1), res.Occupancy.At(0, 0), res.Occupancy.At(1, 0)), string(b))
+
+	o.Heatmap = filepath.Join(t.TempDir(), "heatmap.png")
+	writeHeatmap(o, BuildHeatmap([]Result{res}))
+	b, err = ioutil.ReadFile(o.Heatmap)
+	require.NoError(t, err)
+	require.Equal(t, "\x89PNG", string(b[:4]))
+}
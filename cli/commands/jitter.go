@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// newJitterSource returns the source of the --move-timeout-jitter delays. It is seeded with the
+// game seed, so a seed always delays the same moves by the same amounts.
+func newJitterSource(o *Options) *rand.Rand {
+	return rand.New(rand.NewSource(o.Seed))
+}
+
+// drawJitter draws the delay of each snake's move response this turn, between zero and
+// --move-timeout-jitter. They are drawn in the order of snakes before any move is requested,
+// so they only depend on the seed and not on the order responses arrive in.
+func drawJitter(o *Options, snakes []Battlesnake) map[string]time.Duration {
+	delays := make(map[string]time.Duration)
+	if o.MoveTimeoutJitter <= 0 {
+		return delays
+	}
+	for _, snake := range snakes {
+		delays[snake.ID] = time.Duration(o.jitter.Int63n(int64(o.MoveTimeoutJitter) + 1))
+	}
+	return delays
+}
+
+// waitJitter holds back a snake's move response by its delay for the turn, as if the network
+// had slowed it down. The delay counts against the timeout, so it waits no longer than what is
+// left of it and returns an error when the response ends up arriving too late to be used.
+func waitJitter(ctx context.Context, o *Options, snake Battlesnake, delay time.Duration, start time.Time) error {
+	if delay <= 0 {
+		return nil
+	}
+	timeout := time.Duration(o.Timeout) * time.Millisecond
+	wait := delay
+	if remaining := timeout - time.Since(start); remaining < wait {
+		wait = remaining
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if wait < delay {
+		o.Log("[WARN]: [%v]: %v's move was delayed %v by jitter, past the %v timeout\n", o.Turn, displayName(snake), delay, timeout)
+		return fmt.Errorf("move delayed %v by jitter, past the %v timeout: %w", delay, timeout, context.DeadlineExceeded)
+	}
+	return nil
+}
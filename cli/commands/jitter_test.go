@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawJitter(t *testing.T) {
+	snakes := []Battlesnake{{ID: "one"}, {ID: "two"}, {ID: "three"}}
+	draw := func(seed int64) []time.Duration {
+		o := &Options{Seed: seed, MoveTimeoutJitter: 50 * time.Millisecond}
+		o.jitter = newJitterSource(o)
+		var delays []time.Duration
+		for turn := 0; turn < 20; turn++ {
+			turnDelays := drawJitter(o, snakes)
+			require.Len(t, turnDelays, len(snakes))
+			for _, snake := range snakes {
+				delays = append(delays, turnDelays[snake.ID])
+			}
+		}
+		return delays
+	}
+
+	delays := draw(1)
+	for _, delay := range delays {
+		require.True(t, delay >= 0 && delay <= 50*time.Millisecond, "delay %v out of bounds", delay)
+	}
+	require.Equal(t, delays, draw(1))
+	require.NotEqual(t, delays, draw(2))
+
+	require.Empty(t, drawJitter(&Options{}, snakes))
+}
+
+func TestWaitJitter(t *testing.T) {
+	l := new(testLog)
+	o := &Options{Timeout: 20, Turn: 3, Log: l.Log}
+	snake := Battlesnake{ID: "one", Name: "Snake1"}
+
+	require.NoError(t, waitJitter(context.Background(), o, snake, 0, time.Now()))
+	require.NoError(t, waitJitter(context.Background(), o, snake, time.Millisecond, time.Now()))
+	require.Empty(t, l.lines)
+
+	require.Error(t, waitJitter(context.Background(), o, snake, 30*time.Millisecond, time.Now()))
+	require.Len(t, l.lines, 1)
+	require.True(t, strings.HasPrefix(l.lines[0], "[WARN]: [3]: Snake1's move was delayed 30ms by jitter"), l.lines[0])
+
+	// A delay past the timeout is only waited out until the timeout.
+	start := time.Now()
+	require.True(t, errors.Is(waitJitter(context.Background(), o, snake, time.Hour, start), context.DeadlineExceeded))
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, waitJitter(ctx, o, snake, time.Hour, time.Now()))
+}
+
+func TestRunMoveTimeoutJitter(t *testing.T) {
+	snake := newTestSnake(t, circleMove)
+	l := new(testLog)
+	o := &Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"one", "two"},
+		URLs:     []string{snake.URL, snake.URL},
+		GameType: "standard",
+		Seed:     1,
+		MaxTurns: 2,
+		Timeout:  50,
+		// The jitter is far beyond the timeout, so every move arrives too late.
+		MoveTimeoutJitter: time.Hour,
+		StrictTimeout:     true,
+		LogMoves:          true,
+		HttpClient:        &http.Client{},
+		Log:               l.Log,
+	}
+	start := time.Now()
+	Run(o)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	var moves int
+	for _, line := range l.lines {
+		if strings.HasPrefix(line, "[MOVE]: ") {
+			var entry MoveLog
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+			require.True(t, entry.Fallback)
+			moves++
+		}
+	}
+	require.Equal(t, 4, moves)
+}
+
+func TestRunMoveTimeoutJitterNotStrict(t *testing.T) {
+	snake := newTestSnake(t, circleMove)
+	l := new(testLog)
+	o := &Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"one", "two"},
+		URLs:     []string{snake.URL, snake.URL},
+		GameType: "standard",
+		Seed:     1,
+		MaxTurns: 2,
+		Timeout:  50,
+		// Without --strict-timeout, a jitter past the timeout still makes the moves time out.
+		MoveTimeoutJitter: time.Hour,
+		LogMoves:          true,
+		HttpClient:        &http.Client{},
+		Log:               l.Log,
+	}
+	start := time.Now()
+	Run(o)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	var moves int
+	for _, line := range l.lines {
+		if strings.HasPrefix(line, "[MOVE]: ") {
+			var entry MoveLog
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+			require.True(t, entry.Fallback)
+			moves++
+		}
+	}
+	require.Equal(t, 4, moves)
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LatencyHistogram counts a snake's move latencies, to find tail latency problems that
+// an average hides.
+type LatencyHistogram struct {
+	Name    string          `json:"name"`
+	Buckets []LatencyBucket `json:"buckets"`
+	// Overflow counts the latencies above the largest bucket.
+	Overflow int `json:"overflow"`
+}
+
+type LatencyBucket struct {
+	// MaxMs is the inclusive upper bound of the bucket, the lower bound is the previous bucket's.
+	MaxMs int64 `json:"max_ms"`
+	Count int   `json:"count"`
+}
+
+// parseLatencyBuckets parses the increasing bucket bounds in milliseconds, separated by commas.
+func parseLatencyBuckets(s string) ([]int64, error) {
+	var bounds []int64
+	for _, part := range strings.Split(s, ",") {
+		bound, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || bound <= 0 {
+			return nil, fmt.Errorf("invalid bucket %q", part)
+		}
+		if n := len(bounds); n > 0 && bound <= bounds[n-1] {
+			return nil, fmt.Errorf("bucket %v is not above bucket %v", bound, bounds[n-1])
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, nil
+}
+
+func buildLatencyHistogram(name string, latencies []time.Duration, bounds []int64) LatencyHistogram {
+	h := LatencyHistogram{Name: name, Buckets: make([]LatencyBucket, len(bounds))}
+	for i, bound := range bounds {
+		h.Buckets[i].MaxMs = bound
+	}
+	for _, latency := range latencies {
+		i := 0
+		for i < len(bounds) && latency > time.Duration(bounds[i])*time.Millisecond {
+			i++
+		}
+		if i == len(bounds) {
+			h.Overflow++
+		} else {
+			h.Buckets[i].Count++
+		}
+	}
+	return h
+}
+
+// logLatencyHistograms logs the histogram of each snake that was sent move requests, in setup order.
+func logLatencyHistograms(o *Options, snakes []Battlesnake) {
+	bounds, _ := parseLatencyBuckets(o.LatencyBuckets)
+	for _, snake := range snakes {
+		if snake.Provider != nil {
+			continue
+		}
+		h := buildLatencyHistogram(snake.Name, o.latencies[snake.ID], bounds)
+		if o.LatencyHistogram == "json" {
+			b, err := json.Marshal(h)
+			if err != nil {
+				o.Log("[WARN]: Unable to marshal latency histogram: %v", err)
+				continue
+			}
+			o.Log("%s", b)
+			continue
+		}
+		o.Log("[LATENCY]: %s", formatLatencyHistogram(h))
+	}
+}
+
+func formatLatencyHistogram(h LatencyHistogram) string {
+	parts := []string{h.Name}
+	for _, b := range h.Buckets {
+		parts = append(parts, fmt.Sprintf("<=%vms: %v", b.MaxMs, b.Count))
+	}
+	if n := len(h.Buckets); n > 0 {
+		parts = append(parts, fmt.Sprintf(">%vms: %v", h.Buckets[n-1].MaxMs, h.Overflow))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLatencyHistogram(t *testing.T) {
+	latencies := []time.Duration{
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		51 * time.Millisecond,
+		100 * time.Millisecond,
+		700 * time.Millisecond,
+	}
+	h := buildLatencyHistogram("snake", latencies, []int64{50, 100, 500})
+	require.Equal(t, LatencyHistogram{
+		Name:     "snake",
+		Buckets:  []LatencyBucket{{MaxMs: 50, Count: 2}, {MaxMs: 100, Count: 2}, {MaxMs: 500, Count: 0}},
+		Overflow: 1,
+	}, h)
+	require.Equal(t, "snake <=50ms: 2 <=100ms: 2 <=500ms: 0 >500ms: 1", formatLatencyHistogram(h))
+}
+
+func TestParseLatencyBuckets(t *testing.T) {
+	bounds, err := parseLatencyBuckets("50, 100,250")
+	require.NoError(t, err)
+	require.Equal(t, []int64{50, 100, 250}, bounds)
+
+	_, err = parseLatencyBuckets("100,50")
+	require.EqualError(t, err, "bucket 50 is not above bucket 100")
+	_, err = parseLatencyBuckets("fast")
+	require.EqualError(t, err, `invalid bucket "fast"`)
+}
+
+func TestRunLatencyHistogram(t *testing.T) {
+	// Odd turns are slow and even turns are fast.
+	srv := newTestServer(t, func(w http.ResponseWriter, p ResponsePayload) {
+		if p.Turn%2 == 1 {
+			time.Sleep(60 * time.Millisecond)
+		}
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	})
+	l := new(testLog)
+	Run(&Options{
+		Width:            2,
+		Height:           2,
+		Names:            []string{"variable"},
+		URLs:             []string{srv.URL},
+		GameType:         "solo",
+		Sequential:       true,
+		Seed:             1,
+		MaxTurns:         4,
+		LatencyHistogram: "json",
+		LatencyBuckets:   "30,300",
+		Log:              l.Log,
+	})
+
+	var h LatencyHistogram
+	for _, line := range l.lines {
+		if json.Unmarshal([]byte(line), &h) == nil && h.Name == "variable" {
+			break
+		}
+	}
+	require.Equal(t, LatencyHistogram{
+		Name:    "variable",
+		Buckets: []LatencyBucket{{MaxMs: 30, Count: 2}, {MaxMs: 300, Count: 2}},
+	}, h)
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// LegendEntry is how a snake is drawn, in PNGs by color and on the ASCII map by character.
+type LegendEntry struct {
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Character string `json:"character"`
+}
+
+// writeLegend writes the legend of the snakes as JSON, keyed by snake ID, so that other
+// visualizers can match the colors and characters used by the CLI.
+func writeLegend(o *Options, snakes []Battlesnake) {
+	legend := make(map[string]LegendEntry, len(snakes))
+	for _, snake := range snakes {
+		legend[snake.ID] = LegendEntry{
+			Name:      displayName(snake),
+			Color:     snake.Color,
+			Character: string(snake.Character),
+		}
+	}
+	b, err := json.MarshalIndent(legend, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(o.Legend, b, 0644)
+	}
+	if err != nil {
+		o.Log("[WARN]: Unable to write legend %v: %v", o.Legend, err)
+	}
+}
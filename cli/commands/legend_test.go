@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLegend(t *testing.T) {
+	srv := newTestSnake(t, squareMove)
+	dir := t.TempDir()
+	o := &Options{
+		Width:        rules.BoardSizeMedium,
+		Height:       rules.BoardSizeMedium,
+		Names:        []string{"one", "two"},
+		URLs:         []string{srv.URL, srv.URL},
+		RandomSnakes: 1,
+		GameType:     "standard",
+		Sequential:   true,
+		Seed:         1,
+		MaxTurns:     1,
+		Output:       filepath.Join(dir, "game.ndjson"),
+		Legend:       filepath.Join(dir, "legend.json"),
+		Log:          new(testLog).Log,
+	}
+	Run(o)
+
+	b, err := ioutil.ReadFile(o.Legend)
+	require.NoError(t, err)
+	var legend map[string]LegendEntry
+	require.NoError(t, json.Unmarshal(b, &legend))
+
+	f, err := os.Open(o.Output)
+	require.NoError(t, err)
+	defer f.Close()
+	header, _, err := readRecording(f)
+	require.NoError(t, err)
+
+	require.Len(t, legend, 3)
+	for i, snake := range header.Snakes {
+		require.Equal(t, LegendEntry{
+			Name:      snake.Name,
+			Color:     snakeColors[i],
+			Character: string(bodyChars[i]),
+		}, legend[snake.ID], snake.Name)
+	}
+}
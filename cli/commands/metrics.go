@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"net"
+	"net/http"
+)
+
+// Runtime counters, published with expvar and served at /debug/vars with --metrics-addr. They
+// count across all the games of the process, which is what matters for long-running batches.
+var (
+	gamesPlayed    = expvar.NewInt("games_played")
+	turnsProcessed = expvar.NewInt("turns_processed")
+	moveRequests   = expvar.NewInt("move_requests")
+	moveTimeouts   = expvar.NewInt("move_timeouts")
+)
+
+// serveMetrics serves the expvar counters on addr in the background, returning the address
+// it listens on.
+func serveMetrics(o *Options, addr string) string {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Panicf("[PANIC]: Unable to Serve Metrics on %v: %v", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			o.Log("[WARN]: Metrics server stopped: %v", err)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// isTimeout reports whether a move failed because it didn't arrive in time.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMetrics(t *testing.T) {
+	// The snake answers the first move in time and is too slow for the second.
+	snake := newTestSnake(t, func(p ResponsePayload) string {
+		if p.Turn == 2 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return rules.MoveUp
+	})
+	o := &Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"slow"},
+		URLs:     []string{snake.URL},
+		GameType: "solo",
+		Seed:     1,
+		MaxTurns: 2,
+		Timeout:  50,
+		Log:      new(testLog).Log,
+	}
+	addr := serveMetrics(o, "127.0.0.1:0")
+	before := readMetrics(t, addr)
+
+	res := Run(o)
+	require.Equal(t, int32(2), res.Turn)
+
+	after := readMetrics(t, addr)
+	require.Equal(t, before["games_played"]+1, after["games_played"])
+	require.Equal(t, before["turns_processed"]+2, after["turns_processed"])
+	require.Equal(t, before["move_requests"]+2, after["move_requests"])
+	require.Equal(t, before["move_timeouts"]+1, after["move_timeouts"])
+}
+
+func readMetrics(t *testing.T, addr string) map[string]int64 {
+	res, err := http.Get("http://" + addr + "/debug/vars")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var vars map[string]json.RawMessage
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&vars))
+	metrics := make(map[string]int64)
+	for _, name := range []string{"games_played", "turns_processed", "move_requests", "move_timeouts"} {
+		require.Contains(t, vars, name)
+		var n int64
+		require.NoError(t, json.Unmarshal(vars[name], &n))
+		metrics[name] = n
+	}
+	return metrics
+}
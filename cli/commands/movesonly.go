@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/corverroos/bsrules"
+)
+
+// A moves-only recording, written with --export-moves-only, is a header line, a start line with
+// the initial state, and a line with the moves of each turn. The rest of the game is
+// reconstructed by applying the moves with the same rules and seed, which makes the recording
+// much smaller than the full state of each turn for long games.
+
+// MovesOnlyStart is the state a moves-only recording starts from.
+type MovesOnlyStart struct {
+	Turn    int32             `json:"turn"`
+	State   *rules.BoardState `json:"state"`
+	Hazards []rules.Point     `json:"hazards"`
+	// RandDraws are the numbers drawn from the board seed before the first turn, which are
+	// skipped when reconstructing so that food spawns the same.
+	RandDraws int64 `json:"rand_draws"`
+}
+
+// MovesOnlyTurn is the moves of a turn of a moves-only recording, in the order they were applied.
+type MovesOnlyTurn struct {
+	Turn  int32             `json:"turn"`
+	Moves []rules.SnakeMove `json:"moves"`
+}
+
+type movesOnlyLine struct {
+	Header *Header         `json:"header,omitempty"`
+	Start  *MovesOnlyStart `json:"start,omitempty"`
+	Turn   *MovesOnlyTurn  `json:"turn,omitempty"`
+}
+
+func writeMovesOnlyStart(o *Options, header Header, state *rules.BoardState, outOfBounds []rules.Point) {
+	err := o.movesOnly.Encode(movesOnlyLine{Header: &header})
+	if err == nil {
+		err = o.movesOnly.Encode(movesOnlyLine{Start: &MovesOnlyStart{
+			Turn:      o.Turn,
+			State:     state,
+			Hazards:   outOfBounds,
+			RandDraws: o.randDraws.draws,
+		}})
+	}
+	if err != nil {
+		o.Log("[WARN]: Unable to record the start of the game: %v", err)
+	}
+}
+
+func writeMovesOnlyTurn(o *Options, moves []rules.SnakeMove) {
+	if err := o.movesOnly.Encode(movesOnlyLine{Turn: &MovesOnlyTurn{Turn: o.Turn, Moves: moves}}); err != nil {
+		o.Log("[WARN]: Unable to record the moves of turn %v: %v", o.Turn, err)
+	}
+}
+
+// isMovesOnlyRecording reports whether a recording was written with --export-moves-only,
+// which starts with a header followed by a start line.
+func isMovesOnlyRecording(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16*1024*1024)
+	for i := 0; i < 2 && scanner.Scan(); i++ {
+		var line movesOnlyLine
+		if json.Unmarshal(scanner.Bytes(), &line) == nil && line.Start != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconstructFrames rebuilds the frames of a game from a moves-only recording, as they would
+// have been recorded with --output. The game type, seeds, board size and rule options, like
+// --health-decay, are all read from the header.
+func ReconstructFrames(o *Options, r io.Reader) (*Header, []Frame, error) {
+	var header *Header
+	var start *MovesOnlyStart
+	var turns []MovesOnlyTurn
+	dec := json.NewDecoder(r)
+	for {
+		var line movesOnlyLine
+		if err := dec.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case line.Header != nil:
+			header = line.Header
+		case line.Start != nil:
+			start = line.Start
+		case line.Turn != nil:
+			turns = append(turns, *line.Turn)
+		}
+	}
+	if header == nil || start == nil || start.State == nil {
+		return nil, nil, fmt.Errorf("not a moves-only recording: missing header or start")
+	}
+
+	o.GameType = header.GameType
+	o.Seed, o.BoardSeed = header.Seed, header.BoardSeed
+	o.Width, o.Height = header.Width, header.Height
+	o.Timeout = header.Timeout
+	applyHeaderRules(o, header.Rules)
+	o.Turn = start.Turn
+	if o.GameType == "walls" {
+		o.Walls = start.Hazards
+	}
+	o.Battlesnakes = make(map[string]Battlesnake)
+	var snakes []Battlesnake
+	for _, hs := range header.Snakes {
+		snake := Battlesnake{ID: hs.ID, Name: hs.Name, URL: hs.URL, Squad: hs.Squad, Version: hs.Version}
+		o.Battlesnakes[snake.ID] = snake
+		snakes = append(snakes, snake)
+	}
+
+	o.randDraws = newCountingSource(boardSeed(o))
+	o.rand = rand.New(o.randDraws)
+	skipRandDraws(o, start.RandDraws)
+
+	state, outOfBounds := start.State, start.Hazards
+	frames := []Frame{buildFrame(o, state, outOfBounds)}
+	for _, turn := range turns {
+		if turn.Turn != o.Turn+1 {
+			return nil, nil, fmt.Errorf("expected the moves of turn %v, got turn %v", o.Turn+1, turn.Turn)
+		}
+		o.Turn = turn.Turn
+		ruleset, royale := getRuleset(o, snakes)
+		state, outOfBounds = applyMoves(o, ruleset, royale, state, turn.Moves)
+		frames = append(frames, buildFrame(o, state, outOfBounds))
+	}
+	return header, frames, nil
+}
+
+// skipRandDraws draws from o.rand until n numbers have been drawn from the board seed, to carry
+// on from where a recorded or stopped game left off.
+func skipRandDraws(o *Options, n int64) {
+	for o.randDraws.draws < n {
+		o.rand.Int63()
+	}
+}
+
+// countingSource is a random source that counts the numbers drawn from it.
+type countingSource struct {
+	src   rand.Source64
+	draws int64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.draws = 0
+	s.src.Seed(seed)
+}
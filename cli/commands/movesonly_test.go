@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconstructFrames(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	dir := t.TempDir()
+	full := filepath.Join(dir, "full.ndjson")
+	movesOnly := filepath.Join(dir, "moves.ndjson")
+	o := &Options{
+		Width:           rules.BoardSizeMedium,
+		Height:          rules.BoardSizeMedium,
+		Names:           []string{"square1", "square2", "up"},
+		URLs:            []string{square.URL, square.URL, up.URL},
+		GameType:        "standard",
+		Seed:            7,
+		MaxTurns:        40,
+		Output:          full,
+		ExportMovesOnly: movesOnly,
+		Log:             new(testLog).Log,
+	}
+	res := Run(o)
+
+	f, err := os.Open(full)
+	require.NoError(t, err)
+	defer f.Close()
+	header, expected, err := readRecording(f)
+	require.NoError(t, err)
+	require.Len(t, expected, int(res.Turn)+1)
+
+	f, err = os.Open(movesOnly)
+	require.NoError(t, err)
+	defer f.Close()
+	movesHeader, frames, err := ReconstructFrames(&Options{}, f)
+	require.NoError(t, err)
+	require.Equal(t, header, movesHeader)
+	require.Equal(t, expected, frames)
+
+	// Food spawned during the game, so reconstructing depends on the board seed.
+	require.NotEqual(t, expected[0].Board.Food, expected[len(expected)-1].Board.Food)
+
+	fullInfo, err := os.Stat(full)
+	require.NoError(t, err)
+	movesInfo, err := os.Stat(movesOnly)
+	require.NoError(t, err)
+	require.Less(t, movesInfo.Size(), fullInfo.Size())
+}
+
+func TestReconstructFramesRules(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	dir := t.TempDir()
+	full := filepath.Join(dir, "full.ndjson")
+	movesOnly := filepath.Join(dir, "moves.ndjson")
+	o := &Options{
+		Width:           rules.BoardSizeMedium,
+		Height:          rules.BoardSizeMedium,
+		Names:           []string{"square1", "square2"},
+		URLs:            []string{square.URL, square.URL},
+		GameType:        "royale",
+		Seed:            7,
+		MaxTurns:        40,
+		AutoScale:       true,
+		HealthDecay:     2,
+		FoodPerSpawn:    4,
+		FoodChance:      60,
+		FoodSchedule:    "0:1,10:3",
+		MaxFood:         5,
+		HazardPattern:   "spiral",
+		HazardGrowth:    2,
+		Output:          full,
+		ExportMovesOnly: movesOnly,
+		Log:             new(testLog).Log,
+	}
+	Run(o)
+
+	f, err := os.Open(full)
+	require.NoError(t, err)
+	defer f.Close()
+	header, expected, err := readRecording(f)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), header.Rules.HealthDecay)
+	require.Equal(t, "spiral", header.Rules.HazardPattern)
+
+	// Nothing but the recording is needed to reconstruct the game with the same rules.
+	f, err = os.Open(movesOnly)
+	require.NoError(t, err)
+	defer f.Close()
+	_, frames, err := ReconstructFrames(&Options{}, f)
+	require.NoError(t, err)
+	require.Equal(t, expected, frames)
+}
+
+func TestReplayMovesOnly(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	dir := t.TempDir()
+	full := filepath.Join(dir, "full.ndjson")
+	movesOnly := filepath.Join(dir, "moves.ndjson")
+	Run(&Options{
+		Width:           2,
+		Height:          2,
+		Names:           []string{"circler"},
+		URLs:            []string{srv.URL},
+		GameType:        "solo",
+		Seed:            1,
+		MaxTurns:        5,
+		Output:          full,
+		ExportMovesOnly: movesOnly,
+		Log:             new(testLog).Log,
+	})
+
+	replay := func(path string) []string {
+		l := new(testLog)
+		require.NoError(t, Replay(&ReplayOptions{Path: path, Log: l.Log}))
+		return l.lines
+	}
+	lines := replay(movesOnly)
+	require.Len(t, lines, 6)
+	require.Equal(t, replay(full), lines)
+}
+
+func TestCountingSource(t *testing.T) {
+	src := newCountingSource(3)
+	r := rand.New(src)
+	r.Intn(10)
+	r.Shuffle(5, func(i, j int) {})
+	r.Float64()
+	draws := src.draws
+	require.Greater(t, draws, int64(0))
+
+	// Skipping the draws leaves a new source where the first one left off.
+	skipped := newCountingSource(3)
+	for skipped.draws < draws {
+		skipped.Int63()
+	}
+	require.Equal(t, r.Int63(), rand.New(skipped).Int63())
+	require.False(t, isMovesOnlyRecording([]byte(`{"turn":0,"board":{}}`)))
+	require.True(t, isMovesOnlyRecording(bytes.Join([][]byte{[]byte(`{"header":{}}`), []byte(`{"start":{"turn":0}}`)}, []byte("\n"))))
+}
@@ -0,0 +1,71 @@
+package commands
+
+import "encoding/json"
+
+// Payload schema versions that can be forced with --payload-version.
+const (
+	// PayloadVersion0 is the legacy schema, without hazards, the timeout, or the head, length,
+	// latency, shout and squad of snakes.
+	PayloadVersion0 = "0"
+	// PayloadVersion1 is the current schema.
+	PayloadVersion1 = "1"
+)
+
+func isPayloadVersion(version string) bool {
+	return version == "" || version == PayloadVersion0 || version == PayloadVersion1
+}
+
+type legacySnakeResponse struct {
+	Id     string  `json:"id"`
+	Name   string  `json:"name"`
+	Health int32   `json:"health"`
+	Body   []Coord `json:"body"`
+}
+
+type legacyBoardResponse struct {
+	Height int32                 `json:"height"`
+	Width  int32                 `json:"width"`
+	Food   []Coord               `json:"food"`
+	Snakes []legacySnakeResponse `json:"snakes"`
+}
+
+type legacyGameResponse struct {
+	Id string `json:"id"`
+}
+
+type legacyResponsePayload struct {
+	Game  legacyGameResponse  `json:"game"`
+	Turn  int32               `json:"turn"`
+	Board legacyBoardResponse `json:"board"`
+	You   legacySnakeResponse `json:"you"`
+}
+
+func legacySnake(s SnakeResponse) legacySnakeResponse {
+	return legacySnakeResponse{Id: s.Id, Name: s.Name, Health: s.Health, Body: s.Body}
+}
+
+func legacyPayload(p ResponsePayload) legacyResponsePayload {
+	snakes := make([]legacySnakeResponse, len(p.Board.Snakes))
+	for i, s := range p.Board.Snakes {
+		snakes[i] = legacySnake(s)
+	}
+	return legacyResponsePayload{
+		Game: legacyGameResponse{Id: p.Game.Id},
+		Turn: p.Turn,
+		Board: legacyBoardResponse{
+			Height: p.Board.Height,
+			Width:  p.Board.Width,
+			Food:   p.Board.Food,
+			Snakes: snakes,
+		},
+		You: legacySnake(p.You),
+	}
+}
+
+// marshalPayload serializes a payload in the given schema version, the current one if empty.
+func marshalPayload(payload ResponsePayload, version string) ([]byte, error) {
+	if version == PayloadVersion0 {
+		return json.Marshal(legacyPayload(payload))
+	}
+	return json.Marshal(payload)
+}
@@ -0,0 +1,398 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPayloadForSnake(t *testing.T) {
+	o := &Options{
+		GameId:  "game",
+		Turn:    7,
+		Timeout: 500,
+		Battlesnakes: map[string]Battlesnake{
+			"one": {ID: "one", Name: "Snake1"},
+			"two": {ID: "two", Name: "Snake2"},
+		},
+	}
+	state := &rules.BoardState{
+		Width:  5,
+		Height: 6,
+		Food:   []rules.Point{{X: 4, Y: 4}},
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 90, Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}}},
+			{ID: "two", Health: 80, Body: []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 2}, {X: 3, Y: 1}}},
+		},
+	}
+
+	payload := BuildPayloadForSnake(state, "two", o, []rules.Point{{X: 0, Y: 5}})
+
+	require.Equal(t, GameResponse{Id: "game", Timeout: 500}, payload.Game)
+	require.Equal(t, int32(7), payload.Turn)
+	require.Equal(t, SnakeResponse{
+		Id:      "two",
+		Name:    "Snake2",
+		Health:  80,
+		Body:    []Coord{{X: 3, Y: 3}, {X: 3, Y: 2}, {X: 3, Y: 1}},
+		Latency: "0",
+		Head:    Coord{X: 3, Y: 3},
+		Length:  3,
+	}, payload.You)
+	require.Equal(t, int32(5), payload.Board.Width)
+	require.Equal(t, int32(6), payload.Board.Height)
+	require.Equal(t, []Coord{{X: 4, Y: 4}}, payload.Board.Food)
+	require.Equal(t, []Coord{{X: 0, Y: 5}}, payload.Board.Hazards)
+	require.Len(t, payload.Board.Snakes, 2)
+	require.Equal(t, "Snake1", payload.Board.Snakes[0].Name)
+	require.Equal(t, payload.You, payload.Board.Snakes[1])
+}
+
+func TestBuildPayloadForUnknownSnake(t *testing.T) {
+	state := &rules.BoardState{Width: 5, Height: 5}
+	payload := BuildPayloadForSnake(state, "missing", &Options{}, nil)
+	require.Equal(t, "", payload.You.Id)
+	require.Equal(t, []Coord{}, payload.Board.Hazards)
+}
+
+func TestRunYouMatchesBoardSnake(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []ResponsePayload
+	move := func(p ResponsePayload) string {
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+		return squareMove(p)
+	}
+	one, two := newTestSnake(t, move), newTestSnake(t, move)
+
+	res := Run(&Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"one", "two"},
+		URLs:     []string{one.URL, two.URL},
+		GameType: "standard",
+		Seed:     1,
+		Log:      new(testLog).Log,
+	})
+
+	require.Len(t, payloads, 2*int(res.Turn))
+	for _, p := range payloads {
+		var found bool
+		for _, snake := range p.Board.Snakes {
+			if snake.Id == p.You.Id {
+				require.Equal(t, snake, p.You, "turn %v", p.Turn)
+				found = true
+			}
+		}
+		require.True(t, found, "turn %v", p.Turn)
+	}
+}
+
+func TestBuildPayloadFoodDistance(t *testing.T) {
+	o := &Options{Battlesnakes: map[string]Battlesnake{}}
+	state := &rules.BoardState{
+		Width:  11,
+		Height: 11,
+		Food:   []rules.Point{{X: 8, Y: 8}, {X: 2, Y: 7}, {X: 10, Y: 0}},
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 90, Body: []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 2}}},
+			{ID: "two", Health: 80, Body: []rules.Point{{X: 9, Y: 1}, {X: 9, Y: 2}}},
+		},
+	}
+
+	payload := BuildPayloadForSnake(state, "one", o, nil)
+	require.Nil(t, payload.You.CLI)
+	b, err := json.Marshal(payload)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "x_battlesnake_cli")
+
+	o.FoodDistance = true
+	payload = BuildPayloadForSnake(state, "one", o, nil)
+	// |2-3| + |7-3| = 5, closer than |8-3| + |8-3| = 10
+	require.Equal(t, &SnakeExtensions{NearestFoodDistance: 5}, payload.You.CLI)
+	require.Equal(t, payload.You, payload.Board.Snakes[0])
+	// |10-9| + |0-1| = 2
+	require.Equal(t, &SnakeExtensions{NearestFoodDistance: 2}, payload.Board.Snakes[1].CLI)
+	b, err = json.Marshal(payload.You)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"x_battlesnake_cli":{"nearest_food_distance":5}`)
+
+	state.Food = nil
+	payload = BuildPayloadForSnake(state, "one", o, nil)
+	require.Equal(t, int32(-1), payload.You.CLI.NearestFoodDistance)
+}
+
+func TestToAPIPayload(t *testing.T) {
+	state := &rules.BoardState{
+		Width:  5,
+		Height: 6,
+		Food:   []rules.Point{{X: 4, Y: 4}},
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 90, Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}}},
+			{ID: "two", Health: 80, Body: []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 2}, {X: 3, Y: 1}}},
+		},
+	}
+	snakes := []Battlesnake{{ID: "one", Name: "Snake1", Squad: "a"}, {ID: "two", Name: "Snake2", Squad: "b"}}
+	hazards := []rules.Point{{X: 0, Y: 5}}
+
+	b, err := ToAPIPayload(state, "one", GameResponse{Id: "game", Timeout: 500}, 7, snakes, hazards)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"game": {"id": "game", "timeout": 500},
+		"turn": 7,
+		"board": {
+			"height": 6,
+			"width": 5,
+			"food": [{"x": 4, "y": 4}],
+			"hazards": [{"x": 0, "y": 5}],
+			"snakes": [
+				{"id": "one", "name": "Snake1", "health": 90, "body": [{"x": 1, "y": 1}, {"x": 1, "y": 0}], "latency": "0", "head": {"x": 1, "y": 1}, "length": 2, "shout": "", "squad": "a"},
+				{"id": "two", "name": "Snake2", "health": 80, "body": [{"x": 3, "y": 3}, {"x": 3, "y": 2}, {"x": 3, "y": 1}], "latency": "0", "head": {"x": 3, "y": 3}, "length": 3, "shout": "", "squad": "b"}
+			]
+		},
+		"you": {"id": "one", "name": "Snake1", "health": 90, "body": [{"x": 1, "y": 1}, {"x": 1, "y": 0}], "latency": "0", "head": {"x": 1, "y": 1}, "length": 2, "shout": "", "squad": "a"}
+	}`, string(b))
+}
+
+func TestToAPIPayloadMatchesRun(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	mux := newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: squareMove(p)})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/move" {
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			mu.Lock()
+			bodies = append(bodies, b)
+			mu.Unlock()
+			r.Body = ioutil.NopCloser(bytes.NewReader(b))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	Run(&Options{
+		Width:         rules.BoardSizeSmall,
+		Height:        rules.BoardSizeSmall,
+		Names:         []string{"one", "two"},
+		URLs:          []string{srv.URL, srv.URL},
+		Squads:        []string{"red", "blue"},
+		GameType:      "squad",
+		HazardPattern: "spiral",
+		HazardGrowth:  1,
+		Seed:          1,
+		MaxTurns:      3,
+		Timeout:       500,
+		Log:           new(testLog).Log,
+	})
+
+	var withHazards int
+	for _, body := range bodies {
+		var p ResponsePayload
+		require.NoError(t, json.Unmarshal(body, &p))
+		state := &rules.BoardState{Width: p.Board.Width, Height: p.Board.Height, Food: pointsFromCoords(p.Board.Food)}
+		var snakes []Battlesnake
+		for _, snake := range p.Board.Snakes {
+			require.NotEmpty(t, snake.Name)
+			require.NotEmpty(t, snake.Squad)
+			state.Snakes = append(state.Snakes, rules.Snake{ID: snake.Id, Health: snake.Health, Body: pointsFromCoords(snake.Body)})
+			snakes = append(snakes, Battlesnake{ID: snake.Id, Name: snake.Name, Squad: snake.Squad})
+		}
+		if len(p.Board.Hazards) > 0 {
+			withHazards++
+		}
+
+		b, err := ToAPIPayload(state, p.You.Id, p.Game, p.Turn, snakes, pointsFromCoords(p.Board.Hazards))
+		require.NoError(t, err)
+		require.Equal(t, string(body), string(b), "turn %v", p.Turn)
+	}
+	require.NotZero(t, withHazards)
+}
+
+func TestRunPayloadVersion(t *testing.T) {
+	type rawPayload struct {
+		Game  map[string]interface{} `json:"game"`
+		Board map[string]interface{} `json:"board"`
+		You   map[string]interface{} `json:"you"`
+	}
+	run := func(version string) []rawPayload {
+		var mu sync.Mutex
+		var payloads []rawPayload
+		mux := newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+			_ = json.NewEncoder(w).Encode(PlayerResponse{Move: upMove(p)})
+		})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/move" {
+				b, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				var payload rawPayload
+				require.NoError(t, json.Unmarshal(b, &payload))
+				mu.Lock()
+				payloads = append(payloads, payload)
+				mu.Unlock()
+				r.Body = ioutil.NopCloser(bytes.NewReader(b))
+			}
+			mux.ServeHTTP(w, r)
+		}))
+		defer srv.Close()
+
+		Run(&Options{
+			Width:          rules.BoardSizeSmall,
+			Height:         rules.BoardSizeSmall,
+			Names:          []string{"one", "two"},
+			URLs:           []string{srv.URL, srv.URL},
+			GameType:       "standard",
+			Seed:           1,
+			MaxTurns:       2,
+			PayloadVersion: version,
+			Log:            new(testLog).Log,
+		})
+		require.Len(t, payloads, 4)
+		return payloads
+	}
+	keys := func(m map[string]interface{}) []string {
+		var keys []string
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	for _, payload := range run(PayloadVersion1) {
+		require.Equal(t, []string{"id", "timeout"}, keys(payload.Game))
+		require.Equal(t, []string{"food", "hazards", "height", "snakes", "width"}, keys(payload.Board))
+		require.Equal(t, []string{"body", "head", "health", "id", "latency", "length", "name", "shout", "squad"}, keys(payload.You))
+	}
+	for _, payload := range run(PayloadVersion0) {
+		require.Equal(t, []string{"id"}, keys(payload.Game))
+		require.Equal(t, []string{"food", "height", "snakes", "width"}, keys(payload.Board))
+		require.Equal(t, []string{"body", "health", "id", "name"}, keys(payload.You))
+		for _, snake := range payload.Board["snakes"].([]interface{}) {
+			require.Equal(t, []string{"body", "health", "id", "name"}, keys(snake.(map[string]interface{})))
+		}
+	}
+}
+
+func TestBuildPayloadEliminationInfo(t *testing.T) {
+	o := &Options{Battlesnakes: map[string]Battlesnake{}}
+	state := &rules.BoardState{
+		Width:  11,
+		Height: 11,
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 90, Body: []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 2}}},
+			{ID: "two", Health: 80, Body: []rules.Point{{X: 3, Y: 4}, {X: 3, Y: 5}}, EliminatedCause: rules.EliminatedByCollision, EliminatedBy: "one"},
+		},
+	}
+
+	payload := BuildPayloadForSnake(state, "one", o, nil)
+	require.Nil(t, payload.Board.Snakes[1].CLI)
+
+	o.EliminationInfo = true
+	payload = BuildPayloadForSnake(state, "one", o, nil)
+	require.Nil(t, payload.You.CLI)
+	require.Nil(t, payload.Board.Snakes[0].CLI)
+	require.Equal(t, &SnakeExtensions{EliminatedCause: rules.EliminatedByCollision, EliminatedBy: "one"}, payload.Board.Snakes[1].CLI)
+	b, err := json.Marshal(payload.Board.Snakes[1])
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"x_battlesnake_cli":{"eliminated_cause":"snake-collision","eliminated_by":"one"}`)
+}
+
+func TestRunEliminationInfo(t *testing.T) {
+	var mu sync.Mutex
+	causes := make(map[string]string)
+	square := newTestSnake(t, func(p ResponsePayload) string {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, snake := range p.Board.Snakes {
+			if snake.CLI != nil && causes[snake.Name] == "" {
+				causes[snake.Name] = snake.CLI.EliminatedCause
+			}
+		}
+		return squareMove(p)
+	})
+	up := newTestSnake(t, upMove)
+	res := Run(&Options{
+		Width:           rules.BoardSizeMedium,
+		Height:          rules.BoardSizeMedium,
+		Names:           []string{"square1", "square2", "up"},
+		URLs:            []string{square.URL, square.URL, up.URL},
+		GameType:        "standard",
+		Seed:            1,
+		MaxTurns:        20,
+		EliminationInfo: true,
+		Log:             new(testLog).Log,
+	})
+
+	var upTurn int32
+	for _, e := range res.Eliminations {
+		if e.Cause == rules.EliminatedByOutOfBounds {
+			upTurn = e.Turn
+		}
+	}
+	require.NotZero(t, upTurn)
+	require.Greater(t, res.Turn, upTurn)
+	require.Equal(t, map[string]string{"up": rules.EliminatedByOutOfBounds}, causes)
+}
+
+// largeBoardOptions returns Options and a state for an official 25x25 board with 8 snakes of
+// length 20, the largest games the CLI is expected to run.
+func largeBoardOptions() (*Options, *rules.BoardState) {
+	o := &Options{GameId: "game", Timeout: 500, Battlesnakes: map[string]Battlesnake{}}
+	state := &rules.BoardState{Width: 25, Height: 25}
+	for i := int32(0); i < 8; i++ {
+		snake := rules.Snake{ID: fmt.Sprintf("snake%d", i), Health: 100}
+		y := 1 + i*3
+		for x := int32(22); x > 2; x-- {
+			snake.Body = append(snake.Body, rules.Point{X: x, Y: y})
+		}
+		state.Snakes = append(state.Snakes, snake)
+		state.Food = append(state.Food, rules.Point{X: 0, Y: y})
+		o.Battlesnakes[snake.ID] = Battlesnake{ID: snake.ID, Name: fmt.Sprintf("Snake%d", i)}
+	}
+	return o, state
+}
+
+func TestLargeBoardPayloadAllocs(t *testing.T) {
+	o, state := largeBoardOptions()
+
+	// One turn builds and marshals a payload per snake.
+	allocs := testing.AllocsPerRun(20, func() {
+		for _, snake := range state.Snakes {
+			getIndividualBoardStateForSnake(o, state, o.Battlesnakes[snake.ID], nil)
+		}
+	})
+	require.Less(t, allocs, float64(8*32))
+}
+
+func BenchmarkBuildPayloads25x25(b *testing.B) {
+	o, state := largeBoardOptions()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, snake := range state.Snakes {
+			getIndividualBoardStateForSnake(o, state, o.Battlesnakes[snake.ID], nil)
+		}
+	}
+}
+
+func BenchmarkRenderMap25x25(b *testing.B) {
+	o, state := largeBoardOptions()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		renderMap(o, state, nil)
+	}
+}
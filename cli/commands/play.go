@@ -2,18 +2,27 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"github.com/corverroos/bsrules"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"hash/fnv"
+	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
 	"path"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,6 +34,14 @@ type Battlesnake struct {
 	LastMove  string
 	Squad     string
 	Character rune
+	Color     string
+	Version   string
+	Provider  MoveProvider
+	// PathTemplate is where the actions of the snake are under its URL, with {action} replaced
+	// by start, move or end. The actions are directly under the URL without one.
+	PathTemplate string
+	// Nickname is the short name the snake is shown by in logs and legends, set with --nick.
+	Nickname string
 }
 
 type Coord struct {
@@ -33,11 +50,11 @@ type Coord struct {
 }
 
 type InfoResponse struct {
-Author     string `json:"author"`
-Color      string `json:"color"`
-Head       string `json:"head"`
-Tail       string `json:"tail"`
-Meta interface{} `json:"meta"`
+	Author string      `json:"author"`
+	Color  string      `json:"color"`
+	Head   string      `json:"head"`
+	Tail   string      `json:"tail"`
+	Meta   interface{} `json:"meta"`
 }
 
 type SnakeResponse struct {
@@ -50,6 +67,18 @@ type SnakeResponse struct {
 	Length  int32   `json:"length"`
 	Shout   string  `json:"shout"`
 	Squad   string  `json:"squad"`
+	// CLI holds non-standard fields added by this CLI, namespaced to avoid colliding with the API.
+	CLI *SnakeExtensions `json:"x_battlesnake_cli,omitempty"`
+}
+
+// SnakeExtensions are opt-in conveniences for simple snakes, which official games don't provide.
+type SnakeExtensions struct {
+	// NearestFoodDistance is the Manhattan distance from the head to the nearest food, or -1 without food.
+	// It is omitted without --food-distance, and is never 0 as snakes eat the food under their heads.
+	NearestFoodDistance int32 `json:"nearest_food_distance,omitempty"`
+	// EliminatedCause and EliminatedBy are how an eliminated snake was eliminated, with --elimination-info.
+	EliminatedCause string `json:"eliminated_cause,omitempty"`
+	EliminatedBy    string `json:"eliminated_by,omitempty"`
 }
 
 type BoardResponse struct {
@@ -75,6 +104,8 @@ type ResponsePayload struct {
 type PlayerResponse struct {
 	Move  string `json:"move"`
 	Shout string `json:"shout"`
+	// Id is not part of the API, but buggy snakes sometimes echo back a snake id.
+	Id string `json:"id,omitempty"`
 }
 
 type PingResponse struct {
@@ -87,28 +118,174 @@ type PingResponse struct {
 }
 
 type Options struct {
-	GameId string
-	Turn int32
+	GameId       string
+	Turn         int32
 	Battlesnakes map[string]Battlesnake
-	HttpClient http.Client
-	Width int32
-	Height int32
-	Names []string
-	URLs []string
-	Squads []string
-	Timeout int32
-	Sequential bool
-	GameType string
-	ViewMap bool
-	Seed int64
-	Log func(string, ...interface{})
+	// HttpClient is used for all requests to snakes. If nil, a client with the timeout is used.
+	HttpClient          *http.Client
+	Width               int32
+	Height              int32
+	Names               []string
+	URLs                []string
+	Squads              []string
+	Timeout             int32
+	Sequential          bool
+	GameType            string
+	ViewMap             bool
+	Seed                int64
+	FoodPerSpawn        int32
+	FoodChance          int32
+	HealthWarn          int32
+	AutoScale           bool
+	AllowBodyCollisions bool
+	EliminateTrapped    bool
+	LogMoves            bool
+	Games               int
+	MaxTurns            int32
+	MinSnakesAlive      int
+	Snapshot            string
+	Resume              string
+	CheckIDs            bool
+	BoardFillReport     bool
+	CompactLog          bool
+	CheckGrowth         bool
+	FailOnTimeout       bool
+	BoardChecksum       bool
+	PayloadVersion      string
+	EventsFile          string
+	StrictTimeout       bool
+	RecordHTTP          string
+	Heatmap             string
+	VerboseEliminations bool
+	LengthStats         bool
+	Prewarm             bool
+	RandomHeadings      bool
+	HealthDecay         int32 // at least 1, or 0 for the standard decay of 1
+	RequireSymmetric    bool
+	RetryRateLimited    bool
+	LatencyHistogram    string
+	LatencyBuckets      string
+	Parallel            int
+	AuditHeadToHeads    bool
+	ViewMapClear        bool
+	InsecureSkipVerify  bool
+	CAFile              string
+	StalemateTurns      int32
+	MaxFood             int32
+	TurnOffset          int32
+	WarnNeckMoves       bool
+	BoardSeed           int64
+	Strict              bool
+	LogMovesCSV         string
+	WallsFile           string
+	WallDamage          int32
+	RenderHeads         bool
+	FoodDistance        bool
+	StatsFile           string
+	FirstMoveDelay      time.Duration
+	MaxDuration         time.Duration
+	ResultWebhook       string
+	FoodSchedule        string
+	SummaryOnly         bool
+	RandomSnakes        int
+	MoveSeed            int64
+	SnakeWeights        string
+	EchoRequest         bool
+	HazardPattern       string
+	HazardGrowth        int32
+	ShufflePlacement    bool
+	Placement           string
+	WinnerStats         bool
+	WinnerStatsFormat   string
+	Output              string
+	OutputFormat        string
+	PNGDir              string
+	PNGCell             int
+	GhostTurns          int32
+	Legend              string
+	InitialState        string
+	MoveTimeoutJitter   time.Duration
+	BoardViewer         string
+	MinHealthWarn       int32
+	AccessibleArea      bool
+	TeamSummary         bool
+	NoPing              bool
+	EliminationInfo     bool
+	StableChars         bool
+	ExportMovesOnly     string
+	PathTemplates       []string
+	DrawAsLoss          bool
+	MetricsAddr         string
+	Preview             bool
+	Nicknames           []string
+	SoloFixedHorizon    bool
+	Log                 func(string, ...interface{})
+	// Walls are the hazard walls of the walls game type, loaded from WallsFile if set.
+	Walls []rules.Point
+	// The squad rules, each enabled unless set to false, so all of them apply in a zero Options
+	// as they do by default on the command line.
+	SquadBodyCollisions    *bool
+	SquadSharedElimination *bool
+	SquadSharedHealth      *bool
+	SquadSharedLength      *bool
+
+	movesCSV *csv.Writer
+	// rand places snakes and food. It is seeded with the board seed at the start of each game.
+	rand *rand.Rand
+	// latencies are the move latencies of each snake, for --latency-histogram.
+	latencies map[string][]time.Duration
+	// shouts are what each snake shouted with its last move, for --board-viewer.
+	shouts map[string]string
+	// eliminatedTurns are the turns snakes were eliminated on, for --ghost-turns.
+	eliminatedTurns map[string]int32
+	// jitter draws the move response delays of --move-timeout-jitter.
+	jitter *rand.Rand
+	// randDraws counts what has been drawn from rand, for --export-moves-only.
+	randDraws *countingSource
+	// movesOnly records the moves of each turn with --export-moves-only.
+	movesOnly *json.Encoder
+	// belowHealth are the snakes whose health is below --min-health-warn, which have
+	// already had their health event.
+	belowHealth map[string]bool
 }
 
 type Result struct {
-	Turn int32
-	Winner string
-	Board *rules.BoardState
-	Infos map[string]InfoResponse
+	Turn         int32                   `json:"turn"`
+	Winner       string                  `json:"winner"`
+	Board        *rules.BoardState       `json:"board"`
+	Infos        map[string]InfoResponse `json:"infos"`
+	Snakes       []SnakeResult           `json:"snakes"`
+	EndReason    EndReason               `json:"end_reason"`
+	Eliminations []EliminationEvent      `json:"eliminations"`
+	// Squads are the combined results of each squad, and WinningSquad the squad left in the
+	// game, for --team-summary.
+	Squads       []SquadResult `json:"squads,omitempty"`
+	WinningSquad string        `json:"winning_squad,omitempty"`
+	// HealthEvents are the crossings below --min-health-warn, in the order they happened.
+	HealthEvents []HealthEvent `json:"health_events,omitempty"`
+	// ConformanceFailures are why the game was aborted with --fail-on-timeout.
+	ConformanceFailures []string `json:"conformance_failures,omitempty"`
+	// Occupancy is the heatmap of the game, collected for --heatmap.
+	Occupancy *Heatmap `json:"-"`
+}
+
+// EliminationEvent is a snake's elimination. Result.Eliminations lists them in the order they happened.
+type EliminationEvent struct {
+	Turn    int32  `json:"turn"`
+	SnakeID string `json:"snake_id"`
+	Cause   string `json:"cause"`
+	// By is the ID of the snake responsible for the elimination, if any.
+	By string `json:"by,omitempty"`
+}
+
+type SnakeResult struct {
+	Name            string `json:"name"`
+	Health          int32  `json:"health"`
+	Length          int32  `json:"length"`
+	EliminatedCause string `json:"eliminated_cause"`
+	EliminatedTurn  int32  `json:"eliminated_turn"`
+	Version         string `json:"version,omitempty"`
+	FoodEaten       int    `json:"food_eaten"`
 }
 
 var playCmd = &cobra.Command{
@@ -120,7 +297,6 @@ var playCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(playCmd)
 
-
 	var o Options
 
 	playCmd.Flags().Int32VarP(&o.Width, "width", "W", 11, "Width of Board")
@@ -128,73 +304,591 @@ func init() {
 	playCmd.Flags().StringArrayVarP(&o.Names, "name", "n", nil, "Name of Snake")
 	playCmd.Flags().StringArrayVarP(&o.URLs, "url", "u", nil, "URL of Snake")
 	playCmd.Flags().StringArrayVarP(&o.Names, "squad", "S", nil, "Squad of Snake")
+	playCmd.Flags().StringArrayVar(&o.PathTemplates, "path-template", nil, "Path of the Endpoints of a Named Snake Under its URL, as name=template With {action} for start, move or end (e.g. mysnake=/api/v1/{action})")
+	playCmd.Flags().StringVar(&o.MetricsAddr, "metrics-addr", "", "Serve Counters of Games, Turns, Move Requests and Timeouts With expvar at /debug/vars on This Address (e.g. :6060)")
+	playCmd.Flags().BoolVar(&o.DrawAsLoss, "draw-as-loss", false, "Count a Draw as a Loss for Every Snake in the Winner Stats of a Batch and in --stats-file")
+	playCmd.Flags().StringArrayVar(&o.Nicknames, "nick", nil, "Short Name to Show a Snake by in Logs and Legends, as url=nickname or name=nickname, Without Changing the Name Sent to Snakes")
+	o.SquadBodyCollisions = playCmd.Flags().Bool("squad-body-collisions", true, "Allow Snakes in a Squad to Move Through Each Other")
+	o.SquadSharedElimination = playCmd.Flags().Bool("squad-shared-elimination", true, "Eliminate a Squad Together When One of its Snakes is Eliminated")
+	o.SquadSharedHealth = playCmd.Flags().Bool("squad-shared-health", true, "Share Health Between Snakes in a Squad")
+	o.SquadSharedLength = playCmd.Flags().Bool("squad-shared-length", true, "Share Length Between Snakes in a Squad")
 	playCmd.Flags().Int32VarP(&o.Timeout, "timeout", "t", 500, "Request Timeout")
+	playCmd.Flags().BoolVar(&o.RetryRateLimited, "retry-rate-limited", false, "Retry Move Requests Rate Limited With 429 After Their Retry-After, Within the Timeout")
+	playCmd.Flags().BoolVar(&o.InsecureSkipVerify, "insecure-skip-verify", false, "Don't Verify the TLS Certificates of HTTPS Snakes")
+	playCmd.Flags().StringVar(&o.CAFile, "ca-file", "", "PEM File of CA Certificates to Trust for HTTPS Snakes")
 	playCmd.Flags().BoolVarP(&o.Sequential, "sequential", "s", false, "Use Sequential Processing")
 	playCmd.Flags().StringVarP(&o.GameType, "gametype", "g", "standard", "Type of Game Rules")
 	playCmd.Flags().BoolVarP(&o.ViewMap, "viewmap", "v", false, "View the Map Each Turn")
+	playCmd.Flags().BoolVar(&o.Preview, "preview", false, "Render the Initial Board and Exit Without Sending /start or Playing the Game")
+	playCmd.Flags().BoolVar(&o.ViewMapClear, "viewmap-clear", false, "Clear the Screen and Redraw the Map in Place Each Turn, When Logging to a Terminal")
 	playCmd.Flags().Int64VarP(&o.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed")
+	playCmd.Flags().Int64Var(&o.BoardSeed, "board-seed", 0, "Random Seed for Snake, Food and Hazard Placement (0 to Use --seed)")
+	playCmd.Flags().Int32Var(&o.FoodPerSpawn, "food-per-spawn", 0, "Number of Food to Top the Board Up to When Food Spawns (0 to Disable)")
+	playCmd.Flags().Int32Var(&o.HealthDecay, "health-decay", 1, "Health Snakes Lose Each Turn They Don't Eat, at Least 1")
+	playCmd.Flags().Int32Var(&o.MaxFood, "max-food", 0, "Maximum Food on the Board, Beyond which No Food Spawns (0 for No Limit)")
+	playCmd.Flags().Int32Var(&o.FoodChance, "food-per-spawn-chance", 15, "Chance of Spawning Multiple Food Each Turn")
+	playCmd.Flags().BoolVar(&o.AllowBodyCollisions, "allow-body-collisions", false, "Allow Snakes to Move Through Each Other's Bodies")
+	playCmd.Flags().BoolVar(&o.EliminateTrapped, "eliminate-trapped", false, "Eliminate Snakes with No Safe Move Before Moving")
+	playCmd.Flags().BoolVar(&o.AutoScale, "auto-scale", false, "Scale Minimum Food and Hazard Shrinking to Board Size")
+	playCmd.Flags().StringVarP(&o.Output, "output", "o", "", "File to Record the Game to as NDJSON Frames")
+	playCmd.Flags().StringVar(&o.OutputFormat, "output-format", FormatNDJSON, "Format of the Recorded Game (ndjson, jsonl-gzip or msgpack)")
+	playCmd.Flags().StringVar(&o.PNGDir, "png-dir", "", "Directory to Render Each Turn to as PNG")
+	playCmd.Flags().IntVar(&o.PNGCell, "png-cell", 20, "Pixel Size of Each Cell in PNG Renders")
+	playCmd.Flags().Int32Var(&o.GhostTurns, "ghost-turns", 0, "Draw Eliminated Snakes Faded on the Map and in PNGs for this Many Turns After They Die (0 to Disable)")
+	playCmd.Flags().StringVar(&o.ExportMovesOnly, "export-moves-only", "", "File to Record Only the Initial State and the Moves of Each Turn to, Which Replay Reconstructs the Game From")
+	playCmd.Flags().StringVar(&o.BoardViewer, "board-viewer", "", "File to Export the Game to in the Engine API Format Loaded by the Official Board Viewer")
+	playCmd.Flags().BoolVar(&o.StableChars, "stable-chars", false, "Pick the Map Character of Each Snake by its Name, Instead of by the Order Snakes are Given in")
+	playCmd.Flags().StringVar(&o.Legend, "legend", "", "File to Write the Color and Map Character of Each Snake to as JSON, Keyed by Snake ID")
+	playCmd.Flags().StringVar(&o.InitialState, "initial-state", "", "JSON Frame to Start the Game From (Snakes are Matched in Order)")
+	playCmd.Flags().BoolVar(&o.LogMoves, "log-moves", false, "Log the Move Used for Each Snake Each Turn as JSON")
+	playCmd.Flags().IntVar(&o.Games, "games", 1, "Number of Games to Play, Incrementing the Seed Each Game")
+	playCmd.Flags().IntVar(&o.Parallel, "parallel", 1, "Number of Games to Play at Once With --games, Not Allowed With Per-Game Files Like --output")
+	playCmd.Flags().BoolVar(&o.SoloFixedHorizon, "solo-fixed-horizon", false, "Keep Playing Solo Games Until --max-turns After the Snake is Eliminated, for Comparing Snakes Over the Same Number of Turns")
+	playCmd.Flags().BoolVar(&o.TeamSummary, "team-summary", false, "Report the Results of Squad Games by Squad, with the Combined Length and Survival of Each")
+	playCmd.Flags().BoolVar(&o.WinnerStats, "winner-stats", false, "Print Aggregated Winner Stats After a Batch of Games")
+	playCmd.Flags().StringVar(&o.WinnerStatsFormat, "winner-stats-format", "table", "Format of Winner and Game Length Stats (table or json)")
+	playCmd.Flags().BoolVar(&o.LengthStats, "length-stats", false, "Print the Distribution of Game Lengths After a Batch of Games")
+	playCmd.Flags().BoolVar(&o.ShufflePlacement, "shuffle-placement", false, "Shuffle the Order Snakes are Placed in by Seed")
+	playCmd.Flags().StringVar(&o.Placement, "placement", "", "Start Snakes in the corners, on the edges or at random Points Instead of the Official Start Positions")
+	playCmd.Flags().BoolVar(&o.RandomHeadings, "random-headings", false, "Start Snakes Facing Random Directions by Seed, Instead of Stacked")
+	playCmd.Flags().BoolVar(&o.RequireSymmetric, "require-symmetric-start", false, "Stop if the Snakes Don't Start in Symmetric Positions")
+	playCmd.Flags().Int32Var(&o.StalemateTurns, "stalemate-turns", 0, "End the Game as a Draw After this Many Turns Without Any Snake Changing (0 to Disable)")
+	playCmd.Flags().Int32Var(&o.MaxTurns, "max-turns", 0, "Stop the Game at this Turn (0 for No Limit)")
+	playCmd.Flags().IntVar(&o.MinSnakesAlive, "min-snakes-alive", 0, "Stop the Game Once Fewer than this Many Snakes Are Alive (0 for No Limit)")
+	playCmd.Flags().StringVar(&o.Snapshot, "snapshot", "", "File to Save the Game to if Stopped Early (Ctrl-C or Turn Limit)")
+	playCmd.Flags().StringVar(&o.Resume, "resume", "", "Snapshot File to Resume a Game From (Snakes are Matched in Order)")
+	playCmd.Flags().BoolVar(&o.CheckIDs, "check-ids", false, "Warn When a Snake Responds with an ID Other Than its Own")
+	playCmd.Flags().StringVar(&o.RecordHTTP, "record-requests-responses", "", "File to Record Every HTTP Request and Response to as JSON Lines, with Credentials Redacted")
+	playCmd.Flags().StringVar(&o.Heatmap, "heatmap", "", "File to Write How Often Each Cell Was Occupied by Snakes Over All Games to, as a PNG if it Ends in .png or Else as CSV")
+	playCmd.Flags().BoolVar(&o.StrictTimeout, "strict-timeout", false, "Enforce the Timeout as a Budget for All Moves of a Turn, Including Retries, Using the Last Move for Snakes Out of Time")
+	playCmd.Flags().StringVar(&o.EventsFile, "events", "", "JSON File of Events that Re-Seed the Game or Place Food at Given Turns, to Script Scenarios")
+	playCmd.Flags().StringVar(&o.PayloadVersion, "payload-version", "", "Force the Schema of Payloads Sent to All Snakes: 1 for the Current API or 0 for the Legacy API (Default Current)")
+	playCmd.Flags().BoolVar(&o.BoardChecksum, "board-checksum", false, "Log a Short Checksum of the Board Each Turn, to Find Where Two Runs Diverge")
+	playCmd.Flags().BoolVar(&o.FailOnTimeout, "fail-on-timeout", false, "Abort the Game and Exit with an Error if Any Snake Times Out or Returns an Invalid Move, for Conformance Testing")
+	playCmd.Flags().BoolVar(&o.CheckGrowth, "check-growth", false, "Flag Snakes That Grow Without Eating or Eat Without Growing, as Rule Bugs")
+	playCmd.Flags().BoolVar(&o.BoardFillReport, "board-fill-report", false, "Log the Cells Occupied by Snakes Each Turn")
+	playCmd.Flags().BoolVar(&o.AccessibleArea, "accessible-area", false, "Log the Free Cells Each Snake Can Reach From its Head Each Turn")
+	playCmd.Flags().Int32Var(&o.TurnOffset, "turn-offset", 0, "Turn to Start the Game at, to Align Spliced Games with the Original")
+	playCmd.Flags().BoolVar(&o.WarnNeckMoves, "warn-neck-moves", false, "Warn When a Snake Moves Back into its Own Neck, Forfeiting the Game")
+	playCmd.Flags().BoolVar(&o.Strict, "strict", false, "Reject Move Responses with Unknown Fields, Using the Fallback Move")
+	playCmd.Flags().StringVar(&o.LatencyHistogram, "latency-histogram", "", "Log a Histogram of Each Snake's Move Latencies at the End of the Game (text or json)")
+	playCmd.Flags().StringVar(&o.LatencyBuckets, "latency-buckets", "50,100,200,400", "Upper Bounds in Milliseconds of the Latency Histogram Buckets")
+	playCmd.Flags().StringVar(&o.LogMovesCSV, "log-moves-csv", "", "CSV File to Write the Move and Latency of Each Snake Each Turn to")
+	playCmd.Flags().StringVar(&o.WallsFile, "walls-file", "", "Board Layout with # for Hazard Walls, for the walls Game Type")
+	playCmd.Flags().Int32Var(&o.WallDamage, "wall-damage", rules.SnakeMaxHealth, "Damage Dealt to Snakes Moving into Hazard Walls")
+	playCmd.Flags().BoolVar(&o.RenderHeads, "render-heads", false, "Draw Snake Heads with a Distinct Glyph in the Map")
+	playCmd.Flags().BoolVar(&o.CompactLog, "compact-log", false, "Log a Single Line Summary of Each Turn")
+	playCmd.Flags().BoolVar(&o.VerboseEliminations, "verbose-eliminations", false, "Log Where Each Snake Was Eliminated")
+	playCmd.Flags().BoolVar(&o.AuditHeadToHeads, "audit-head-to-heads", false, "Log Every Head-to-Head Collision and Its Outcome at the End of the Game")
+	playCmd.Flags().IntVar(&o.RandomSnakes, "random-snakes", 0, "Number of In-Process Random Snakes to Add to the Game")
+	playCmd.Flags().StringVar(&o.SnakeWeights, "snake-weights", "", "Weights of the Moves of Random Snakes as direction=weight Pairs Separated by Commas (e.g. up=4,left=1,right=1)")
+	playCmd.Flags().Int64Var(&o.MoveSeed, "move-seed", 0, "Seed the Moves of In-Process Snakes With This and the Game Seed, Instead of Only the Game Seed")
+	playCmd.Flags().BoolVar(&o.SummaryOnly, "summary-only", false, "Only Print the Aggregated Winner Stats of the Games Played")
+	playCmd.Flags().BoolVar(&o.EchoRequest, "echo-request", false, "Log the Pretty-Printed Move Request Sent to Each Snake Each Turn")
+	playCmd.Flags().BoolVar(&o.NoPing, "no-ping", false, "Don't GET the Root of Each Snake, Assuming API Version 1 and Using the Default Appearance")
+	playCmd.Flags().BoolVar(&o.Prewarm, "prewarm", false, "Send Each Snake a Throwaway /move Before the First Turn, to Warm Up Cold Starts")
+	playCmd.Flags().DurationVar(&o.MaxDuration, "max-duration", 0, "Stop the Game if it Runs Longer than this Wall-Clock Time (e.g. 5m, 0 for No Limit)")
+	playCmd.Flags().DurationVar(&o.MoveTimeoutJitter, "move-timeout-jitter", 0, "Delay Each Move Response by a Random Duration up to This, Seeded by the Game Seed, to Simulate Network Jitter (e.g. 100ms)")
+	playCmd.Flags().DurationVar(&o.FirstMoveDelay, "first-move-delay", 0, "Time to Wait After Starting the Game Before the First Move (e.g. 2s)")
+	playCmd.Flags().BoolVar(&o.EliminationInfo, "elimination-info", false, "Add the Non-Standard Cause and Eliminator of Eliminated Snakes to Payloads")
+	playCmd.Flags().BoolVar(&o.FoodDistance, "food-distance", false, "Add the Non-Standard Distance to the Nearest Food to Each Snake in Payloads")
+	playCmd.Flags().StringVar(&o.StatsFile, "stats-file", "", "JSON File Recording Wins, Losses and Draws per Snake Across Runs")
+	playCmd.Flags().StringVar(&o.ResultWebhook, "result-webhook", "", "URL to POST the Result of Each Game to as JSON")
+	playCmd.Flags().StringVar(&o.FoodSchedule, "food-schedule", "", "Minimum Food from Given Turns, as turn:food Pairs (e.g. 0:1,150:3)")
+	playCmd.Flags().StringVar(&o.HazardPattern, "hazard-pattern", "", "Pattern of Hazards to Grow During the Game (spiral)")
+	playCmd.Flags().Int32Var(&o.HazardGrowth, "hazard-growth", 3, "Turns Between Each Growth of the Hazard Pattern")
+	playCmd.Flags().Int32Var(&o.HealthWarn, "health-warn", 0, "Log Snakes with Health Below this Threshold (0 to Disable)")
+	playCmd.Flags().Int32Var(&o.MinHealthWarn, "min-health-warn", 0, "Log a Health Event Each Time a Snake's Health Drops Below this Threshold (0 to Disable)")
 
-	playCmd.Run = makeRun(&o)
+	playCmd.RunE = makeRun(&o)
 }
 
-var makeRun = func(o *Options) func(cmd *cobra.Command, args []string) {
-	return func(cmd *cobra.Command, args []string) {
-		res := Run(o)
-		o.Log("%#v", res)
+var makeRun = func(o *Options) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		// Zero only stands for the standard decay in Options built in code, the flag defaults to 1.
+		if o.HealthDecay < 1 {
+			return fmt.Errorf("health decay must be at least 1, got %v", o.HealthDecay)
+		}
+		playGames(o)
+		return nil
 	}
 }
 
-func Run(o *Options) Result {
-	rand.Seed(o.Seed)
+func playGames(o *Options) {
+	if o.Log == nil {
+		o.Log = log.Printf
+	}
+	if o.MetricsAddr != "" {
+		serveMetrics(o, o.MetricsAddr)
+	}
+	if o.Preview {
+		Run(o)
+		return
+	}
+	if o.SummaryOnly {
+		// Only the aggregated results are logged, which with --random-snakes makes for fast simulations.
+		logf := o.Log
+		o.Log = func(string, ...interface{}) {}
+		results := RunBatch(o)
+		o.Log = logf
+		printWinnerStats(o, buildWinnerStats(results, o.DrawAsLoss))
+		if o.LengthStats {
+			printGameLengthStats(o, BuildGameLengthStats(results))
+		}
+		if o.Heatmap != "" {
+			writeHeatmap(o, BuildHeatmap(results))
+		}
+		exitOnConformanceFailure(results)
+		return
+	}
+	if o.Games > 1 {
+		results := RunBatch(o)
+		if o.WinnerStats {
+			printWinnerStats(o, buildWinnerStats(results, o.DrawAsLoss))
+		}
+		if o.LengthStats {
+			printGameLengthStats(o, BuildGameLengthStats(results))
+		}
+		if o.Heatmap != "" {
+			writeHeatmap(o, BuildHeatmap(results))
+		}
+		exitOnConformanceFailure(results)
+		return
+	}
+	res := Run(o)
+	o.Log("%#v", res)
+	if o.Heatmap != "" {
+		writeHeatmap(o, BuildHeatmap([]Result{res}))
+	}
+	exitOnConformanceFailure([]Result{res})
+}
 
-	o.Battlesnakes = make(map[string]Battlesnake)
-	o.GameId = uuid.New().String()
-	o.Turn = 0
+func Run(o *Options) Result {
 	if o.Log == nil {
 		o.Log = log.Printf
 	}
 
+	o.Turn = o.TurnOffset
+	var snapshot Snapshot
+	if o.Resume != "" {
+		var err error
+		snapshot, err = readSnapshot(o.Resume)
+		if err != nil {
+			log.Panicf("[PANIC]: Error Loading Snapshot: %v", err)
+		}
+		o.Seed = snapshot.Seed
+		o.Turn = snapshot.Turn
+	}
+
+	// Placement of snakes and food, and hazards, use the board seed. Each game has its own
+	// source so that games can be played concurrently.
+	o.randDraws = newCountingSource(boardSeed(o))
+	o.rand = rand.New(o.randDraws)
+	skipRandDraws(o, snapshot.RandDraws)
+	o.jitter = newJitterSource(o)
+
+	if o.Timeout == 0 {
+		o.Timeout = 500
+	}
+	if o.HttpClient == nil {
+		o.HttpClient = &http.Client{
+			Timeout: time.Duration(o.Timeout) * time.Millisecond,
+		}
+		if o.InsecureSkipVerify || o.CAFile != "" {
+			tlsConfig, err := buildTLSConfig(o)
+			if err != nil {
+				log.Panicf("[PANIC]: Error Configuring TLS: %v", err)
+			}
+			o.HttpClient.Transport = &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			}
+		}
+	}
+	if o.RecordHTTP != "" {
+		f, err := os.Create(o.RecordHTTP)
+		if err != nil {
+			log.Panicf("[PANIC]: Error Creating HTTP Capture File: %v", err)
+		}
+		defer f.Close()
+		defer func(client *http.Client) { o.HttpClient = client }(o.HttpClient)
+		client := *o.HttpClient
+		client.Transport = newCaptureTransport(client.Transport, f)
+		o.HttpClient = &client
+	}
+
+	if o.Output != "" && !isOutputFormat(o.OutputFormat) {
+		log.Panicf("[PANIC]: Unknown Output Format %v", o.OutputFormat)
+	}
+	if o.HazardPattern != "" && o.HazardPattern != "spiral" {
+		log.Panicf("[PANIC]: Unknown Hazard Pattern %v", o.HazardPattern)
+	}
+	if _, err := parseFoodSchedule(o.FoodSchedule); err != nil {
+		log.Panicf("[PANIC]: Invalid Food Schedule: %v", err)
+	}
+	if o.Placement != "" && !rules.IsPlacement(o.Placement) {
+		log.Panicf("[PANIC]: Unknown Placement %v", o.Placement)
+	}
+	if !isPayloadVersion(o.PayloadVersion) {
+		log.Panicf("[PANIC]: Unknown Payload Version %v", o.PayloadVersion)
+	}
+	if _, err := parseSnakeWeights(o.SnakeWeights); err != nil {
+		log.Panicf("[PANIC]: Invalid Snake Weights: %v", err)
+	}
+	if o.ExportMovesOnly != "" && o.EventsFile != "" {
+		log.Panicf("[PANIC]: Games With Events Can't Be Reconstructed From Their Moves")
+	}
+	if _, err := parsePathTemplates(o.PathTemplates); err != nil {
+		log.Panicf("[PANIC]: Invalid Path Template: %v", err)
+	}
+	if _, err := parseNicknames(o.Nicknames); err != nil {
+		log.Panicf("[PANIC]: Invalid Nickname: %v", err)
+	}
+	if o.HealthDecay < 0 {
+		log.Panicf("[PANIC]: Health Decay Must Be at Least 1 (or 0 for the Standard Decay of 1), Got %v", o.HealthDecay)
+	}
+	if o.SoloFixedHorizon && o.GameType == "solo" && o.MaxTurns <= 0 {
+		log.Panicf("[PANIC]: Solo Fixed Horizon Requires --max-turns")
+	}
+	if o.MoveTimeoutJitter < 0 {
+		log.Panicf("[PANIC]: Move Timeout Jitter Must Not Be Negative")
+	}
+	if o.LatencyHistogram != "" {
+		if o.LatencyHistogram != "text" && o.LatencyHistogram != "json" {
+			log.Panicf("[PANIC]: Unknown Latency Histogram Format %v", o.LatencyHistogram)
+		}
+		if _, err := parseLatencyBuckets(o.LatencyBuckets); err != nil {
+			log.Panicf("[PANIC]: Invalid Latency Buckets: %v", err)
+		}
+	}
+
+	o.Battlesnakes = make(map[string]Battlesnake)
+	o.GameId = uuid.New().String()
+
 	snakes := buildSnakesFromOptions(o)
+	snakes = append(snakes, buildRandomSnakes(o, len(snakes))...)
+
+	var initialState *rules.BoardState
+	if o.Resume != "" {
+		initialState = snapshot.State
+		if err := assignSnakeIDs(initialState, snakes); err != nil {
+			log.Panicf("[PANIC]: Error Loading Snapshot: %v", err)
+		}
+	} else if o.InitialState != "" {
+		var err error
+		initialState, err = loadInitialState(o.InitialState, o.GameType, snakes)
+		if err != nil {
+			log.Panicf("[PANIC]: Error Loading Initial State: %v", err)
+		}
+	} else if err := validateSnakeIDs(snakes); err != nil {
+		log.Panicf("[PANIC]: Error Building Snakes: %v", err)
+	}
+
+	if o.GameType == "walls" && o.WallsFile != "" {
+		walls, width, height, err := loadWalls(o.WallsFile)
+		if err != nil {
+			log.Panicf("[PANIC]: Error Loading Walls: %v", err)
+		}
+		o.Walls, o.Width, o.Height = walls, width, height
+	}
+
+	var events []Event
+	if o.EventsFile != "" {
+		var err error
+		events, err = loadEvents(o.EventsFile)
+		if err != nil {
+			log.Panicf("[PANIC]: Error Loading Events: %v", err)
+		}
+	}
 
 	var ruleset rules.Ruleset
 	var royale rules.RoyaleRuleset
 	var outOfBounds []rules.Point
+	if o.GameType == "walls" {
+		outOfBounds = o.Walls
+	}
 
 	ruleset, _ = getRuleset(o, snakes)
 
 	infos := getSnakeInfos(o, snakes)
 
-	state := initializeBoardFromArgs(o, ruleset, snakes)
+	state := initializeBoardFromArgs(o, ruleset, snakes, initialState)
+	if !o.Preview {
+		sendStartRequests(o, state, snakes)
+	}
+	applyEvents(o, events, state)
+	if o.RequireSymmetric && !rules.IsStartSymmetric(state) {
+		log.Panicf("[PANIC]: Snakes Don't Start in Symmetric Positions")
+	}
+	if o.Preview {
+		// Only the initial board is rendered, without playing the game.
+		for _, snake := range snakes {
+			o.Battlesnakes[snake.ID] = snake
+		}
+		o.Log("%s", renderMap(o, state, outOfBounds))
+		return Result{Turn: o.Turn, Board: state, Infos: infos}
+	}
+	if o.FirstMoveDelay > 0 {
+		// Give slow starting snakes, like freshly started containers, time to become ready.
+		time.Sleep(o.FirstMoveDelay)
+	}
+	if o.Prewarm {
+		prewarmSnakes(o, state, snakes)
+	}
 	for _, snake := range snakes {
 		o.Battlesnakes[snake.ID] = snake
 	}
+	if o.BoardChecksum {
+		logBoardChecksum(o, state, outOfBounds)
+	}
+	if o.Legend != "" {
+		writeLegend(o, snakes)
+	}
 
-	for v := false; !v; v, _ = ruleset.IsGameOver(state) {
+	var output *recorder
+	if o.Output != "" {
+		f, err := os.Create(o.Output)
+		if err != nil {
+			o.Log("[WARN]: Unable to create output file %v: %v", o.Output, err)
+		} else {
+			output, _ = newRecorder(f, o.OutputFormat)
+			defer output.Close()
+			if err := output.writeHeader(buildHeader(o, ruleset, snakes)); err != nil {
+				o.Log("[WARN]: Unable to record header: %v", err)
+			}
+		}
+	}
+	recordFrame(o, output, state, outOfBounds)
+	o.movesOnly = nil
+	if o.ExportMovesOnly != "" {
+		f, err := os.Create(o.ExportMovesOnly)
+		if err != nil {
+			o.Log("[WARN]: Unable to create moves-only file %v: %v", o.ExportMovesOnly, err)
+		} else {
+			defer f.Close()
+			o.movesOnly = json.NewEncoder(f)
+			writeMovesOnlyStart(o, buildHeader(o, ruleset, snakes), state, outOfBounds)
+		}
+	}
+
+	o.latencies = make(map[string][]time.Duration)
+	o.shouts = make(map[string]string)
+	o.movesCSV = nil
+	if o.LogMovesCSV != "" {
+		f, err := os.Create(o.LogMovesCSV)
+		if err != nil {
+			o.Log("[WARN]: Unable to create moves CSV %v: %v", o.LogMovesCSV, err)
+		} else {
+			defer f.Close()
+			o.movesCSV = csv.NewWriter(f)
+			defer o.movesCSV.Flush()
+			_ = o.movesCSV.Write([]string{"turn", "name", "move", "latency_ms", "fallback"})
+		}
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	if o.Snapshot != "" {
+		signal.Notify(interrupted, os.Interrupt)
+		defer signal.Stop(interrupted)
+	}
+
+	record := rules.GameRecord{StartTurn: o.Turn, States: []*rules.BoardState{state}}
+	var occupancy *Heatmap
+	if o.Heatmap != "" {
+		occupancy = newHeatmap(state.Width, state.Height)
+		occupancy.addState(state)
+	}
+	// Snakes already eliminated when the game starts, like in a snapshot, keep their elimination.
+	eliminatedTurns, eliminations := restoreEliminations(o, state, snapshot.EliminatedTurns)
+	o.eliminatedTurns = eliminatedTurns
+	var viewerFrames []ViewerFrame
+	if o.BoardViewer != "" {
+		viewerFrames = append(viewerFrames, buildViewerFrame(o, state, outOfBounds, infos, eliminatedTurns))
+	}
+	foodEaten := make(map[string]int)
+	var healthEvents []HealthEvent
+	o.belowHealth = make(map[string]bool)
+	var isInterrupted, isStalemate, isTimedOut, isBelowMinSnakes bool
+	var conformance *ConformanceError
+	var unchangedTurns int32
+	started := time.Now()
+	for v := false; !v; v = isGameOver(o, ruleset, state) {
 		o.Turn++
 		ruleset, royale = getRuleset(o, snakes)
-		state, outOfBounds = createNextBoardState(o,ruleset, royale, state, outOfBounds, snakes)
+		prevState := state
+		// With --solo-fixed-horizon, the board keeps going without moves once the snake is eliminated.
+		moving := snakes
+		if o.GameType == "solo" && len(aliveSnakes(state)) == 0 {
+			moving = nil
+		}
+		var err error
+		state, outOfBounds, err = createNextBoardState(o, ruleset, royale, state, outOfBounds, moving)
+		if cerr, ok := err.(*ConformanceError); ok {
+			conformance = cerr
+			for _, failure := range cerr.Failures {
+				o.Log("[CONFORMANCE]: [%v]: %v", o.Turn, failure)
+			}
+			// The moves of the turn weren't applied.
+			o.Turn--
+			break
+		}
+		turnsProcessed.Add(1)
+		applyEvents(o, events, state)
+		if o.BoardChecksum {
+			logBoardChecksum(o, state, outOfBounds)
+		}
+		countFoodEaten(prevState, state, foodEaten)
+		if o.CheckGrowth && o.GameType != "constrictor" && !(o.GameType == "squad" && enabled(o.SquadSharedLength)) {
+			if err, ok := rules.CheckGrowth(prevState, state).(*rules.GrowthError); ok {
+				o.Log("[BUG]: [%v]: %v length changed from %v to %v, expected %v", o.Turn, displayName(o.Battlesnakes[err.SnakeID]), err.From, err.To, err.Expected)
+			}
+		}
+		if snakesUnchanged(prevState, state) {
+			unchangedTurns++
+		} else {
+			unchangedTurns = 0
+		}
 		if o.ViewMap {
 			printMap(o, state, outOfBounds)
+		} else if o.CompactLog {
+			o.Log("%s\n", compactStateLine(o, state, outOfBounds))
 		} else {
 			o.Log("[%v]: State: %v OutOfBounds: %v\n", o.Turn, state, outOfBounds)
 		}
+		for _, snake := range state.Snakes {
+			if _, ok := eliminatedTurns[snake.ID]; !ok && snake.EliminatedCause != rules.NotEliminated {
+				eliminatedTurns[snake.ID] = o.Turn
+				eliminations = append(eliminations, EliminationEvent{
+					Turn:    o.Turn,
+					SnakeID: snake.ID,
+					Cause:   snake.EliminatedCause,
+					By:      snake.EliminatedBy,
+				})
+				if o.VerboseEliminations {
+					logElimination(o, snake)
+				}
+			}
+		}
+		if o.AuditHeadToHeads {
+			record.States = append(record.States, state)
+		}
+		if occupancy != nil {
+			occupancy.addState(state)
+		}
+		logLowHealth(o, state)
+		healthEvents = append(healthEvents, logHealthCrossings(o, state)...)
+		if o.BoardFillReport {
+			logBoardFill(o, state)
+		}
+		if o.AccessibleArea {
+			logAccessibleArea(o, state)
+		}
+		recordFrame(o, output, state, outOfBounds)
+		if o.BoardViewer != "" {
+			viewerFrames = append(viewerFrames, buildViewerFrame(o, state, outOfBounds, infos, eliminatedTurns))
+		}
+		if o.PNGDir != "" {
+			writePNG(o, state, outOfBounds)
+		}
+		if o.StalemateTurns > 0 && unchangedTurns >= o.StalemateTurns {
+			isStalemate = true
+			break
+		}
+		if o.MaxTurns > 0 && o.Turn >= o.MaxTurns {
+			break
+		}
+		if o.MinSnakesAlive > 0 && len(aliveSnakes(state)) < o.MinSnakesAlive {
+			if isOver, _ := ruleset.IsGameOver(state); !isOver {
+				isBelowMinSnakes = true
+			}
+			break
+		}
+		if o.MaxDuration > 0 && time.Since(started) >= o.MaxDuration {
+			if isOver, _ := ruleset.IsGameOver(state); !isOver {
+				isTimedOut = true
+			}
+			break
+		}
+		select {
+		case <-interrupted:
+			isInterrupted = true
+		default:
+		}
+		if isInterrupted {
+			break
+		}
+	}
+
+	if o.Snapshot != "" && !isStalemate {
+		if isOver, _ := ruleset.IsGameOver(state); !isOver {
+			err := writeSnapshot(o.Snapshot, Snapshot{Turn: o.Turn, Seed: o.Seed, State: state, RandDraws: o.randDraws.draws, EliminatedTurns: eliminatedTurns})
+			if err != nil {
+				o.Log("[WARN]: Unable to write snapshot %v: %v", o.Snapshot, err)
+			} else {
+				o.Log("[DONE]: Snapshot of turn %v written to %v.", o.Turn, o.Snapshot)
+			}
+		}
 	}
 
 	res := Result{
-		Board: state,
-		Turn: o.Turn,
-		Infos: infos,
+		Board:        state,
+		Turn:         o.Turn,
+		Infos:        infos,
+		Snakes:       buildSnakeResults(o, state, eliminatedTurns, foodEaten),
+		EndReason:    classifyEndReason(o, ruleset, state),
+		Eliminations: eliminations,
+		HealthEvents: healthEvents,
+		Occupancy:    occupancy,
+	}
+	if isInterrupted {
+		res.EndReason = EndReasonInterrupted
+	} else if isStalemate {
+		res.EndReason = EndReasonStalemate
+	} else if isTimedOut {
+		res.EndReason = EndReasonTimedOut
+	} else if isBelowMinSnakes {
+		res.EndReason = EndReasonMinSnakesAlive
+	} else if conformance != nil {
+		res.EndReason = EndReasonConformanceFailure
+		res.ConformanceFailures = conformance.Failures
+	}
+	for i, sr := range res.Snakes {
+		name := displayName(o.Battlesnakes[state.Snakes[i].ID])
+		o.Log("[DONE]: %v finished with length %v and health %v (%v).", snakeLabel(name, sr.Version), sr.Length, sr.Health, eliminationSummary(sr.EliminatedCause))
 	}
 
-	if o.GameType == "solo" {
+	if res.EndReason == EndReasonStalemate {
+		o.Log("[DONE]: Game completed after %v turns. It was a draw by stalemate after %v turns without change.", o.Turn, unchangedTurns)
+		for _, snake := range state.Snakes {
+			if snake.EliminatedCause == rules.NotEliminated {
+				sendEndRequest(o, state, o.Battlesnakes[snake.ID])
+			}
+		}
+	} else if res.EndReason == EndReasonMinSnakesAlive {
+		alive := aliveSnakes(state)
+		names := make([]string, len(alive))
+		for i, snake := range alive {
+			names[i] = displayName(o.Battlesnakes[snake.ID])
+			sendEndRequest(o, state, o.Battlesnakes[snake.ID])
+		}
+		o.Log("[DONE]: Game stopped (%v) after %v turns with %v snakes alive: %v.", res.EndReason, o.Turn, len(alive), strings.Join(names, ", "))
+	} else if res.EndReason == EndReasonTurnLimit || res.EndReason == EndReasonInterrupted || res.EndReason == EndReasonTimedOut || res.EndReason == EndReasonConformanceFailure {
+		o.Log("[DONE]: Game stopped (%v) after %v turns.", res.EndReason, o.Turn)
+		for _, snake := range state.Snakes {
+			if snake.EliminatedCause == rules.NotEliminated {
+				sendEndRequest(o, state, o.Battlesnakes[snake.ID])
+			}
+		}
+	} else if o.GameType == "solo" {
 		o.Log("[DONE]: Game completed after %v turns.", o.Turn)
 	} else {
-		var winner string
+		var winner, winnerName string
 		isDraw := true
 		for _, snake := range state.Snakes {
 			if snake.EliminatedCause == rules.NotEliminated {
 				isDraw = false
 				winner = o.Battlesnakes[snake.ID].Name
+				winnerName = displayName(o.Battlesnakes[snake.ID])
 				sendEndRequest(o, state, o.Battlesnakes[snake.ID])
 			}
 		}
@@ -204,62 +898,487 @@ func Run(o *Options) Result {
 		if isDraw {
 			o.Log("[DONE]: Game completed after %v turns. It was a draw.", o.Turn)
 		} else {
-			o.Log("[DONE]: Game completed after %v turns. %v is the winner.", o.Turn, winner)
+			o.Log("[DONE]: Game completed after %v turns. %v is the winner.", o.Turn, winnerName)
+		}
+	}
+
+	if o.TeamSummary && o.GameType == "squad" {
+		res.Squads = buildSquadResults(o, state, buildSquadMap(snakes), eliminatedTurns)
+		res.WinningSquad = winningSquad(res.Squads)
+		logTeamSummary(o, res.Squads, res.WinningSquad)
+	}
+	if o.BoardViewer != "" {
+		writeBoardViewer(o, state, royale, viewerFrames)
+	}
+	if o.AuditHeadToHeads {
+		logHeadToHeadAudit(o, rules.AuditHeadToHeads(record))
+	}
+	if o.LatencyHistogram != "" {
+		logLatencyHistograms(o, snakes)
+	}
+
+	if o.ResultWebhook != "" {
+		postResult(o, res)
+	}
+	if o.StatsFile != "" {
+		if err := updateStatsFile(o.StatsFile, o.GameType, res, o.DrawAsLoss); err != nil {
+			o.Log("[WARN]: Unable to update stats file %v: %v", o.StatsFile, err)
+		}
+	}
+	gamesPlayed.Add(1)
+
+	return res
+}
+
+// isGameOver reports whether the game is over. With --solo-fixed-horizon, solo games only end
+// at the turn limit, even after the snake is eliminated.
+func isGameOver(o *Options, ruleset rules.Ruleset, state *rules.BoardState) bool {
+	if o.SoloFixedHorizon && o.GameType == "solo" {
+		return false
+	}
+	isOver, _ := ruleset.IsGameOver(state)
+	return isOver
+}
+
+// logElimination logs where a snake was eliminated, which is where its head moved to,
+// even if that is off the board.
+func logElimination(o *Options, snake rules.Snake) {
+	var head rules.Point
+	if len(snake.Body) > 0 {
+		head = snake.Body[0]
+	}
+	line := fmt.Sprintf("[ELIMINATED]: [%v]: %v %v at (%v,%v)", o.Turn, displayName(o.Battlesnakes[snake.ID]), eliminationCauseString(snake.EliminatedCause), head.X, head.Y)
+	if by, ok := o.Battlesnakes[snake.EliminatedBy]; ok && snake.EliminatedBy != "" && snake.EliminatedBy != snake.ID {
+		line += fmt.Sprintf(", eliminated by %v", displayName(by))
+	}
+	o.Log("%s", line)
+}
+
+// snakesUnchanged reports whether no snake's health, body or elimination changed between two states.
+func snakesUnchanged(prev, next *rules.BoardState) bool {
+	if len(prev.Snakes) != len(next.Snakes) {
+		return false
+	}
+	for i, snake := range next.Snakes {
+		other := prev.Snakes[i]
+		if snake.ID != other.ID || snake.Health != other.Health || snake.EliminatedCause != other.EliminatedCause || len(snake.Body) != len(other.Body) {
+			return false
+		}
+		for j := range snake.Body {
+			if snake.Body[j] != other.Body[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func recordFrame(o *Options, w *recorder, state *rules.BoardState, outOfBounds []rules.Point) {
+	if w == nil {
+		return
+	}
+	if err := w.writeFrame(buildFrame(o, state, outOfBounds)); err != nil {
+		o.Log("[WARN]: Unable to record turn %v: %v", o.Turn, err)
+	}
+}
+
+// compactStateLine summarises a turn on a single line, listing each snake that hasn't been eliminated.
+func compactStateLine(o *Options, state *rules.BoardState, outOfBounds []rules.Point) string {
+	var alive []string
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated || len(snake.Body) == 0 {
+			continue
+		}
+		head := snake.Body[0]
+		alive = append(alive, fmt.Sprintf("%v (%v,%v) len=%v hp=%v", displayName(o.Battlesnakes[snake.ID]), head.X, head.Y, len(snake.Body), snake.Health))
+	}
+	line := fmt.Sprintf("[%v]: Alive: %v Food: %v Hazards: %v", o.Turn, len(alive), len(state.Food), len(outOfBounds))
+	if len(alive) > 0 {
+		line += " | " + strings.Join(alive, " | ")
+	}
+	return line
+}
+
+func logBoardFill(o *Options, state *rules.BoardState) {
+	occupied, free := boardFill(state)
+	total := occupied + free
+	if total == 0 {
+		return
+	}
+	o.Log("[FILL]: [%v]: %v occupied cells (%.1f%%), %v free cells\n", o.Turn, occupied, 100*float64(occupied)/float64(total), free)
+}
+
+// boardFill counts the cells covered by snakes that haven't been eliminated, and the cells that aren't.
+func boardFill(state *rules.BoardState) (int, int) {
+	covered := make(map[rules.Point]bool)
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		for _, p := range snake.Body {
+			if p.X >= 0 && p.Y >= 0 && p.X < state.Width && p.Y < state.Height {
+				covered[p] = true
+			}
+		}
+	}
+	return len(covered), int(state.Width*state.Height) - len(covered)
+}
+
+// logAccessibleArea logs how many free cells each snake can still reach from its head.
+func logAccessibleArea(o *Options, state *rules.BoardState) {
+	for _, snake := range aliveSnakes(state) {
+		if len(snake.Body) == 0 {
+			continue
+		}
+		var area int
+		if isWrapped(o.GameType) {
+			area = rules.WrappedAccessibleArea(state, snake.Body[0])
+		} else {
+			area = rules.AccessibleArea(state, snake.Body[0])
+		}
+		o.Log("[AREA]: [%v]: %v can reach %v free cells\n", o.Turn, displayName(o.Battlesnakes[snake.ID]), area)
+	}
+}
+
+func logLowHealth(o *Options, state *rules.BoardState) {
+	if o.HealthWarn <= 0 {
+		return
+	}
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated && snake.Health < o.HealthWarn {
+			o.Log("[WARN]: [%v]: %v health is low: %v\n", o.Turn, displayName(o.Battlesnakes[snake.ID]), snake.Health)
+		}
+	}
+}
+
+// HealthEvent is a snake's health dropping below --min-health-warn.
+type HealthEvent struct {
+	Turn      int32  `json:"turn"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Health    int32  `json:"health"`
+	Threshold int32  `json:"threshold"`
+}
+
+// logHealthCrossings logs and returns a health event for each snake whose health dropped below
+// --min-health-warn this turn. A snake only has another event once its health recovers to the
+// threshold, unlike --health-warn which logs every turn the health is low.
+func logHealthCrossings(o *Options, state *rules.BoardState) []HealthEvent {
+	if o.MinHealthWarn <= 0 {
+		return nil
+	}
+	var events []HealthEvent
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		if snake.Health >= o.MinHealthWarn {
+			delete(o.belowHealth, snake.ID)
+			continue
+		}
+		if o.belowHealth[snake.ID] {
+			continue
+		}
+		o.belowHealth[snake.ID] = true
+		event := HealthEvent{
+			Turn:      o.Turn,
+			ID:        snake.ID,
+			Name:      o.Battlesnakes[snake.ID].Name,
+			Health:    snake.Health,
+			Threshold: o.MinHealthWarn,
+		}
+		events = append(events, event)
+		if entry, err := json.Marshal(event); err != nil {
+			o.Log("[WARN]: Unable to log health event: %v", err)
+		} else {
+			o.Log("[HEALTH]: %s", entry)
+		}
+	}
+	return events
+}
+
+// restoreEliminations returns the turns the snakes already eliminated in state were eliminated
+// on, and their elimination events. Turns missing from turns default to the current turn.
+func restoreEliminations(o *Options, state *rules.BoardState, turns map[string]int32) (map[string]int32, []EliminationEvent) {
+	eliminatedTurns := make(map[string]int32)
+	var eliminations []EliminationEvent
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			continue
+		}
+		turn, ok := turns[snake.ID]
+		if !ok {
+			turn = o.Turn
+		}
+		eliminatedTurns[snake.ID] = turn
+		eliminations = append(eliminations, EliminationEvent{
+			Turn:    turn,
+			SnakeID: snake.ID,
+			Cause:   snake.EliminatedCause,
+			By:      snake.EliminatedBy,
+		})
+	}
+	sort.SliceStable(eliminations, func(i, j int) bool { return eliminations[i].Turn < eliminations[j].Turn })
+	return eliminatedTurns, eliminations
+}
+
+func buildSnakeResults(o *Options, state *rules.BoardState, eliminatedTurns map[string]int32, foodEaten map[string]int) []SnakeResult {
+	var a []SnakeResult
+	for _, snake := range state.Snakes {
+		a = append(a, SnakeResult{
+			Name:            o.Battlesnakes[snake.ID].Name,
+			Health:          snake.Health,
+			Length:          int32(len(snake.Body)),
+			EliminatedCause: snake.EliminatedCause,
+			EliminatedTurn:  eliminatedTurns[snake.ID],
+			Version:         o.Battlesnakes[snake.ID].Version,
+			FoodEaten:       foodEaten[snake.ID],
+		})
+	}
+	return a
+}
+
+// countFoodEaten adds the food each snake ate moving from prev to next: a snake eats the food
+// its head moves onto, even if it is eliminated in the same turn.
+func countFoodEaten(prev, next *rules.BoardState, foodEaten map[string]int) {
+	food := make(map[rules.Point]bool, len(prev.Food))
+	for _, f := range prev.Food {
+		food[f] = true
+	}
+	alive := make(map[string]bool, len(prev.Snakes))
+	for _, snake := range prev.Snakes {
+		alive[snake.ID] = snake.EliminatedCause == rules.NotEliminated
+	}
+	for _, snake := range next.Snakes {
+		if alive[snake.ID] && len(snake.Body) > 0 && food[snake.Body[0]] {
+			foodEaten[snake.ID]++
+		}
+	}
+}
+
+// logBoardChecksum logs the first 8 hex digits of the hash of the board and its hazards. Snake
+// IDs are random for each run, so snakes are hashed by name to make runs comparable.
+func logBoardChecksum(o *Options, state *rules.BoardState, hazards []rules.Point) {
+	named := *state
+	named.Snakes = make([]rules.Snake, len(state.Snakes))
+	for i, snake := range state.Snakes {
+		snake.ID = o.Battlesnakes[snake.ID].Name
+		if snake.EliminatedBy != "" {
+			snake.EliminatedBy = o.Battlesnakes[snake.EliminatedBy].Name
+		}
+		named.Snakes[i] = snake
+	}
+	o.Log("[CHECKSUM]: [%v]: %v", o.Turn, rules.HashState(&named, hazards)[:8])
+}
+
+// isGhost reports whether an eliminated snake is drawn faded, for --ghost-turns turns
+// counting the turn it was eliminated on.
+func isGhost(o *Options, snake rules.Snake) bool {
+	if snake.EliminatedCause == rules.NotEliminated {
+		return false
+	}
+	turn, ok := o.eliminatedTurns[snake.ID]
+	if !ok {
+		// Eliminated on the current turn, which hasn't been recorded yet.
+		turn = o.Turn
+	}
+	return o.Turn-turn < o.GhostTurns
+}
+
+// aliveSnakes returns the snakes that haven't been eliminated.
+func aliveSnakes(state *rules.BoardState) []rules.Snake {
+	var alive []rules.Snake
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			alive = append(alive, snake)
+		}
+	}
+	return alive
+}
+
+func eliminationSummary(cause string) string {
+	if cause == rules.NotEliminated {
+		return "alive"
+	}
+	return eliminationCauseString(cause)
+}
+
+// eliminationCauseString describes an elimination cause in words, for logs and reports.
+// Unknown causes from custom rulesets are returned as is.
+func eliminationCauseString(cause string) string {
+	switch cause {
+	case rules.NotEliminated:
+		return "not eliminated"
+	case rules.EliminatedByCollision:
+		return "collided with another snake"
+	case rules.EliminatedBySelfCollision:
+		return "collided with itself"
+	case rules.EliminatedByOutOfHealth:
+		return "ran out of health"
+	case rules.EliminatedByHeadToHeadCollision:
+		return "lost a head-to-head collision"
+	case rules.EliminatedByOutOfBounds:
+		return "moved out of bounds"
+	case rules.EliminatedBySquad:
+		return "eliminated with its squad"
+	case rules.EliminatedBySelfTrapped:
+		return "trapped itself"
+	default:
+		return cause
+	}
+}
+
+// standardRuleset returns the standard rules that every game type builds on.
+func standardRuleset(o *Options) rules.StandardRuleset {
+	minimumFood := int32(1)
+	if o.AutoScale {
+		minimumFood, _ = autoScale(o.Width, o.Height, minimumFood, royaleShrinkEveryNTurns)
+	}
+
+	standard := rules.StandardRuleset{
+		FoodSpawnChance: 15,
+		MinimumFood:     minimumFood,
+		Rand:            o.rand,
+	}
+
+	// The food schedule replaces the minimum food, so that it can also decrease.
+	if schedule, _ := parseFoodSchedule(o.FoodSchedule); len(schedule) > 0 {
+		standard.MinimumFood = 0
+	}
+	return standard
+}
+
+func getRuleset(o *Options, snakes []Battlesnake) (rules.Ruleset, rules.RoyaleRuleset) {
+	var royale rules.RoyaleRuleset
+
+	standard := standardRuleset(o)
+	schedule, _ := parseFoodSchedule(o.FoodSchedule)
+
+	ctor, ok := rulesetRegistry[o.GameType]
+	if !ok {
+		ctor = rulesetRegistry["standard"]
+	}
+	ruleset := ctor(o, standard, snakes)
+	switch r := ruleset.(type) {
+	case *rules.RoyaleRuleset:
+		royale = *r
+	case *rules.WrappedRoyaleRuleset:
+		royale = r.RoyaleRuleset
+	}
+
+	// The wrappers treat hazard walls as blocked, like the walls ruleset itself.
+	var blocked []rules.Point
+	if o.GameType == "walls" {
+		blocked = o.Walls
+	}
+
+	if o.AllowBodyCollisions {
+		ruleset = &rules.AllowBodyCollisionsRuleset{Ruleset: ruleset}
+	}
+	if o.EliminateTrapped {
+		ruleset = &rules.SelfTrappedRuleset{
+			Ruleset: ruleset,
+			Wrapped: isWrapped(o.GameType),
+			Blocked: blocked,
+		}
+	}
+	if o.HealthDecay > 1 {
+		ruleset = &rules.HealthDecayRuleset{
+			Ruleset:     ruleset,
+			HealthDecay: o.HealthDecay,
+		}
+	}
+	if o.FoodPerSpawn > 0 {
+		ruleset = &rules.FoodSpawnRuleset{
+			Ruleset:         ruleset,
+			FoodPerSpawn:    o.FoodPerSpawn,
+			FoodSpawnChance: o.FoodChance,
+			Blocked:         blocked,
+			Rand:            o.rand,
+		}
+	}
+	if len(schedule) > 0 {
+		ruleset = &rules.FoodScheduleRuleset{
+			Ruleset:  ruleset,
+			Turn:     o.Turn,
+			Schedule: schedule,
+			Blocked:  blocked,
+			Rand:     o.rand,
 		}
 	}
-
-	return res
+	if o.MaxFood > 0 {
+		ruleset = &rules.MaxFoodRuleset{
+			Ruleset: ruleset,
+			MaxFood: o.MaxFood,
+		}
+	}
+	// Hazard patterns are applied last so their hazards can be read back after each turn.
+	if o.HazardPattern == "spiral" {
+		ruleset = &rules.SpiralHazardsRuleset{
+			Ruleset:         ruleset,
+			Turn:            o.Turn,
+			GrowEveryNTurns: o.HazardGrowth,
+			DamagePerTurn:   15,
+		}
+	}
+	return ruleset, royale
 }
 
-func getRuleset(o *Options, snakes []Battlesnake) (rules.Ruleset, rules.RoyaleRuleset) {
-	var ruleset rules.Ruleset
-	var royale rules.RoyaleRuleset
-
-	standard := rules.StandardRuleset{
-		FoodSpawnChance: 15,
-		MinimumFood:     1,
+// parseFoodSchedule parses turn:food pairs separated by commas, which must be sorted by turn.
+func parseFoodSchedule(s string) ([]rules.FoodSchedulePoint, error) {
+	var schedule []rules.FoodSchedulePoint
+	if s == "" {
+		return schedule, nil
 	}
-
-	switch o.GameType {
-	case "royale":
-		royale = rules.RoyaleRuleset{
-			StandardRuleset:   standard,
-			Seed:              o.Seed,
-			Turn:              o.Turn,
-			ShrinkEveryNTurns: 20,
-			DamagePerTurn:     15,
-		}
-		ruleset = &royale
-	case "squad":
-		squadMap := map[string]string{}
-		for _, snake := range snakes {
-			squadMap[snake.ID] = snake.Squad
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not a turn:food pair", pair)
 		}
-		ruleset = &rules.SquadRuleset{
-			StandardRuleset:     standard,
-			SquadMap:            squadMap,
-			AllowBodyCollisions: true,
-			SharedElimination:   true,
-			SharedHealth:        true,
-			SharedLength:        true,
+		turn, err := strconv.ParseInt(parts[0], 10, 32)
+		if err != nil || turn < 0 {
+			return nil, fmt.Errorf("invalid turn in %q", pair)
 		}
-	case "solo":
-		ruleset = &rules.SoloRuleset{
-			StandardRuleset: standard,
+		food, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil || food < 0 {
+			return nil, fmt.Errorf("invalid food in %q", pair)
 		}
-	case "constrictor":
-		ruleset = &rules.ConstrictorRuleset{
-			StandardRuleset: standard,
+		if n := len(schedule); n > 0 && int32(turn) <= schedule[n-1].Turn {
+			return nil, fmt.Errorf("turn %v is not after turn %v", turn, schedule[n-1].Turn)
 		}
-	default:
-		ruleset = &standard
+		schedule = append(schedule, rules.FoodSchedulePoint{Turn: int32(turn), MinimumFood: int32(food)})
 	}
-	return ruleset, royale
+	return schedule, nil
+}
+
+// autoScale scales the minimum food and the shrink cadence proportionally to the
+// board area, relative to the defaults used on a medium board.
+func autoScale(width, height int32, minimumFood, shrinkEveryNTurns int32) (int32, int32) {
+	factor := float64(width*height) / float64(rules.BoardSizeMedium*rules.BoardSizeMedium)
+	if factor <= 0 {
+		return minimumFood, shrinkEveryNTurns
+	}
+
+	scaledFood := int32(math.Round(float64(minimumFood) * factor))
+	if scaledFood < 1 {
+		scaledFood = 1
+	}
+	scaledShrink := int32(math.Round(float64(shrinkEveryNTurns) / factor))
+	if scaledShrink < 1 {
+		scaledShrink = 1
+	}
+	return scaledFood, scaledShrink
 }
 
 func getSnakeInfos(o *Options, snakes []Battlesnake) map[string]InfoResponse {
 	res := make(map[string]InfoResponse)
+	if o.NoPing {
+		return res
+	}
 	for _, snake := range snakes {
+		if snake.Provider != nil {
+			continue
+		}
 		u, _ := url.ParseRequestURI(snake.URL)
 		resp, err := o.HttpClient.Get(u.String())
 		if err != nil {
@@ -277,48 +1396,122 @@ func getSnakeInfos(o *Options, snakes []Battlesnake) map[string]InfoResponse {
 	return res
 }
 
-func initializeBoardFromArgs(o *Options, ruleset rules.Ruleset, snakes []Battlesnake) *rules.BoardState {
-	if o.Timeout == 0 {
-		o.Timeout = 500
-	}
-	o.HttpClient = http.Client{
-		Timeout: time.Duration(o.Timeout) * time.Millisecond,
+func initializeBoardFromArgs(o *Options, ruleset rules.Ruleset, snakes []Battlesnake, state *rules.BoardState) *rules.BoardState {
+	if state != nil {
+		o.Width, o.Height = state.Width, state.Height
+	} else {
+		snakeIds := []string{}
+		for _, snake := range snakes {
+			snakeIds = append(snakeIds, snake.ID)
+		}
+		if o.ShufflePlacement {
+			snakeIds = shufflePlacement(snakeIds, o.Seed)
+		}
+		var err error
+		state, err = ruleset.CreateInitialBoardState(o.Width, o.Height, snakeIds)
+		if err != nil {
+			log.Panic("[PANIC]: Error Initializing Board State")
+			panic(err)
+		}
+		if o.Placement != "" {
+			if err := rules.RepositionSnakes(state, o.Placement, o.rand); err != nil {
+				log.Panicf("[PANIC]: Error Placing Snakes: %v", err)
+			}
+		}
+		if o.RandomHeadings {
+			rules.RandomizeHeadings(state, o.rand)
+		}
 	}
+	return state
+}
 
-	snakeIds := []string{}
+// sendStartRequests sends each snake served over HTTP a /start of the initial board.
+func sendStartRequests(o *Options, state *rules.BoardState, snakes []Battlesnake) {
 	for _, snake := range snakes {
-		snakeIds = append(snakeIds, snake.ID)
-	}
-	state, err := ruleset.CreateInitialBoardState(o.Width, o.Height, snakeIds)
-	if err != nil {
-		log.Panic("[PANIC]: Error Initializing Board State")
-		panic(err)
+		if snake.Provider != nil {
+			continue
+		}
+		requestBody := getIndividualBoardStateForSnake(o, state, snake, nil)
+		u := snakeActionURL(snake, "start")
+		_, err := o.HttpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
+		if err != nil {
+			o.Log("[WARN]: Request to %v failed", u.String())
+		}
 	}
+}
+
+// prewarmSnakes sends each snake a throwaway /move of the initial board, discarding the response,
+// so that cold starts don't count against the first turn's latency.
+func prewarmSnakes(o *Options, state *rules.BoardState, snakes []Battlesnake) {
 	for _, snake := range snakes {
+		if snake.Provider != nil {
+			continue
+		}
 		requestBody := getIndividualBoardStateForSnake(o, state, snake, nil)
-		u, _ := url.ParseRequestURI(snake.URL)
-		u.Path = path.Join(u.Path, "start")
-		_, err = o.HttpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
+		u := snakeActionURL(snake, "move")
+		res, err := o.HttpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
 		if err != nil {
 			o.Log("[WARN]: Request to %v failed", u.String())
+			continue
 		}
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
 	}
-	return state
 }
 
-func createNextBoardState(o *Options, ruleset rules.Ruleset, royale rules.RoyaleRuleset, state *rules.BoardState, outOfBounds []rules.Point, snakes []Battlesnake) (*rules.BoardState, []rules.Point) {
-	var moves []rules.SnakeMove
+// createNextBoardState collects the moves of the snakes and applies them. With --fail-on-timeout,
+// the moves aren't applied if any snake fails to move, and a *ConformanceError is returned.
+func createNextBoardState(o *Options, ruleset rules.Ruleset, royale rules.RoyaleRuleset, state *rules.BoardState, outOfBounds []rules.Point, snakes []Battlesnake) (*rules.BoardState, []rules.Point, error) {
+	// With --strict-timeout, the timeout is a budget for all the moves of the turn.
+	ctx := context.Background()
+	if o.StrictTimeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(o.Timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	delays := drawJitter(o, snakes)
+	var results []moveResult
 	if o.Sequential {
 		for _, snake := range snakes {
-			moves = append(moves, getMoveForSnake(o, state, snake, outOfBounds))
+			results = append(results, getMoveForSnake(ctx, o, state, snake, outOfBounds, delays[snake.ID]))
 		}
 	} else {
-		c := make(chan rules.SnakeMove, len(snakes))
+		c := make(chan moveResult, len(snakes))
 		for _, snake := range snakes {
-			go getConcurrentMoveForSnake(o, state, snake, outOfBounds, c)
+			go getConcurrentMoveForSnake(ctx, o, state, snake, outOfBounds, delays[snake.ID], c)
 		}
 		for range snakes {
-			moves = append(moves, <-c)
+			results = append(results, <-c)
+		}
+	}
+	var moves []rules.SnakeMove
+	for _, result := range results {
+		moves = append(moves, result.SnakeMove)
+	}
+	results = orderMoveResults(snakes, results)
+	if o.FailOnTimeout {
+		if err := checkConformance(o, state, results); err != nil {
+			return state, outOfBounds, err
+		}
+	}
+	if o.WarnNeckMoves {
+		warnNeckMoves(o, state, results)
+	}
+	if o.LogMoves {
+		logMoves(o, results)
+	}
+	if o.movesCSV != nil {
+		writeMovesCSV(o, results)
+	}
+	if o.movesOnly != nil {
+		writeMovesOnlyTurn(o, moves)
+	}
+	for _, result := range results {
+		o.latencies[result.ID] = append(o.latencies[result.ID], result.Latency)
+		o.shouts[result.ID] = result.Shout
+		if isTimeout(result.Err) {
+			moveTimeouts.Add(1)
 		}
 	}
 	for _, move := range moves {
@@ -326,7 +1519,13 @@ func createNextBoardState(o *Options, ruleset rules.Ruleset, royale rules.Royale
 		snake.LastMove = move.Move
 		o.Battlesnakes[move.ID] = snake
 	}
-	if o.GameType == "royale" {
+	state, outOfBounds = applyMoves(o, ruleset, royale, state, moves)
+	return state, outOfBounds, nil
+}
+
+// applyMoves applies the moves of a turn to the state, returning the next state and its hazards.
+func applyMoves(o *Options, ruleset rules.Ruleset, royale rules.RoyaleRuleset, state *rules.BoardState, moves []rules.SnakeMove) (*rules.BoardState, []rules.Point) {
+	if o.GameType == "royale" || o.GameType == "wrapped-royale" {
 		_, err := royale.CreateNextBoardState(state, moves)
 		if err != nil {
 			log.Panic("[PANIC]: Error Producing Next Royale Board State")
@@ -338,22 +1537,133 @@ func createNextBoardState(o *Options, ruleset rules.Ruleset, royale rules.Royale
 		log.Panic("[PANIC]: Error Producing Next Board State")
 		panic(err)
 	}
-	return state, royale.OutOfBounds
+	outOfBounds := royale.OutOfBounds
+	if spiral, ok := ruleset.(*rules.SpiralHazardsRuleset); ok {
+		outOfBounds = append(append([]rules.Point{}, outOfBounds...), spiral.Hazards...)
+	}
+	if o.GameType == "walls" {
+		outOfBounds = append(append([]rules.Point{}, outOfBounds...), o.Walls...)
+	}
+	return state, outOfBounds
+}
+
+// moveResult is the move used for a snake, and whether it fell back to the snake's last move.
+type moveResult struct {
+	rules.SnakeMove
+	Fallback bool
+	Latency  time.Duration
+	// Shout is what the snake shouted with its move, if it wasn't a fallback.
+	Shout string
+	// Err is why the snake's response couldn't be used, if it fell back.
+	Err error
+}
+
+// warnNeckMoves warns about moves back into a snake's own neck, which are always fatal
+// and usually a bug in the snake. The moves are still applied.
+func warnNeckMoves(o *Options, state *rules.BoardState, results []moveResult) {
+	for _, result := range results {
+		for _, snake := range state.Snakes {
+			if snake.ID != result.ID || snake.EliminatedCause != rules.NotEliminated || len(snake.Body) < 2 {
+				continue
+			}
+			if snake.Body[0] != snake.Body[1] && nextPoint(snake.Body[0], result.Move) == snake.Body[1] {
+				o.Log("[FORFEIT]: [%v]: %v moved %v back into its own neck\n", o.Turn, displayName(o.Battlesnakes[snake.ID]), result.Move)
+			}
+		}
+	}
+}
+
+// orderMoveResults orders results by setup order, regardless of the order responses arrived in.
+func orderMoveResults(snakes []Battlesnake, results []moveResult) []moveResult {
+	byID := make(map[string]moveResult)
+	for _, result := range results {
+		byID[result.ID] = result
+	}
+	var ordered []moveResult
+	for _, snake := range snakes {
+		if result, ok := byID[snake.ID]; ok {
+			ordered = append(ordered, result)
+		}
+	}
+	return ordered
+}
+
+func writeMovesCSV(o *Options, results []moveResult) {
+	for _, result := range results {
+		err := o.movesCSV.Write([]string{
+			strconv.Itoa(int(o.Turn)),
+			o.Battlesnakes[result.ID].Name,
+			result.Move,
+			strconv.FormatInt(result.Latency.Milliseconds(), 10),
+			strconv.FormatBool(result.Fallback),
+		})
+		if err != nil {
+			o.Log("[WARN]: Unable to write moves CSV: %v", err)
+			return
+		}
+	}
 }
 
-func getConcurrentMoveForSnake(o *Options, state *rules.BoardState, snake Battlesnake, outOfBounds []rules.Point, c chan rules.SnakeMove) {
-	c <- getMoveForSnake(o, state, snake, outOfBounds)
+// MoveLog is logged for each snake each turn with --log-moves.
+type MoveLog struct {
+	Turn     int32  `json:"turn"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Move     string `json:"move"`
+	Fallback bool   `json:"fallback"`
 }
 
-func getMoveForSnake(o *Options, state *rules.BoardState, snake Battlesnake, outOfBounds []rules.Point) rules.SnakeMove {
+func logMoves(o *Options, results []moveResult) {
+	for _, result := range results {
+		entry, err := json.Marshal(MoveLog{
+			Turn:     o.Turn,
+			ID:       result.ID,
+			Name:     o.Battlesnakes[result.ID].Name,
+			Move:     result.Move,
+			Fallback: result.Fallback,
+		})
+		if err != nil {
+			o.Log("[WARN]: Unable to log move: %v", err)
+			continue
+		}
+		o.Log("[MOVE]: %s", entry)
+	}
+}
+
+func getConcurrentMoveForSnake(ctx context.Context, o *Options, state *rules.BoardState, snake Battlesnake, outOfBounds []rules.Point, delay time.Duration, c chan moveResult) {
+	c <- getMoveForSnake(ctx, o, state, snake, outOfBounds, delay)
+}
+
+func getMoveForSnake(ctx context.Context, o *Options, state *rules.BoardState, snake Battlesnake, outOfBounds []rules.Point, delay time.Duration) moveResult {
+	start := time.Now()
+	if snake.Provider != nil {
+		move := snake.Provider.Move(BuildPayloadForSnake(state, snake.ID, o, outOfBounds))
+		if err := waitJitter(ctx, o, snake, delay, start); err != nil {
+			return moveResult{SnakeMove: rules.SnakeMove{ID: snake.ID, Move: o.Battlesnakes[snake.ID].LastMove}, Fallback: true, Latency: time.Since(start), Err: err}
+		}
+		return moveResult{SnakeMove: rules.SnakeMove{ID: snake.ID, Move: move}, Latency: time.Since(start)}
+	}
 	requestBody := getIndividualBoardStateForSnake(o, state, snake, outOfBounds)
-	u, _ := url.ParseRequestURI(snake.URL)
-	u.Path = path.Join(u.Path, "move")
-	res, err := o.HttpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
-	move := snake.LastMove
+	if o.EchoRequest {
+		logRequest(o, snake, requestBody)
+	}
+	u := snakeActionURL(snake, "move")
+	moveRequests.Add(1)
+	res, err := postMove(ctx, o, snake, u.String(), requestBody, start)
+	if err == nil {
+		if jitterErr := waitJitter(ctx, o, snake, delay, start); jitterErr != nil {
+			res.Body.Close()
+			err = jitterErr
+		}
+	}
+	move := o.Battlesnakes[snake.ID].LastMove
+	fallback := true
+	var shout string
+	var moveErr error
 	if err != nil {
 		o.Log("[WARN]: Request to %v failed\n", u.String())
 		o.Log("Body --> %v\n", string(requestBody))
+		moveErr = err
 	} else if res.Body != nil {
 		defer res.Body.Close()
 		body, readErr := ioutil.ReadAll(res.Body)
@@ -361,21 +1671,133 @@ func getMoveForSnake(o *Options, state *rules.BoardState, snake Battlesnake, out
 			log.Fatal(readErr)
 		} else {
 			playerResponse := PlayerResponse{}
-			jsonErr := json.Unmarshal(body, &playerResponse)
-			if jsonErr != nil {
+			var jsonErr error
+			if o.Strict {
+				jsonErr = decodeStrict(body, &playerResponse)
+			} else {
+				jsonErr = json.Unmarshal(body, &playerResponse)
+			}
+			if jsonErr != nil && o.Strict {
+				o.Log("[WARN]: [%v]: %v sent an invalid move response: %v\n", o.Turn, displayName(snake), jsonErr)
+				moveErr = jsonErr
+			} else if jsonErr != nil && o.FailOnTimeout {
+				moveErr = jsonErr
+			} else if jsonErr != nil {
 				log.Fatal(jsonErr)
+			} else if playerResponse.Move == "" {
+				// Valid JSON without a move is treated like no response at all.
+				o.Log("[WARN]: [%v]: %v responded without a move, using its last move %q\n", o.Turn, displayName(snake), move)
+				moveErr = fmt.Errorf("response has no move")
 			} else {
 				move = playerResponse.Move
+				shout = playerResponse.Shout
+				fallback = false
+				if o.CheckIDs && playerResponse.Id != "" && playerResponse.Id != snake.ID {
+					o.Log("[WARN]: [%v]: %v responded with id %v but its id is %v\n", o.Turn, displayName(snake), playerResponse.Id, snake.ID)
+				}
 			}
 		}
 	}
-	return rules.SnakeMove{ID: snake.ID, Move: move}
+	return moveResult{SnakeMove: rules.SnakeMove{ID: snake.ID, Move: move}, Fallback: fallback, Latency: time.Since(start), Shout: shout, Err: moveErr}
+}
+
+// postMove sends a move request, which is cancelled when ctx is done. With --retry-rate-limited,
+// 429 responses are retried after their Retry-After, as long as the wait leaves time for the
+// retry before the timeout.
+func postMove(ctx context.Context, o *Options, snake Battlesnake, u string, body []byte, start time.Time) (*http.Response, error) {
+	client := o.HttpClient
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewBuffer(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		if err != nil || !o.RetryRateLimited || res.StatusCode != http.StatusTooManyRequests {
+			return res, err
+		}
+		res.Body.Close()
+		wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+		remaining := time.Duration(o.Timeout)*time.Millisecond - time.Since(start)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < remaining {
+			remaining = time.Until(deadline)
+		}
+		if !ok || wait >= remaining {
+			return nil, fmt.Errorf("rate limited with %v left in the turn", remaining)
+		}
+		o.Log("[WARN]: [%v]: %v is rate limited, retrying in %v\n", o.Turn, displayName(snake), wait)
+		time.Sleep(wait)
+
+		// The retry only gets what is left of the timeout.
+		retryClient := *o.HttpClient
+		retryClient.Timeout = remaining - wait
+		client = &retryClient
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, which is either seconds or an HTTP date.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if wait := t.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// decodeStrict decodes JSON, failing on fields that v doesn't have, such as misspelt fields.
+func decodeStrict(body []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func logRequest(o *Options, snake Battlesnake, requestBody []byte) {
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, requestBody, "", "  "); err != nil {
+		pretty.Reset()
+		pretty.Write(requestBody)
+	}
+	o.Log("[REQUEST]: [%v]: %v /move\n%s\n", o.Turn, displayName(snake), pretty.String())
+}
+
+// parsePathTemplates parses the name=template pairs of --path-template into templates by snake name.
+func parsePathTemplates(pairs []string) (map[string]string, error) {
+	templates := make(map[string]string)
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("expected name=template, got %q", pair)
+		}
+		if !strings.Contains(kv[1], "{action}") {
+			return nil, fmt.Errorf("template %q has no {action}", kv[1])
+		}
+		templates[kv[0]] = kv[1]
+	}
+	return templates, nil
+}
+
+// snakeActionURL returns the URL of an action of a snake, which is start, move or end.
+func snakeActionURL(snake Battlesnake, action string) *url.URL {
+	u, _ := url.ParseRequestURI(snake.URL)
+	if snake.PathTemplate != "" {
+		u.Path = path.Join(u.Path, strings.ReplaceAll(snake.PathTemplate, "{action}", action))
+	} else {
+		u.Path = path.Join(u.Path, action)
+	}
+	return u
 }
 
 func sendEndRequest(o *Options, state *rules.BoardState, snake Battlesnake) {
+	if snake.Provider != nil {
+		return
+	}
 	requestBody := getIndividualBoardStateForSnake(o, state, snake, nil)
-	u, _ := url.ParseRequestURI(snake.URL)
-	u.Path = path.Join(u.Path, "end")
+	u := snakeActionURL(snake, "end")
 	_, err := o.HttpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
 	if err != nil {
 		o.Log("[WARN]: Request to %v failed", u.String())
@@ -383,41 +1805,103 @@ func sendEndRequest(o *Options, state *rules.BoardState, snake Battlesnake) {
 }
 
 func getIndividualBoardStateForSnake(o *Options, state *rules.BoardState, snake Battlesnake, outOfBounds []rules.Point) []byte {
-	var youSnake rules.Snake
-	for _, snk := range state.Snakes {
-		if snake.ID == snk.ID {
+	responseJson, err := marshalPayloadForSnake(o, state, snake.ID, outOfBounds)
+	if err != nil {
+		log.Panic("[PANIC]: Error Marshalling JSON from State")
+		panic(err)
+	}
+	return responseJson
+}
+
+// marshalPayloadForSnake serializes the payload sent to the snake with ID you in o.PayloadVersion.
+func marshalPayloadForSnake(o *Options, state *rules.BoardState, you string, hazards []rules.Point) ([]byte, error) {
+	return marshalPayload(BuildPayloadForSnake(state, you, o, hazards), o.PayloadVersion)
+}
+
+// ToAPIPayload returns the JSON payload sent to the snake with ID youID, serialized exactly as
+// the CLI serializes it, for other tools to reuse. Snake names and squads are looked up in
+// snakes by ID, and hazards are the hazards of the turn.
+func ToAPIPayload(state *rules.BoardState, youID string, game GameResponse, turn int32, snakes []Battlesnake, hazards []rules.Point) ([]byte, error) {
+	o := &Options{GameId: game.Id, Timeout: game.Timeout, Turn: turn, Battlesnakes: make(map[string]Battlesnake, len(snakes))}
+	for _, snake := range snakes {
+		o.Battlesnakes[snake.ID] = snake
+	}
+	return marshalPayloadForSnake(o, state, youID, hazards)
+}
+
+// BuildPayloadForSnake returns the payload sent to the snake with ID you for the given state,
+// which snake authors can use to test their move logic against specific board positions.
+// Snake names and squads are looked up in o.Battlesnakes.
+func BuildPayloadForSnake(state *rules.BoardState, you string, o *Options, hazards []rules.Point) ResponsePayload {
+	snakes := buildSnakesResponse(o, state.Snakes)
+	if o.FoodDistance {
+		for i := range snakes {
+			snakes[i].CLI = &SnakeExtensions{NearestFoodDistance: nearestFoodDistance(snakes[i].Head, state.Food)}
+		}
+	}
+	if o.EliminationInfo {
+		for i, snake := range state.Snakes {
+			if snake.EliminatedCause == rules.NotEliminated {
+				continue
+			}
+			if snakes[i].CLI == nil {
+				snakes[i].CLI = &SnakeExtensions{}
+			}
+			snakes[i].CLI.EliminatedCause = snake.EliminatedCause
+			snakes[i].CLI.EliminatedBy = snake.EliminatedBy
+		}
+	}
+
+	// You is copied from the board so the two never disagree.
+	youSnake := snakeResponseFromSnake(o, rules.Snake{})
+	for _, snk := range snakes {
+		if you == snk.Id {
 			youSnake = snk
+			youSnake.Body = append([]Coord{}, snk.Body...)
+			if snk.CLI != nil {
+				ext := *snk.CLI
+				youSnake.CLI = &ext
+			}
 			break
 		}
 	}
-	response := ResponsePayload{
+	return ResponsePayload{
 		Game: GameResponse{Id: o.GameId, Timeout: o.Timeout},
 		Turn: o.Turn,
 		Board: BoardResponse{
 			Height:  state.Height,
 			Width:   state.Width,
 			Food:    coordFromPointArray(state.Food),
-			Hazards: coordFromPointArray(outOfBounds),
-			Snakes:  buildSnakesResponse(o, state.Snakes),
+			Hazards: coordFromPointArray(hazards),
+			Snakes:  snakes,
 		},
-		You: snakeResponseFromSnake(o, youSnake),
+		You: youSnake,
 	}
-	responseJson, err := json.Marshal(response)
-	if err != nil {
-		log.Panic("[PANIC]: Error Marshalling JSON from State")
-		panic(err)
+}
+
+func nearestFoodDistance(head Coord, food []rules.Point) int32 {
+	nearest := int32(-1)
+	for _, f := range food {
+		d := abs(f.X-head.X) + abs(f.Y-head.Y)
+		if nearest < 0 || d < nearest {
+			nearest = d
+		}
 	}
-	return responseJson
+	return nearest
 }
 
 func snakeResponseFromSnake(o *Options, snake rules.Snake) SnakeResponse {
+	var head Coord
+	if len(snake.Body) > 0 {
+		head = coordFromPoint(snake.Body[0])
+	}
 	return SnakeResponse{
 		Id:      snake.ID,
 		Name:    o.Battlesnakes[snake.ID].Name,
 		Health:  snake.Health,
 		Body:    coordFromPointArray(snake.Body),
 		Latency: "0",
-		Head:    coordFromPoint(snake.Body[0]),
+		Head:    head,
 		Length:  int32(len(snake.Body)),
 		Shout:   "",
 		Squad:   o.Battlesnakes[snake.ID].Squad,
@@ -425,7 +1909,7 @@ func snakeResponseFromSnake(o *Options, snake rules.Snake) SnakeResponse {
 }
 
 func buildSnakesResponse(o *Options, snakes []rules.Snake) []SnakeResponse {
-	var a []SnakeResponse
+	a := make([]SnakeResponse, 0, len(snakes))
 	for _, snake := range snakes {
 		a = append(a, snakeResponseFromSnake(o, snake))
 	}
@@ -437,20 +1921,76 @@ func coordFromPoint(pt rules.Point) Coord {
 }
 
 func coordFromPointArray(ptArray []rules.Point) []Coord {
-	a := make([]Coord, 0)
+	a := make([]Coord, 0, len(ptArray))
 	for _, pt := range ptArray {
 		a = append(a, coordFromPoint(pt))
 	}
 	return a
 }
 
+const (
+	emptyChar = '◦'
+	// ghostChar marks the bodies of recently eliminated snakes with --ghost-turns.
+	ghostChar = '·'
+)
+
+var bodyChars = []rune{'■', '⌀', '●', '⍟', '◘', '☺', '□', '☻'}
+
+// stableChar picks the body character of a snake by the hash of its name, so that a snake is
+// drawn the same regardless of the order snakes are given in. Snakes may share a character.
+func stableChar(name string) rune {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return bodyChars[h.Sum32()%uint32(len(bodyChars))]
+}
+
+// headChars are the head glyphs of the snakes with the body characters at the same index.
+var headChars = []rune{'▣', '⊘', '◉', '✪', '◙', '☹', '▢', '◕'}
+
+// headCollisionChar marks a cell where the heads of more than one snake collided.
+const headCollisionChar = '✖'
+
+func headChar(body rune) rune {
+	for i, c := range bodyChars {
+		if c == body {
+			return headChars[i]
+		}
+	}
+	return body
+}
+
+// drawHeads draws the heads over the bodies, so they win any overlap with another snake's body.
+func drawHeads(o *Options, board [][]rune, state *rules.BoardState) {
+	heads := make(map[rules.Point]int)
+	for _, s := range state.Snakes {
+		if len(s.Body) > 0 {
+			heads[s.Body[0]]++
+		}
+	}
+	for _, s := range state.Snakes {
+		if len(s.Body) == 0 {
+			continue
+		}
+		head := s.Body[0]
+		if head.X < 0 || head.Y < 0 || head.X >= state.Width || head.Y >= state.Height {
+			continue
+		}
+		if heads[head] > 1 {
+			board[head.X][head.Y] = headCollisionChar
+		} else {
+			board[head.X][head.Y] = headChar(o.Battlesnakes[s.ID].Character)
+		}
+	}
+}
+
 func buildSnakesFromOptions(o *Options) []Battlesnake {
-	bodyChars := []rune{'■', '⌀', '●', '⍟', '◘', '☺', '□', '☻'}
 	var numSnakes int
 	var snakes []Battlesnake
 	numNames := len(o.Names)
 	numURLs := len(o.URLs)
 	numSquads := len(o.Squads)
+	templates, _ := parsePathTemplates(o.PathTemplates)
+	nicknames, _ := parseNicknames(o.Nicknames)
 	if numNames > numURLs {
 		numSnakes = numNames
 	} else {
@@ -494,26 +2034,41 @@ func buildSnakesFromOptions(o *Options) []Battlesnake {
 				snakeSquad = strconv.Itoa(i / 2)
 			}
 		}
-		res, err := o.HttpClient.Get(snakeURL)
 		api := "0"
-		if err != nil {
+		var version string
+		color := snakeColors[i%len(snakeColors)]
+		if o.NoPing {
+			// Snakes that aren't pinged are assumed to implement the current API.
+			api = "1"
+		} else if res, err := o.HttpClient.Get(snakeURL); err != nil {
 			o.Log("[WARN]: Request to %v failed", snakeURL)
 		} else if res.Body != nil {
 			defer res.Body.Close()
 			body, readErr := ioutil.ReadAll(res.Body)
-			if readErr != nil {
-				log.Fatal(readErr)
-			}
-
 			pingResponse := PingResponse{}
-			jsonErr := json.Unmarshal(body, &pingResponse)
-			if jsonErr != nil {
-				log.Fatal(jsonErr)
+			// A snake with a broken ping still plays, with the default appearance.
+			if readErr != nil {
+				o.Log("[WARN]: Unable to read the ping response of %v: %v\n", snakeURL, readErr)
+			} else if jsonErr := json.Unmarshal(body, &pingResponse); jsonErr != nil {
+				o.Log("[WARN]: Invalid ping response from %v, defaults will be applied: %v\n", snakeURL, jsonErr)
 			} else {
 				api = pingResponse.APIVersion
+				version = pingResponse.Version
+				if _, ok := parseHexColor(pingResponse.Color); ok {
+					color = pingResponse.Color
+				}
 			}
 		}
-		snake := Battlesnake{Name: snakeName, URL: snakeURL, ID: id, API: api, LastMove: "up", Character: bodyChars[i%8]}
+		char := bodyChars[i%8]
+		if o.StableChars {
+			char = stableChar(snakeName)
+		}
+		snake := Battlesnake{Name: snakeName, URL: snakeURL, ID: id, API: api, LastMove: "up", Character: char, Color: color, Version: version, PathTemplate: templates[snakeName]}
+		if nick, ok := nicknames[snakeURL]; ok {
+			snake.Nickname = nick
+		} else {
+			snake.Nickname = nicknames[snakeName]
+		}
 		if o.GameType == "squad" {
 			snake.Squad = snakeSquad
 		}
@@ -522,40 +2077,145 @@ func buildSnakesFromOptions(o *Options) []Battlesnake {
 	return snakes
 }
 
+// clearScreen moves the cursor home and clears the terminal, so each map is drawn in place.
+const clearScreen = "\033[H\033[2J"
+
+// isTerminal reports whether the log output is a terminal, rather than a file or pipe.
+var isTerminal = func() bool {
+	f, ok := log.Writer().(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func printMap(o *Options, state *rules.BoardState, outOfBounds []rules.Point) {
+	if o.ViewMapClear && isTerminal() {
+		o.Log("%s%s", clearScreen, renderMap(o, state, outOfBounds))
+		return
+	}
+	o.Log("%s", renderMap(o, state, outOfBounds))
+}
+
+func renderMap(o *Options, state *rules.BoardState, outOfBounds []rules.Point) string {
 	var b bytes.Buffer
 	b.WriteString(fmt.Sprintf("Ruleset: %s, Seed: %d, Turn: %v\n", o.GameType, o.Seed, o.Turn))
-	board := make([][]rune, state.Width)
-	for i := range board {
-		board[i] = make([]rune, state.Height)
-	}
-	for y := int32(0); y < state.Height; y++ {
-		for x := int32(0); x < state.Width; x++ {
-			board[x][y] = '◦'
-		}
-	}
-	for _, oob := range outOfBounds {
-		board[oob.X][oob.Y] = '░'
-	}
+	board := newBoardGrid(state.Width, state.Height, outOfBounds, state.Food)
 	b.WriteString(fmt.Sprintf("Hazards ░: %v\n", outOfBounds))
-	for _, f := range state.Food {
-		board[f.X][f.Y] = '⚕'
+	if o.GameType == "walls" {
+		for _, w := range o.Walls {
+			board[w.X][w.Y] = '▓'
+		}
+		b.WriteString(fmt.Sprintf("Walls ▓: %v\n", o.Walls))
 	}
 	b.WriteString(fmt.Sprintf("Food ⚕: %v\n", state.Food))
 	for _, s := range state.Snakes {
+		char := o.Battlesnakes[s.ID].Character
+		if o.GhostTurns > 0 && s.EliminatedCause != rules.NotEliminated {
+			char = ghostChar
+			if !isGhost(o, s) {
+				char = 0
+			}
+		}
 		for _, b := range s.Body {
-			if b.X < 0 || b.Y < 0 || b.X >= state.Width || b.Y >= state.Height{
+			// Only snakes eliminated by leaving the board can be out of bounds, wrapped snakes never are.
+			if char == 0 || b.X < 0 || b.Y < 0 || b.X >= state.Width || b.Y >= state.Height {
+				continue
+			}
+			// Ghosts don't hide anything else on the board.
+			if char == ghostChar && board[b.X][b.Y] != emptyChar {
 				continue
 			}
-			board[b.X][b.Y] = o.Battlesnakes[s.ID].Character
+			board[b.X][b.Y] = char
+		}
+		b.WriteString(fmt.Sprintf("%v %c: %v\n", snakeLabel(displayName(o.Battlesnakes[s.ID]), o.Battlesnakes[s.ID].Version), o.Battlesnakes[s.ID].Character, s))
+	}
+	if o.RenderHeads {
+		drawHeads(o, board, state)
+	}
+	writeBoardGrid(&b, board)
+	return b.String()
+}
+
+// parseNicknames parses the url=nickname pairs of --nick into nicknames by URL or snake name.
+// URLs can contain =, so the nickname is after the last one.
+func parseNicknames(pairs []string) (map[string]string, error) {
+	nicknames := make(map[string]string)
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 || i == len(pair)-1 {
+			return nil, fmt.Errorf("expected url=nickname, got %q", pair)
+		}
+		nicknames[pair[:i]] = pair[i+1:]
+	}
+	return nicknames, nil
+}
+
+// displayName is the name a snake is shown by in logs and legends, its --nick if it has one.
+// Payloads and results always use the real name.
+func displayName(snake Battlesnake) string {
+	if snake.Nickname != "" {
+		return snake.Nickname
+	}
+	return snake.Name
+}
+
+// snakeLabel names a snake along with the version it reported, if any.
+func snakeLabel(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return fmt.Sprintf("%v (%v)", name, version)
+}
+
+// boardSeed returns the seed for the board's randomness, which can be set apart from the seed
+// used for other randomness, such as random snakes, to keep the board the same.
+func boardSeed(o *Options) int64 {
+	if o.BoardSeed != 0 {
+		return o.BoardSeed
+	}
+	return o.Seed
+}
+
+// shufflePlacement returns the snake IDs in an order determined by the seed,
+// so snakes don't always get the same start positions across a batch.
+func shufflePlacement(snakeIds []string, seed int64) []string {
+	shuffled := append([]string{}, snakeIds...)
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func newBoardGrid(width int32, height int32, hazards []rules.Point, food []rules.Point) [][]rune {
+	board := make([][]rune, width)
+	for i := range board {
+		board[i] = make([]rune, height)
+	}
+	for y := int32(0); y < height; y++ {
+		for x := int32(0); x < width; x++ {
+			board[x][y] = emptyChar
 		}
-		b.WriteString(fmt.Sprintf("%v %c: %v\n", o.Battlesnakes[s.ID].Name, o.Battlesnakes[s.ID].Character, s))
 	}
-	for y := state.Height - 1; y >= 0; y-- {
-		for x := int32(0); x < state.Width; x++ {
+	for _, oob := range hazards {
+		board[oob.X][oob.Y] = '░'
+	}
+	for _, f := range food {
+		board[f.X][f.Y] = '⚕'
+	}
+	return board
+}
+
+func writeBoardGrid(b *bytes.Buffer, board [][]rune) {
+	if len(board) == 0 {
+		return
+	}
+	for y := len(board[0]) - 1; y >= 0; y-- {
+		for x := range board {
 			b.WriteRune(board[x][y])
 		}
 		b.WriteString("\n")
 	}
-	log.Print(b.String())
 }
@@ -0,0 +1,1738 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+type testLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLog) Log(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *testLog) Count(substr string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var n int
+	for _, line := range l.lines {
+		if strings.Contains(line, substr) {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestSnake(t *testing.T, move func(ResponsePayload) string) *httptest.Server {
+	return newTestServer(t, func(w http.ResponseWriter, payload ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: move(payload)})
+	})
+}
+
+// newTestServer starts a snake server that handles /move requests with the given handler.
+func newTestServer(t *testing.T, move func(http.ResponseWriter, ResponsePayload)) *httptest.Server {
+	srv := httptest.NewServer(newTestMux(move))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestMux(move func(http.ResponseWriter, ResponsePayload)) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(PingResponse{APIVersion: "1"})
+	})
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/move", func(w http.ResponseWriter, r *http.Request) {
+		var payload ResponsePayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		move(w, payload)
+	})
+	return mux
+}
+
+// closeConnection fails the request on the client side.
+func closeConnection(t *testing.T, w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	require.NoError(t, err)
+	conn.Close()
+}
+
+// circleMove loops a snake clockwise around a 2x2 board, where no food can spawn.
+func circleMove(p ResponsePayload) string {
+	head := p.You.Head
+	switch {
+	case head.X == 0 && head.Y == 0:
+		return rules.MoveUp
+	case head.X == 0:
+		return rules.MoveRight
+	case head.Y == 1:
+		return rules.MoveDown
+	default:
+		return rules.MoveLeft
+	}
+}
+
+func TestRunHealthWarnings(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	l := new(testLog)
+
+	o := &Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"starver"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		HealthWarn: 10,
+		Log:        l.Log,
+	}
+	res := Run(o)
+
+	require.Len(t, res.Snakes, 1)
+	require.Equal(t, "starver", res.Snakes[0].Name)
+	require.Equal(t, rules.EliminatedByOutOfHealth, res.Snakes[0].EliminatedCause)
+	require.Equal(t, int32(0), res.Snakes[0].Health)
+	require.Equal(t, 9, l.Count("starver health is low"))
+	require.Equal(t, 1, l.Count("starver finished with length 4 and health 0 (ran out of health)"))
+}
+
+func TestLogHealthCrossings(t *testing.T) {
+	l := new(testLog)
+	o := &Options{
+		MinHealthWarn: 10,
+		Battlesnakes:  map[string]Battlesnake{"one": {ID: "one", Name: "Snake1"}},
+		belowHealth:   make(map[string]bool),
+		Log:           l.Log,
+	}
+
+	// The snake dips below the threshold twice, recovering by eating in between.
+	var events []HealthEvent
+	for i, health := range []int32{50, 12, 9, 8, 100, 11, 10, 9, 5, 100} {
+		o.Turn = int32(i)
+		state := &rules.BoardState{Snakes: []rules.Snake{{ID: "one", Health: health}}}
+		events = append(events, logHealthCrossings(o, state)...)
+	}
+	require.Equal(t, []HealthEvent{
+		{Turn: 2, ID: "one", Name: "Snake1", Health: 9, Threshold: 10},
+		{Turn: 7, ID: "one", Name: "Snake1", Health: 9, Threshold: 10},
+	}, events)
+	require.Equal(t, []string{
+		`[HEALTH]: {"turn":2,"id":"one","name":"Snake1","health":9,"threshold":10}`,
+		`[HEALTH]: {"turn":7,"id":"one","name":"Snake1","health":9,"threshold":10}`,
+	}, l.lines)
+
+	// Eliminated snakes have no events.
+	o.Turn++
+	state := &rules.BoardState{Snakes: []rules.Snake{{ID: "one", Health: 0, EliminatedCause: rules.EliminatedByOutOfHealth}}}
+	require.Empty(t, logHealthCrossings(o, state))
+}
+
+func TestRunMinHealthWarn(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	o := &Options{
+		Width:         2,
+		Height:        2,
+		Names:         []string{"starver"},
+		URLs:          []string{srv.URL},
+		GameType:      "solo",
+		Seed:          1,
+		MinHealthWarn: 10,
+		Log:           new(testLog).Log,
+	}
+	res := Run(o)
+
+	// Without food the snake only crosses once, on its way to starving.
+	require.Len(t, res.HealthEvents, 1)
+	require.Equal(t, "starver", res.HealthEvents[0].Name)
+	require.Equal(t, int32(9), res.HealthEvents[0].Health)
+}
+
+func TestGetRulesetAutoScale(t *testing.T) {
+	o := &Options{Width: 25, Height: 25, GameType: "standard"}
+	ruleset, _ := getRuleset(o, nil)
+	require.Equal(t, int32(1), ruleset.(*rules.StandardRuleset).MinimumFood)
+
+	o.AutoScale = true
+	ruleset, _ = getRuleset(o, nil)
+	require.Greater(t, ruleset.(*rules.StandardRuleset).MinimumFood, int32(1))
+
+	o.GameType = "royale"
+	_, royale := getRuleset(o, nil)
+	require.Less(t, royale.ShrinkEveryNTurns, int32(20))
+
+	o.Width, o.Height = rules.BoardSizeMedium, rules.BoardSizeMedium
+	_, royale = getRuleset(o, nil)
+	require.Equal(t, int32(1), royale.MinimumFood)
+	require.Equal(t, int32(20), royale.ShrinkEveryNTurns)
+}
+
+func TestRunLogMoves(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, p ResponsePayload) {
+		if p.Turn == 3 {
+			closeConnection(t, w)
+			return
+		}
+		move := rules.MoveUp
+		if p.Turn == 2 {
+			move = rules.MoveRight
+		}
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: move})
+	})
+	l := new(testLog)
+
+	o := &Options{
+		Width:      15,
+		Height:     15,
+		Names:      []string{"mover"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       6,
+		LogMoves:   true,
+		Log:        l.Log,
+	}
+	res := Run(o)
+
+	var entries []MoveLog
+	for _, line := range l.lines {
+		if strings.HasPrefix(line, "[MOVE]: ") {
+			var entry MoveLog
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+			entries = append(entries, entry)
+		}
+	}
+	require.Len(t, entries, int(res.Turn))
+	require.Greater(t, len(entries), 3)
+	for i, entry := range entries {
+		require.Equal(t, int32(i+1), entry.Turn)
+		require.Equal(t, "mover", entry.Name)
+		switch entry.Turn {
+		case 2:
+			require.Equal(t, rules.MoveRight, entry.Move)
+			require.False(t, entry.Fallback)
+		case 3:
+			// Falls back to the last move
+			require.Equal(t, rules.MoveRight, entry.Move)
+			require.True(t, entry.Fallback)
+		default:
+			require.Equal(t, rules.MoveUp, entry.Move)
+			require.False(t, entry.Fallback)
+		}
+	}
+}
+
+func TestShufflePlacement(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	shuffled := shufflePlacement(ids, 42)
+	require.Equal(t, shuffled, shufflePlacement(ids, 42))
+	require.NotEqual(t, ids, shuffled)
+	require.ElementsMatch(t, ids, shuffled)
+	require.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, ids)
+}
+
+func TestRunShufflePlacement(t *testing.T) {
+	var urls []string
+	for i := 0; i < 4; i++ {
+		urls = append(urls, newTestSnake(t, upMove).URL)
+	}
+	o := &Options{
+		Width:            rules.BoardSizeSmall,
+		Height:           rules.BoardSizeSmall,
+		Names:            []string{"a", "b", "c", "d"},
+		URLs:             urls,
+		GameType:         "standard",
+		Sequential:       true,
+		Seed:             3,
+		ShufflePlacement: true,
+		Log:              new(testLog).Log,
+	}
+	res := Run(o)
+
+	var ids, placed []string
+	for _, snake := range res.Board.Snakes {
+		placed = append(placed, o.Battlesnakes[snake.ID].Name)
+	}
+	for _, name := range o.Names {
+		for id, snake := range o.Battlesnakes {
+			if snake.Name == name {
+				ids = append(ids, id)
+			}
+		}
+	}
+	var expected []string
+	for _, id := range shufflePlacement(ids, 3) {
+		expected = append(expected, o.Battlesnakes[id].Name)
+	}
+	require.Equal(t, expected, placed)
+	require.NotEqual(t, o.Names, placed)
+}
+
+func TestRunCheckIDs(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: rules.MoveUp, Id: "not-" + p.You.Id})
+	})
+	run := func(checkIDs bool) *testLog {
+		l := new(testLog)
+		Run(&Options{
+			Width:      rules.BoardSizeSmall,
+			Height:     rules.BoardSizeSmall,
+			Names:      []string{"liar"},
+			URLs:       []string{srv.URL},
+			GameType:   "solo",
+			Sequential: true,
+			Seed:       1,
+			CheckIDs:   checkIDs,
+			Log:        l.Log,
+		})
+		return l
+	}
+
+	l := run(true)
+	require.Equal(t, 1, l.Count("[WARN]: [1]: liar responded with id not-"))
+	require.Greater(t, l.Count("liar responded with id"), 0)
+
+	l = run(false)
+	require.Equal(t, 0, l.Count("responded with id"))
+}
+
+func TestRunNoPing(t *testing.T) {
+	mux := newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	})
+	var gets int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+			_ = json.NewEncoder(w).Encode(PingResponse{APIVersion: "1", Color: "#123456"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	run := func(noPing bool) (*Options, Result) {
+		atomic.StoreInt32(&gets, 0)
+		o := &Options{
+			Width:    2,
+			Height:   2,
+			Names:    []string{"unpinged"},
+			URLs:     []string{srv.URL},
+			GameType: "solo",
+			Seed:     1,
+			MaxTurns: 3,
+			NoPing:   noPing,
+			Log:      new(testLog).Log,
+		}
+		return o, Run(o)
+	}
+
+	o, res := run(true)
+	require.Equal(t, int32(0), atomic.LoadInt32(&gets))
+	require.Equal(t, int32(3), res.Turn)
+	require.Len(t, o.Battlesnakes, 1)
+	for _, snake := range o.Battlesnakes {
+		require.Equal(t, "1", snake.API)
+		require.Equal(t, snakeColors[0], snake.Color)
+		require.Equal(t, bodyChars[0], snake.Character)
+	}
+
+	o, _ = run(false)
+	require.Equal(t, int32(2), atomic.LoadInt32(&gets))
+	for _, snake := range o.Battlesnakes {
+		require.Equal(t, "#123456", snake.Color)
+	}
+}
+
+func TestRunInvalidPing(t *testing.T) {
+	mux := newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte("<html>not a snake</html>"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	l := new(testLog)
+	o := &Options{
+		Width:    2,
+		Height:   2,
+		Names:    []string{"html"},
+		URLs:     []string{srv.URL},
+		GameType: "solo",
+		Seed:     1,
+		MaxTurns: 3,
+		Log:      l.Log,
+	}
+	res := Run(o)
+
+	require.Equal(t, int32(3), res.Turn)
+	require.Equal(t, 1, l.Count("[WARN]: Invalid ping response from "+srv.URL+", defaults will be applied"))
+	require.Len(t, o.Battlesnakes, 1)
+	for _, snake := range o.Battlesnakes {
+		require.Equal(t, "0", snake.API)
+		require.Equal(t, snakeColors[0], snake.Color)
+	}
+}
+
+func TestBuildSnakesStableChars(t *testing.T) {
+	build := func(names []string, stable bool) map[string]rune {
+		o := &Options{
+			Names:       names,
+			URLs:        []string{"http://a", "http://b", "http://c"},
+			NoPing:      true,
+			StableChars: stable,
+			Log:         new(testLog).Log,
+		}
+		chars := make(map[string]rune)
+		for _, snake := range buildSnakesFromOptions(o) {
+			chars[snake.Name] = snake.Character
+		}
+		return chars
+	}
+
+	chars := build([]string{"alpha", "beta", "gamma"}, true)
+	require.Equal(t, chars, build([]string{"gamma", "alpha", "beta"}, true))
+	for name, char := range chars {
+		require.Equal(t, stableChar(name), char)
+	}
+
+	// By default, characters follow the order snakes are given in.
+	require.Equal(t, bodyChars[0], build([]string{"alpha", "beta", "gamma"}, false)["alpha"])
+	require.Equal(t, bodyChars[1], build([]string{"gamma", "alpha", "beta"}, false)["alpha"])
+}
+
+func TestRunPathTemplate(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	})))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/" {
+			_ = json.NewEncoder(w).Encode(PingResponse{APIVersion: "1"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	res := Run(&Options{
+		Width:         2,
+		Height:        2,
+		Names:         []string{"custom"},
+		URLs:          []string{srv.URL},
+		PathTemplates: []string{"custom=/api/v1/{action}"},
+		GameType:      "solo",
+		Seed:          1,
+		MaxTurns:      2,
+		Log:           new(testLog).Log,
+	})
+	require.Equal(t, int32(2), res.Turn)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 1, hits["/api/v1/start"])
+	require.Equal(t, 2, hits["/api/v1/move"])
+	require.Equal(t, 1, hits["/api/v1/end"])
+	require.Zero(t, hits["/move"])
+}
+
+func TestParsePathTemplates(t *testing.T) {
+	templates, err := parsePathTemplates([]string{"a=/api/{action}", "b=/x/{action}/y"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "/api/{action}", "b": "/x/{action}/y"}, templates)
+
+	for _, invalid := range []string{"a", "=/api/{action}", "a=/api"} {
+		_, err := parsePathTemplates([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+
+	u := snakeActionURL(Battlesnake{URL: "http://example.com/snake", PathTemplate: "/api/{action}"}, "move")
+	require.Equal(t, "http://example.com/snake/api/move", u.String())
+	u = snakeActionURL(Battlesnake{URL: "http://example.com/snake"}, "move")
+	require.Equal(t, "http://example.com/snake/move", u.String())
+}
+
+func TestParseNicknames(t *testing.T) {
+	nicknames, err := parseNicknames([]string{"http://localhost:8000/?key=abc=short", "long-name=ln"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"http://localhost:8000/?key=abc": "short", "long-name": "ln"}, nicknames)
+
+	for _, invalid := range []string{"short", "=short", "http://localhost:8000="} {
+		_, err := parseNicknames([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestRunNicknames(t *testing.T) {
+	var mu sync.Mutex
+	payloadNames := make(map[string]bool)
+	move := func(p ResponsePayload) string {
+		mu.Lock()
+		payloadNames[p.You.Name] = true
+		mu.Unlock()
+		return rules.MoveUp
+	}
+	one := newTestSnake(t, move)
+	two := newTestSnake(t, move)
+	legend := filepath.Join(t.TempDir(), "legend.json")
+	l := new(testLog)
+	o := &Options{
+		Width:     rules.BoardSizeSmall,
+		Height:    rules.BoardSizeSmall,
+		Names:     []string{"a-snake-with-a-long-name", "another-snake-with-a-long-name"},
+		URLs:      []string{one.URL, two.URL},
+		GameType:  "standard",
+		Seed:      1,
+		Nicknames: []string{one.URL + "=one", "another-snake-with-a-long-name=two"},
+		Legend:    legend,
+		ViewMap:   true,
+		Log:       l.Log,
+	}
+	res := Run(o)
+
+	// The compact log and eliminations name the snakes too.
+	o.ViewMap, o.CompactLog, o.VerboseEliminations = false, true, true
+	Run(o)
+	require.NotZero(t, l.Count("[ELIMINATED]: "))
+
+	// Payloads and results keep the real names.
+	require.Equal(t, map[string]bool{"a-snake-with-a-long-name": true, "another-snake-with-a-long-name": true}, payloadNames)
+	for _, sr := range res.Snakes {
+		require.Contains(t, o.Names, sr.Name)
+	}
+
+	// Logs and the legend use the nicknames.
+	require.Equal(t, 2, l.Count("[DONE]: one finished"))
+	require.Equal(t, 2, l.Count("[DONE]: two finished"))
+	for _, line := range l.lines {
+		require.NotContains(t, line, "snake-with-a-long-name")
+	}
+	b, err := ioutil.ReadFile(legend)
+	require.NoError(t, err)
+	var entries map[string]LegendEntry
+	require.NoError(t, json.Unmarshal(b, &entries))
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	require.ElementsMatch(t, []string{"one", "two"}, names)
+}
+
+func TestRunMissingMove(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, p ResponsePayload) {
+		if p.Turn == 1 {
+			_ = json.NewEncoder(w).Encode(PlayerResponse{Move: rules.MoveUp})
+			return
+		}
+		_, _ = w.Write([]byte(`{"shout":"hi"}`))
+	})
+	l := new(testLog)
+	Run(&Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"quiet"},
+		URLs:     []string{srv.URL},
+		GameType: "solo",
+		Seed:     1,
+		MaxTurns: 3,
+		LogMoves: true,
+		Log:      l.Log,
+	})
+
+	require.Equal(t, 2, l.Count(`responded without a move, using its last move "up"`))
+	require.Equal(t, 1, l.Count(`[WARN]: [2]: quiet responded without a move`))
+	var moves []MoveLog
+	for _, line := range l.lines {
+		if strings.HasPrefix(line, "[MOVE]: ") {
+			var entry MoveLog
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+			moves = append(moves, entry)
+		}
+	}
+	require.Len(t, moves, 3)
+	for i, move := range moves {
+		require.Equal(t, rules.MoveUp, move.Move)
+		require.Equal(t, i > 0, move.Fallback)
+	}
+}
+
+func TestRunAccessibleArea(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	l := new(testLog)
+	o := &Options{
+		Width:          2,
+		Height:         2,
+		Names:          []string{"circler"},
+		URLs:           []string{srv.URL},
+		GameType:       "solo",
+		Seed:           1,
+		MaxTurns:       3,
+		AccessibleArea: true,
+		Log:            l.Log,
+	}
+	Run(o)
+
+	// The snake eats the only food on turn 2, after which its body fills the board.
+	require.Equal(t, 1, l.Count("[AREA]: [1]: circler can reach 2 free cells"))
+	require.Equal(t, 1, l.Count("[AREA]: [2]: circler can reach 1 free cells"))
+	require.Equal(t, 1, l.Count("[AREA]: [3]: circler can reach 0 free cells"))
+}
+
+func TestRunBoardFillReport(t *testing.T) {
+	up := newTestSnake(t, upMove)
+	square := newTestSnake(t, squareMove)
+	l := new(testLog)
+
+	o := &Options{
+		Width:           rules.BoardSizeMedium,
+		Height:          rules.BoardSizeMedium,
+		Names:           []string{"up", "square"},
+		URLs:            []string{up.URL, square.URL},
+		GameType:        "constrictor",
+		Sequential:      true,
+		Seed:            1,
+		BoardFillReport: true,
+		Log:             l.Log,
+	}
+	res := Run(o)
+	require.Greater(t, res.Turn, int32(2))
+
+	var occupied []int
+	for _, line := range l.lines {
+		if !strings.HasPrefix(line, "[FILL]: ") {
+			continue
+		}
+		var turn int32
+		var cells, free int
+		var pct float64
+		_, err := fmt.Sscanf(line, "[FILL]: [%d]: %d occupied cells (%f%%), %d free cells\n", &turn, &cells, &pct, &free)
+		require.NoError(t, err)
+		require.Equal(t, int32(len(occupied)+1), turn)
+		require.Equal(t, rules.BoardSizeMedium*rules.BoardSizeMedium, cells+free)
+		occupied = append(occupied, cells)
+	}
+	require.Len(t, occupied, int(res.Turn))
+
+	// Both snakes grow every turn until the final turn, when snakes are eliminated.
+	for i := 1; i < len(occupied)-1; i++ {
+		require.Greater(t, occupied[i], occupied[i-1])
+	}
+}
+
+func TestRunSnakeVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(PingResponse{APIVersion: "1", Version: "v1.2.3"})
+	})
+	mux.HandleFunc("/move", func(w http.ResponseWriter, r *http.Request) {
+		var payload ResponsePayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(payload)})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	l := new(testLog)
+
+	o := &Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"versioned"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		ViewMap:    true,
+		Log:        l.Log,
+	}
+	res := Run(o)
+
+	require.Equal(t, int(res.Turn), l.Count("versioned (v1.2.3) ■: "))
+	require.Equal(t, "v1.2.3", res.Snakes[0].Version)
+	require.Equal(t, 1, l.Count("[DONE]: versioned (v1.2.3) finished with length"))
+}
+
+func TestRunSpiralHazards(t *testing.T) {
+	srv := newTestSnake(t, squareMove)
+	path := writeTestFrames(t, nil)
+
+	o := &Options{
+		Width:         rules.BoardSizeSmall,
+		Height:        rules.BoardSizeSmall,
+		Names:         []string{"square"},
+		URLs:          []string{srv.URL},
+		GameType:      "solo",
+		Sequential:    true,
+		Seed:          1,
+		HazardPattern: "spiral",
+		HazardGrowth:  2,
+		MaxTurns:      12,
+		Output:        path,
+		Log:           new(testLog).Log,
+	}
+	Run(o)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	frames, err := readFrames(f)
+	require.NoError(t, err)
+	require.Len(t, frames, 13)
+
+	spiral := []Coord{{3, 3}, {4, 3}, {4, 4}, {3, 4}, {2, 4}, {2, 3}}
+	for _, frame := range frames {
+		require.Equal(t, spiral[:frame.Turn/2], frame.Board.Hazards, "turn %v", frame.Turn)
+	}
+}
+
+func TestRunEchoRequest(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	run := func(echo bool) (*testLog, Result) {
+		l := new(testLog)
+		res := Run(&Options{
+			Width:       2,
+			Height:      2,
+			Names:       []string{"echo"},
+			URLs:        []string{srv.URL},
+			GameType:    "solo",
+			Sequential:  true,
+			Seed:        1,
+			EchoRequest: echo,
+			Log:         l.Log,
+		})
+		return l, res
+	}
+
+	l, res := run(true)
+	require.Equal(t, int(res.Turn), l.Count("[REQUEST]: "))
+	for turn := int32(1); turn <= res.Turn; turn++ {
+		prefix := fmt.Sprintf("[REQUEST]: [%v]: echo /move\n", turn)
+		var found bool
+		for _, line := range l.lines {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			found = true
+			var payload ResponsePayload
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, prefix)), &payload))
+			require.Equal(t, turn, payload.Turn)
+			require.Equal(t, "echo", payload.You.Name)
+			require.Contains(t, line, "\n  \"game\": {")
+		}
+		require.True(t, found, "turn %v", turn)
+	}
+
+	l, _ = run(false)
+	require.Equal(t, 0, l.Count("[REQUEST]: "))
+}
+
+// hostTransport sends every request to the test server, whatever the snake URL.
+type hostTransport struct {
+	srv   *httptest.Server
+	mu    sync.Mutex
+	paths []string
+}
+
+func (t *hostTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.paths = append(t.paths, r.URL.Path)
+	t.mu.Unlock()
+	u, _ := url.Parse(t.srv.URL)
+	r.URL.Scheme, r.URL.Host = u.Scheme, u.Host
+	return t.srv.Client().Transport.RoundTrip(r)
+}
+
+func TestRunHttpClient(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	transport := &hostTransport{srv: srv}
+
+	res := Run(&Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"injected"},
+		URLs:       []string{"http://snake.invalid"},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		HttpClient: &http.Client{Transport: transport},
+		Log:        new(testLog).Log,
+	})
+
+	require.Equal(t, rules.EliminatedByOutOfHealth, res.Snakes[0].EliminatedCause)
+	var moves int
+	for _, p := range transport.paths {
+		if p == "/move" {
+			moves++
+		}
+	}
+	require.Equal(t, int(res.Turn), moves)
+	require.Equal(t, []string{"", "", "/start"}, transport.paths[:3])
+}
+
+func TestGetRulesetFoodSchedule(t *testing.T) {
+	o := &Options{Width: 11, Height: 11, GameType: "standard", FoodSchedule: "0:1, 100:4,200:0"}
+
+	for _, turn := range []int32{1, 99, 100, 199, 200, 300} {
+		o.Turn = turn
+		ruleset, _ := getRuleset(o, nil)
+		schedule, ok := ruleset.(*rules.FoodScheduleRuleset)
+		require.True(t, ok)
+		require.Equal(t, turn, schedule.Turn)
+		require.Equal(t, int32(0), schedule.Ruleset.(*rules.StandardRuleset).MinimumFood)
+	}
+
+	schedule, err := parseFoodSchedule(o.FoodSchedule)
+	require.NoError(t, err)
+	require.Equal(t, []rules.FoodSchedulePoint{{Turn: 0, MinimumFood: 1}, {Turn: 100, MinimumFood: 4}, {Turn: 200, MinimumFood: 0}}, schedule)
+
+	for _, invalid := range []string{"1", "a:1", "1:b", "-1:1", "10:1,5:2", "5:1,5:2"} {
+		_, err := parseFoodSchedule(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestRunFirstMoveDelay(t *testing.T) {
+	var mu sync.Mutex
+	var started time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(PingResponse{APIVersion: "1"})
+	})
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		started = time.Now()
+		mu.Unlock()
+	})
+	mux.HandleFunc("/move", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ready := time.Since(started) > 50*time.Millisecond
+		mu.Unlock()
+		if !ready {
+			closeConnection(t, w)
+			return
+		}
+		var payload ResponsePayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(payload)})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	firstMove := func(delay time.Duration) MoveLog {
+		l := new(testLog)
+		Run(&Options{
+			Width:          2,
+			Height:         2,
+			Names:          []string{"slow"},
+			URLs:           []string{srv.URL},
+			GameType:       "solo",
+			Sequential:     true,
+			Seed:           1,
+			LogMoves:       true,
+			FirstMoveDelay: delay,
+			MaxTurns:       1,
+			Log:            l.Log,
+		})
+		for _, line := range l.lines {
+			if strings.HasPrefix(line, "[MOVE]: ") {
+				var entry MoveLog
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+				return entry
+			}
+		}
+		t.Fatal("no moves logged")
+		return MoveLog{}
+	}
+
+	require.False(t, firstMove(100*time.Millisecond).Fallback)
+	require.True(t, firstMove(0).Fallback)
+}
+
+func TestRunLogMovesCSV(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	path := writeTestFrames(t, nil)
+
+	res := Run(&Options{
+		Width:       rules.BoardSizeSmall,
+		Height:      rules.BoardSizeSmall,
+		Names:       []string{"square", "up"},
+		URLs:        []string{square.URL, up.URL},
+		GameType:    "standard",
+		Seed:        1,
+		LogMovesCSV: path,
+		Log:         new(testLog).Log,
+	})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"turn", "name", "move", "latency_ms", "fallback"}, rows[0])
+	require.Len(t, rows, 1+2*int(res.Turn))
+	for i, row := range rows[1:] {
+		require.Equal(t, strconv.Itoa(i/2+1), row[0])
+		require.Equal(t, []string{"square", "up"}[i%2], row[1])
+		if row[1] == "up" {
+			require.Equal(t, rules.MoveUp, row[2])
+		}
+		latency, err := strconv.Atoi(row[3])
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, latency, 0)
+		require.Equal(t, "false", row[4])
+	}
+}
+
+func TestRunStrict(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, p ResponsePayload) {
+		if p.Turn == 2 {
+			_, _ = w.Write([]byte(`{"move": "right", "moveDirection": "left"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: rules.MoveUp})
+	})
+	run := func(strict bool) (*testLog, []MoveLog) {
+		l := new(testLog)
+		Run(&Options{
+			Width:      rules.BoardSizeMedium,
+			Height:     rules.BoardSizeMedium,
+			Names:      []string{"typo"},
+			URLs:       []string{srv.URL},
+			GameType:   "solo",
+			Sequential: true,
+			Seed:       1,
+			Strict:     strict,
+			LogMoves:   true,
+			MaxTurns:   3,
+			Log:        l.Log,
+		})
+		var entries []MoveLog
+		for _, line := range l.lines {
+			if strings.HasPrefix(line, "[MOVE]: ") {
+				var entry MoveLog
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+				entries = append(entries, entry)
+			}
+		}
+		require.Len(t, entries, 3)
+		return l, entries
+	}
+
+	l, entries := run(true)
+	require.Equal(t, 1, l.Count(`[WARN]: [2]: typo sent an invalid move response: json: unknown field "moveDirection"`))
+	require.Equal(t, rules.MoveUp, entries[1].Move)
+	require.True(t, entries[1].Fallback)
+
+	l, entries = run(false)
+	require.Equal(t, 0, l.Count("invalid move response"))
+	require.Equal(t, rules.MoveRight, entries[1].Move)
+	require.False(t, entries[1].Fallback)
+}
+
+func TestRunWarnNeckMoves(t *testing.T) {
+	srv := newTestSnake(t, func(p ResponsePayload) string {
+		if p.Turn == 2 {
+			return rules.MoveDown
+		}
+		return rules.MoveUp
+	})
+	run := func(warn bool) (*testLog, Result) {
+		l := new(testLog)
+		res := Run(&Options{
+			Width:         rules.BoardSizeMedium,
+			Height:        rules.BoardSizeMedium,
+			Names:         []string{"reverser"},
+			URLs:          []string{srv.URL},
+			GameType:      "solo",
+			Sequential:    true,
+			Seed:          1,
+			WarnNeckMoves: warn,
+			Log:           l.Log,
+		})
+		return l, res
+	}
+
+	l, res := run(true)
+	require.Equal(t, 1, l.Count("[FORFEIT]: [2]: reverser moved down back into its own neck"))
+	require.Equal(t, int32(2), res.Turn)
+	require.Equal(t, rules.EliminatedBySelfCollision, res.Snakes[0].EliminatedCause)
+
+	l, _ = run(false)
+	require.Equal(t, 0, l.Count("[FORFEIT]"))
+}
+
+func TestRunTurnOffset(t *testing.T) {
+	var mu sync.Mutex
+	var startTurn int32 = -1
+	var moveTurns []int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(PingResponse{APIVersion: "1"})
+	})
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		var payload ResponsePayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		startTurn = payload.Turn
+		mu.Unlock()
+	})
+	mux.HandleFunc("/move", func(w http.ResponseWriter, r *http.Request) {
+		var payload ResponsePayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		moveTurns = append(moveTurns, payload.Turn)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(payload)})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	l := new(testLog)
+
+	res := Run(&Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"offset"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		TurnOffset: 50,
+		MaxTurns:   53,
+		Log:        l.Log,
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, int32(50), startTurn)
+	require.Equal(t, []int32{51, 52, 53}, moveTurns)
+	require.Equal(t, int32(53), res.Turn)
+	require.Equal(t, 1, l.Count("[51]: State: "))
+}
+
+func TestRunMaxFood(t *testing.T) {
+	srv := newTestSnake(t, squareMove)
+	l := new(testLog)
+
+	Run(&Options{
+		Width:        rules.BoardSizeMedium,
+		Height:       rules.BoardSizeMedium,
+		Names:        []string{"square"},
+		URLs:         []string{srv.URL},
+		GameType:     "solo",
+		Sequential:   true,
+		Seed:         1,
+		FoodPerSpawn: 5,
+		FoodChance:   100,
+		MaxFood:      3,
+		MaxTurns:     50,
+		CompactLog:   true,
+		Log:          l.Log,
+	})
+
+	var maxFood int
+	for _, line := range l.lines {
+		var turn, alive, food int
+		if _, err := fmt.Sscanf(line, "[%d]: Alive: %d Food: %d", &turn, &alive, &food); err != nil {
+			continue
+		}
+		require.LessOrEqual(t, food, 3, "turn %v", turn)
+		if food > maxFood {
+			maxFood = food
+		}
+	}
+	require.Equal(t, 3, maxFood)
+}
+
+func TestRunViewMapClear(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	run := func(terminal bool) *testLog {
+		defer func(f func() bool) { isTerminal = f }(isTerminal)
+		isTerminal = func() bool { return terminal }
+
+		l := new(testLog)
+		Run(&Options{
+			Width:        2,
+			Height:       2,
+			Names:        []string{"clear"},
+			URLs:         []string{srv.URL},
+			GameType:     "solo",
+			Sequential:   true,
+			Seed:         1,
+			MaxTurns:     3,
+			ViewMap:      true,
+			ViewMapClear: true,
+			Log:          l.Log,
+		})
+		return l
+	}
+
+	l := run(true)
+	require.Equal(t, 3, l.Count(clearScreen+"Ruleset: solo"))
+
+	l = run(false)
+	require.Equal(t, 0, l.Count(clearScreen))
+	require.Equal(t, 3, l.Count("Ruleset: solo"))
+}
+
+func TestRunEliminationTimeline(t *testing.T) {
+	right := newTestSnake(t, func(ResponsePayload) string { return rules.MoveRight })
+	left := newTestSnake(t, func(ResponsePayload) string { return rules.MoveLeft })
+	up := newTestSnake(t, upMove)
+	path := writeTestFrames(t, []Frame{{Board: BoardResponse{
+		Width:  7,
+		Height: 7,
+		Snakes: []SnakeResponse{
+			{Id: "wall", Health: 100, Body: []Coord{{X: 0, Y: 6}, {X: 0, Y: 5}, {X: 0, Y: 4}}},
+			{Id: "long", Health: 100, Body: []Coord{{X: 2, Y: 4}, {X: 2, Y: 3}, {X: 2, Y: 2}, {X: 2, Y: 1}}},
+			{Id: "short", Health: 100, Body: []Coord{{X: 4, Y: 4}, {X: 5, Y: 4}, {X: 6, Y: 4}}},
+			{Id: "survivor", Health: 100, Body: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}},
+		},
+	}}})
+
+	res := Run(&Options{
+		Names:        []string{"wall", "long", "short", "survivor"},
+		URLs:         []string{up.URL, right.URL, left.URL, up.URL},
+		GameType:     "standard",
+		Sequential:   true,
+		Seed:         1,
+		InitialState: path,
+		Log:          new(testLog).Log,
+	})
+
+	require.Equal(t, "survivor", res.Winner)
+	require.Equal(t, []EliminationEvent{
+		{Turn: 1, SnakeID: "wall", Cause: rules.EliminatedByOutOfBounds},
+		{Turn: 1, SnakeID: "short", Cause: rules.EliminatedByHeadToHeadCollision, By: "long"},
+		{Turn: 5, SnakeID: "long", Cause: rules.EliminatedByOutOfBounds},
+	}, res.Eliminations)
+}
+
+func TestRunRetryRateLimited(t *testing.T) {
+	var requests int32
+	srv := newTestServer(t, func(w http.ResponseWriter, p ResponsePayload) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	})
+	newOptions := func(l *testLog, timeout int32) *Options {
+		return &Options{
+			Width:            2,
+			Height:           2,
+			Names:            []string{"limited"},
+			URLs:             []string{srv.URL},
+			GameType:         "solo",
+			Sequential:       true,
+			Seed:             1,
+			MaxTurns:         1,
+			Timeout:          timeout,
+			RetryRateLimited: true,
+			LogMoves:         true,
+			Log:              l.Log,
+		}
+	}
+
+	l := new(testLog)
+	start := time.Now()
+	Run(newOptions(l, 2000))
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Second))
+	require.Equal(t, 1, l.Count("[WARN]: [1]: limited is rate limited, retrying in 1s"))
+	require.Equal(t, 1, l.Count(`"fallback":false`))
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// A Retry-After longer than the timeout falls back without waiting.
+	atomic.StoreInt32(&requests, 0)
+	l = new(testLog)
+	start = time.Now()
+	Run(newOptions(l, 500))
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+	require.Equal(t, 1, l.Count(`"fallback":true`))
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	wait, ok := parseRetryAfter("3", now)
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, wait)
+
+	wait, ok = parseRetryAfter(now.Add(2*time.Second).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, wait)
+
+	_, ok = parseRetryAfter("soon", now)
+	require.False(t, ok)
+}
+
+func TestRunFoodEaten(t *testing.T) {
+	srv := newTestSnake(t, upMove)
+	path := writeTestFrames(t, []Frame{{Board: BoardResponse{
+		Width:  7,
+		Height: 7,
+		Food:   []Coord{{X: 1, Y: 2}, {X: 1, Y: 4}},
+		Snakes: []SnakeResponse{
+			{Id: "eater", Health: 100, Body: []Coord{{X: 1, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 0}}},
+			{Id: "hungry", Health: 100, Body: []Coord{{X: 5, Y: 1}, {X: 5, Y: 0}, {X: 5, Y: 0}}},
+		},
+	}}})
+
+	res := Run(&Options{
+		Names:        []string{"eater", "hungry"},
+		URLs:         []string{srv.URL, srv.URL},
+		GameType:     "standard",
+		Sequential:   true,
+		Seed:         1,
+		MaxTurns:     4,
+		InitialState: path,
+		Log:          new(testLog).Log,
+	})
+
+	require.Equal(t, "eater", res.Snakes[0].Name)
+	require.Equal(t, 2, res.Snakes[0].FoodEaten)
+	require.Equal(t, int32(5), res.Snakes[0].Length)
+	require.Equal(t, "hungry", res.Snakes[1].Name)
+	require.Equal(t, 0, res.Snakes[1].FoodEaten)
+	require.Equal(t, int32(3), res.Snakes[1].Length)
+}
+
+func TestGetRulesetHealthDecay(t *testing.T) {
+	ruleset, _ := getRuleset(&Options{GameType: "standard", HealthDecay: 1}, nil)
+	require.IsType(t, &rules.StandardRuleset{}, ruleset)
+
+	ruleset, _ = getRuleset(&Options{GameType: "standard", HealthDecay: 5}, nil)
+	decay, ok := ruleset.(*rules.HealthDecayRuleset)
+	require.True(t, ok)
+	require.Equal(t, int32(5), decay.HealthDecay)
+}
+
+func TestRunHealthDecayRange(t *testing.T) {
+	require.PanicsWithValue(t, "[PANIC]: Health Decay Must Be at Least 1 (or 0 for the Standard Decay of 1), Got -1", func() {
+		Run(&Options{GameType: "standard", HealthDecay: -1, Log: new(testLog).Log})
+	})
+	err := makeRun(&Options{GameType: "standard", Log: new(testLog).Log})(nil, nil)
+	require.EqualError(t, err, "health decay must be at least 1, got 0")
+}
+
+func TestRunRandomHeadings(t *testing.T) {
+	var mu sync.Mutex
+	first := make(map[string][]Coord)
+	srv := newTestSnake(t, func(p ResponsePayload) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := first[p.You.Name]; !ok {
+			first[p.You.Name] = p.You.Body
+		}
+		return upMove(p)
+	})
+	Run(&Options{
+		Width:          rules.BoardSizeSmall,
+		Height:         rules.BoardSizeSmall,
+		Names:          []string{"a", "b"},
+		URLs:           []string{srv.URL, srv.URL},
+		GameType:       "standard",
+		Sequential:     true,
+		Seed:           1,
+		MaxTurns:       1,
+		RandomHeadings: true,
+		Log:            new(testLog).Log,
+	})
+
+	require.Len(t, first, 2)
+	for _, body := range first {
+		require.Len(t, body, 3)
+		require.NotEqual(t, body[0], body[1])
+		require.Equal(t, body[1], body[2])
+	}
+}
+
+func TestRunPrewarm(t *testing.T) {
+	var mu sync.Mutex
+	var turns []int32
+	srv := newTestSnake(t, func(p ResponsePayload) string {
+		mu.Lock()
+		defer mu.Unlock()
+		turns = append(turns, p.Turn)
+		return circleMove(p)
+	})
+	run := func(prewarm bool) []int32 {
+		turns = nil
+		Run(&Options{
+			Width:      2,
+			Height:     2,
+			Names:      []string{"warm"},
+			URLs:       []string{srv.URL},
+			GameType:   "solo",
+			Sequential: true,
+			Seed:       1,
+			MaxTurns:   3,
+			Prewarm:    prewarm,
+			Log:        new(testLog).Log,
+		})
+		return turns
+	}
+
+	require.Equal(t, []int32{1, 2, 3}, run(false))
+	require.Equal(t, []int32{0, 1, 2, 3}, run(true))
+}
+
+func TestRunVerboseEliminations(t *testing.T) {
+	up := newTestSnake(t, upMove)
+	right := newTestSnake(t, func(ResponsePayload) string { return rules.MoveRight })
+	left := newTestSnake(t, func(ResponsePayload) string { return rules.MoveLeft })
+	path := writeTestFrames(t, []Frame{{Board: BoardResponse{
+		Width:  7,
+		Height: 7,
+		Snakes: []SnakeResponse{
+			{Id: "wall", Health: 100, Body: []Coord{{X: 2, Y: 6}, {X: 2, Y: 5}, {X: 2, Y: 4}}},
+			{Id: "long", Health: 100, Body: []Coord{{X: 2, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+			{Id: "short", Health: 100, Body: []Coord{{X: 4, Y: 1}, {X: 5, Y: 1}, {X: 6, Y: 1}}},
+		},
+	}}})
+	l := new(testLog)
+	Run(&Options{
+		Names:               []string{"wall", "long", "short"},
+		URLs:                []string{up.URL, right.URL, left.URL},
+		GameType:            "standard",
+		Sequential:          true,
+		Seed:                1,
+		MaxTurns:            1,
+		InitialState:        path,
+		VerboseEliminations: true,
+		Log:                 l.Log,
+	})
+
+	require.Equal(t, 1, l.Count("[ELIMINATED]: [1]: wall moved out of bounds at (2,7)"))
+	require.Equal(t, 1, l.Count("[ELIMINATED]: [1]: short lost a head-to-head collision at (3,1), eliminated by long"))
+	require.Equal(t, 2, l.Count("[ELIMINATED]"))
+}
+
+func TestEliminationCauseString(t *testing.T) {
+	causes := []string{
+		rules.NotEliminated,
+		rules.EliminatedByCollision,
+		rules.EliminatedBySelfCollision,
+		rules.EliminatedByOutOfHealth,
+		rules.EliminatedByHeadToHeadCollision,
+		rules.EliminatedByOutOfBounds,
+		rules.EliminatedBySquad,
+		rules.EliminatedBySelfTrapped,
+	}
+	seen := make(map[string]bool)
+	for _, cause := range causes {
+		s := eliminationCauseString(cause)
+		require.NotEmpty(t, s, cause)
+		require.NotEqual(t, cause, s, "cause %q has no description", cause)
+		require.False(t, seen[s], "duplicate description %q", s)
+		seen[s] = true
+	}
+	require.Equal(t, "custom-cause", eliminationCauseString("custom-cause"))
+}
+
+func TestRunStrictTimeout(t *testing.T) {
+	fast := newTestSnake(t, upMove)
+	slow := newTestSnake(t, func(p ResponsePayload) string {
+		time.Sleep(500 * time.Millisecond)
+		return rules.MoveRight
+	})
+	l := new(testLog)
+	o := &Options{
+		Width:         rules.BoardSizeMedium,
+		Height:        rules.BoardSizeMedium,
+		Names:         []string{"fast", "slow"},
+		URLs:          []string{fast.URL, slow.URL},
+		GameType:      "standard",
+		Seed:          1,
+		MaxTurns:      1,
+		Timeout:       100,
+		StrictTimeout: true,
+		LogMoves:      true,
+		// The client has no timeout of its own, so only the turn budget stops the slow snake.
+		HttpClient: &http.Client{},
+		Log:        l.Log,
+	}
+	start := time.Now()
+	Run(o)
+	require.Less(t, int64(time.Since(start)), int64(400*time.Millisecond))
+
+	moves := make(map[string]MoveLog)
+	for _, line := range l.lines {
+		if strings.HasPrefix(line, "[MOVE]: ") {
+			var entry MoveLog
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "[MOVE]: ")), &entry))
+			moves[entry.Name] = entry
+		}
+	}
+	require.Equal(t, rules.MoveUp, moves["fast"].Move)
+	require.False(t, moves["fast"].Fallback)
+	require.Equal(t, rules.MoveUp, moves["slow"].Move)
+	require.True(t, moves["slow"].Fallback)
+}
+
+func TestRunBoardChecksum(t *testing.T) {
+	srv := newTestSnake(t, squareMove)
+	run := func(events string) []string {
+		l := new(testLog)
+		Run(&Options{
+			Width:         rules.BoardSizeMedium,
+			Height:        rules.BoardSizeMedium,
+			Names:         []string{"one", "two"},
+			URLs:          []string{srv.URL, srv.URL},
+			GameType:      "standard",
+			Sequential:    true,
+			Seed:          3,
+			MaxTurns:      6,
+			BoardChecksum: true,
+			EventsFile:    events,
+			Log:           l.Log,
+		})
+		var checksums []string
+		for _, line := range l.lines {
+			if strings.HasPrefix(line, "[CHECKSUM]: ") {
+				checksums = append(checksums, line)
+			}
+		}
+		require.Len(t, checksums, 7)
+		return checksums
+	}
+
+	a := run("")
+	require.True(t, strings.HasPrefix(a[0], "[CHECKSUM]: [0]: "))
+	require.Len(t, strings.TrimPrefix(a[0], "[CHECKSUM]: [0]: "), 8)
+	require.Equal(t, a, run(""))
+
+	// Food placed at turn 3 makes the runs diverge from that turn on.
+	events := filepath.Join(t.TempDir(), "events.json")
+	require.NoError(t, ioutil.WriteFile(events, []byte(`[{"turn": 3, "food": [{"X": 0, "Y": 10}]}]`), 0644))
+	b := run(events)
+	require.Equal(t, a[:3], b[:3])
+	for turn := 3; turn < len(a); turn++ {
+		require.NotEqual(t, a[turn], b[turn], "turn %v", turn)
+	}
+}
+
+func TestRunPlacement(t *testing.T) {
+	srv := newTestSnake(t, squareMove)
+	output := filepath.Join(t.TempDir(), "game.ndjson")
+	Run(&Options{
+		Width:      rules.BoardSizeMedium,
+		Height:     rules.BoardSizeMedium,
+		Names:      []string{"one", "two"},
+		URLs:       []string{srv.URL, srv.URL},
+		GameType:   "standard",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   1,
+		Placement:  rules.PlacementCorners,
+		Output:     output,
+		Log:        new(testLog).Log,
+	})
+
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	_, frames, err := readRecording(f)
+	require.NoError(t, err)
+	var heads []Coord
+	for _, snake := range frames[0].Board.Snakes {
+		heads = append(heads, snake.Head)
+	}
+	require.Equal(t, []Coord{{1, 1}, {9, 9}}, heads)
+}
+
+func TestRunPreview(t *testing.T) {
+	var starts, moves int32
+	mux := newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: rules.MoveUp})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			atomic.AddInt32(&starts, 1)
+		case "/move":
+			atomic.AddInt32(&moves, 1)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	l := new(testLog)
+	o := &Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"one", "two"},
+		URLs:     []string{srv.URL, srv.URL},
+		GameType: "standard",
+		Seed:     1,
+		Preview:  true,
+		Log:      l.Log,
+	}
+	res := Run(o)
+	require.Equal(t, int32(0), res.Turn)
+	require.Len(t, res.Board.Snakes, 2)
+	require.NotEmpty(t, res.Board.Food)
+	require.Zero(t, atomic.LoadInt32(&starts))
+	require.Zero(t, atomic.LoadInt32(&moves))
+
+	// The initial board is rendered, with both snakes and the food.
+	require.Equal(t, []string{renderMap(o, res.Board, nil)}, l.lines)
+	for _, snake := range res.Board.Snakes {
+		require.Contains(t, l.lines[0], string(o.Battlesnakes[snake.ID].Character))
+	}
+	require.Contains(t, l.lines[0], "⚕")
+
+	// Playing the game does send /start.
+	played := &Options{
+		Width:    o.Width,
+		Height:   o.Height,
+		Names:    o.Names,
+		URLs:     o.URLs,
+		GameType: o.GameType,
+		Seed:     o.Seed,
+		MaxTurns: 1,
+		Log:      new(testLog).Log,
+	}
+	Run(played)
+	require.Equal(t, int32(2), atomic.LoadInt32(&starts))
+}
+
+func TestRunSoloFixedHorizon(t *testing.T) {
+	var moves int32
+	srv := newTestSnake(t, func(p ResponsePayload) string {
+		atomic.AddInt32(&moves, 1)
+		return rules.MoveUp
+	})
+	o := &Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"climber"},
+		URLs:     []string{srv.URL},
+		GameType: "solo",
+		Seed:     1,
+		MaxTurns: 20,
+		Log:      new(testLog).Log,
+	}
+	res := Run(o)
+	require.Less(t, res.Turn, int32(20))
+	died := res.Snakes[0].EliminatedTurn
+	require.Equal(t, res.Turn, died)
+
+	// The snake still leaves the board at the same turn, and the game goes on without it.
+	atomic.StoreInt32(&moves, 0)
+	o.SoloFixedHorizon = true
+	res = Run(o)
+	require.Equal(t, int32(20), res.Turn)
+	require.Equal(t, rules.EliminatedByOutOfBounds, res.Snakes[0].EliminatedCause)
+	require.Equal(t, died, res.Snakes[0].EliminatedTurn)
+	require.Equal(t, EndReasonSoloEliminated, res.EndReason)
+	require.Equal(t, died, atomic.LoadInt32(&moves))
+
+	// Without a turn limit the game would never end.
+	o.MaxTurns = 0
+	require.Panics(t, func() { Run(o) })
+}
+
+// noopRuleset never changes the board and never ends.
+type noopRuleset struct {
+	rules.StandardRuleset
+}
+
+func (r *noopRuleset) CreateNextBoardState(prevState *rules.BoardState, moves []rules.SnakeMove) (*rules.BoardState, error) {
+	return prevState, nil
+}
+
+func (r *noopRuleset) IsGameOver(state *rules.BoardState) (bool, error) {
+	return false, nil
+}
+
+func TestRegisterRuleset(t *testing.T) {
+	var calls int
+	RegisterRuleset("noop", func(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+		calls++
+		return &noopRuleset{StandardRuleset: standard}
+	})
+	defer delete(rulesetRegistry, "noop")
+
+	srv := newTestSnake(t, upMove)
+	o := &Options{
+		Width:      rules.BoardSizeSmall,
+		Height:     rules.BoardSizeSmall,
+		Names:      []string{"one", "two"},
+		URLs:       []string{srv.URL, srv.URL},
+		GameType:   "noop",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   5,
+		Log:        new(testLog).Log,
+	}
+	res := Run(o)
+
+	require.Equal(t, int32(5), res.Turn)
+	require.Equal(t, EndReasonTurnLimit, res.EndReason)
+	require.Equal(t, 6, calls)
+	for _, snake := range res.Board.Snakes {
+		require.Equal(t, int32(rules.SnakeMaxHealth), snake.Health)
+		require.Equal(t, rules.NotEliminated, snake.EliminatedCause)
+	}
+}
+
+func TestRunStalemate(t *testing.T) {
+	RegisterRuleset("noop", func(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+		return &noopRuleset{StandardRuleset: standard}
+	})
+	defer delete(rulesetRegistry, "noop")
+
+	srv := newTestSnake(t, upMove)
+	l := new(testLog)
+	res := Run(&Options{
+		Width:          rules.BoardSizeSmall,
+		Height:         rules.BoardSizeSmall,
+		Names:          []string{"one", "two"},
+		URLs:           []string{srv.URL, srv.URL},
+		GameType:       "noop",
+		Sequential:     true,
+		Seed:           1,
+		StalemateTurns: 7,
+		MaxTurns:       100,
+		Log:            l.Log,
+	})
+
+	require.Equal(t, int32(7), res.Turn)
+	require.Equal(t, EndReasonStalemate, res.EndReason)
+	require.Empty(t, res.Winner)
+	require.Equal(t, 1, l.Count("It was a draw by stalemate after 7 turns without change."))
+}
+
+func TestSnakesUnchanged(t *testing.T) {
+	state := func() *rules.BoardState {
+		return &rules.BoardState{Snakes: []rules.Snake{{ID: "one", Health: 50, Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}}}}}
+	}
+	require.True(t, snakesUnchanged(state(), state()))
+
+	moved := state()
+	moved.Snakes[0].Body[0].Y = 2
+	require.False(t, snakesUnchanged(state(), moved))
+
+	hungry := state()
+	hungry.Snakes[0].Health--
+	require.False(t, snakesUnchanged(state(), hungry))
+
+	grown := state()
+	grown.Snakes[0].Body = append(grown.Snakes[0].Body, rules.Point{X: 1, Y: 0})
+	require.False(t, snakesUnchanged(state(), grown))
+}
+
+// growingRuleset is the standard ruleset with a bug that grows every snake
+// by one segment each turn, whether it ate or not.
+type growingRuleset struct {
+	rules.StandardRuleset
+}
+
+func (r *growingRuleset) CreateNextBoardState(prevState *rules.BoardState, moves []rules.SnakeMove) (*rules.BoardState, error) {
+	next, err := r.StandardRuleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+	for i := range next.Snakes {
+		body := next.Snakes[i].Body
+		next.Snakes[i].Body = append(body, body[len(body)-1])
+	}
+	return next, nil
+}
+
+func TestRunCheckGrowth(t *testing.T) {
+	RegisterRuleset("growing", func(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+		return &growingRuleset{StandardRuleset: standard}
+	})
+	defer delete(rulesetRegistry, "growing")
+
+	srv := newTestSnake(t, circleMove)
+	l := new(testLog)
+	Run(&Options{
+		Width:       rules.BoardSizeSmall,
+		Height:      rules.BoardSizeSmall,
+		Names:       []string{"solo"},
+		URLs:        []string{srv.URL},
+		GameType:    "growing",
+		Sequential:  true,
+		Seed:        1,
+		MaxTurns:    1,
+		CheckGrowth: true,
+		Log:         l.Log,
+	})
+
+	require.Equal(t, 1, l.Count("[BUG]: [1]: solo length changed from 3 to 4, expected 3"))
+}
+
+func TestRunWrappedRoyale(t *testing.T) {
+	srv := newTestSnake(t, upMove)
+	output := filepath.Join(t.TempDir(), "game.ndjson")
+	res := Run(&Options{
+		Width:    rules.BoardSizeSmall,
+		Height:   rules.BoardSizeSmall,
+		Names:    []string{"one", "two"},
+		URLs:     []string{srv.URL, srv.URL},
+		GameType: "wrapped-royale",
+		Seed:     1,
+		MaxTurns: royaleShrinkEveryNTurns + 2,
+		Output:   output,
+		Log:      new(testLog).Log,
+	})
+	// Moving up across the top edge wraps the snakes around instead of eliminating them.
+	require.Equal(t, int32(royaleShrinkEveryNTurns+2), res.Turn)
+	for _, sr := range res.Snakes {
+		require.Empty(t, sr.EliminatedCause)
+	}
+
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	_, frames, err := readRecording(f)
+	require.NoError(t, err)
+	require.Len(t, frames, royaleShrinkEveryNTurns+3)
+	for _, frame := range frames {
+		if frame.Turn < royaleShrinkEveryNTurns {
+			require.Empty(t, frame.Board.Hazards)
+		} else {
+			require.Len(t, frame.Board.Hazards, rules.BoardSizeSmall)
+		}
+	}
+}
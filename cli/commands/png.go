@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+)
+
+// snakeColors are assigned by setup order to snakes that don't report a valid color.
+var snakeColors = []string{"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4", "#42d4f4", "#f032e6", "#bfef45"}
+
+var (
+	pngEmptyColor  = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	pngHazardColor = color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}
+	pngFoodColor   = color.RGBA{R: 0xff, G: 0x5c, B: 0x75, A: 0xff}
+)
+
+func writePNG(o *Options, state *rules.BoardState, outOfBounds []rules.Point) {
+	if err := os.MkdirAll(o.PNGDir, 0755); err != nil {
+		o.Log("[WARN]: Unable to create PNG directory %v: %v", o.PNGDir, err)
+		return
+	}
+	filename := filepath.Join(o.PNGDir, fmt.Sprintf("turn-%04d.png", o.Turn))
+	f, err := os.Create(filename)
+	if err != nil {
+		o.Log("[WARN]: Unable to create PNG %v: %v", filename, err)
+		return
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, renderPNG(o, state, outOfBounds)); err != nil {
+		o.Log("[WARN]: Unable to write PNG %v: %v", filename, err)
+	}
+}
+
+func renderPNG(o *Options, state *rules.BoardState, outOfBounds []rules.Point) image.Image {
+	cell := o.PNGCell
+	if cell < 1 {
+		cell = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, int(state.Width)*cell, int(state.Height)*cell))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: pngEmptyColor}, image.Point{}, draw.Src)
+
+	fill := func(p rules.Point, c color.Color) {
+		if p.X < 0 || p.Y < 0 || p.X >= state.Width || p.Y >= state.Height {
+			return
+		}
+		// Board coordinates have y pointing up, image coordinates have y pointing down.
+		x, y := int(p.X)*cell, int(state.Height-1-p.Y)*cell
+		draw.Draw(img, image.Rect(x, y, x+cell, y+cell), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	for _, p := range outOfBounds {
+		fill(p, pngHazardColor)
+	}
+	for _, p := range state.Food {
+		fill(p, pngFoodColor)
+	}
+	// Ghosts are drawn first, so that snakes still in the game cover them.
+	for _, snake := range state.Snakes {
+		if o.GhostTurns > 0 && isGhost(o, snake) {
+			c := fade(snakeColor(o, snake))
+			for _, p := range snake.Body {
+				fill(p, c)
+			}
+		}
+	}
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		c := snakeColor(o, snake)
+		for _, p := range snake.Body {
+			fill(p, c)
+		}
+	}
+	return img
+}
+
+func snakeColor(o *Options, snake rules.Snake) color.RGBA {
+	c, ok := parseHexColor(o.Battlesnakes[snake.ID].Color)
+	if !ok {
+		c = color.RGBA{A: 0xff}
+	}
+	return c
+}
+
+// fade mixes a color a quarter of the way from the empty cell color, for ghosts.
+func fade(c color.RGBA) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8((int(a) + 3*int(b)) / 4)
+	}
+	return color.RGBA{R: mix(c.R, pngEmptyColor.R), G: mix(c.G, pngEmptyColor.G), B: mix(c.B, pngEmptyColor.B), A: 0xff}
+}
+
+// parseHexColor parses colors of the form "#rrggbb" as reported by snakes.
+func parseHexColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPNG(t *testing.T) {
+	o := &Options{
+		PNGCell: 10,
+		Battlesnakes: map[string]Battlesnake{
+			"one": {ID: "one", Color: "#ff0000"},
+		},
+	}
+	state := &rules.BoardState{
+		Width:  7,
+		Height: 5,
+		Food:   []rules.Point{{X: 6, Y: 4}},
+		Snakes: []rules.Snake{{ID: "one", Body: []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}}}},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, png.Encode(&b, renderPNG(o, state, nil)))
+	img, err := png.Decode(&b)
+	require.NoError(t, err)
+
+	require.Equal(t, 70, img.Bounds().Dx())
+	require.Equal(t, 50, img.Bounds().Dy())
+
+	// The snake is on the bottom row and the food in the top right corner.
+	require.Equal(t, color.RGBAModel.Convert(color.RGBA{R: 0xff, A: 0xff}), color.RGBAModel.Convert(img.At(15, 45)))
+	require.Equal(t, color.RGBAModel.Convert(pngFoodColor), color.RGBAModel.Convert(img.At(65, 5)))
+	require.Equal(t, color.RGBAModel.Convert(pngEmptyColor), color.RGBAModel.Convert(img.At(35, 25)))
+}
+
+func TestParseHexColor(t *testing.T) {
+	c, ok := parseHexColor("#12ab3C")
+	require.True(t, ok)
+	require.Equal(t, color.RGBA{R: 0x12, G: 0xab, B: 0x3c, A: 0xff}, c)
+
+	for _, s := range []string{"", "#fff", "#zzzzzz", "red"} {
+		_, ok := parseHexColor(s)
+		require.False(t, ok, s)
+	}
+}
+
+func TestRenderPNGGhosts(t *testing.T) {
+	o := &Options{
+		PNGCell:    1,
+		Turn:       5,
+		GhostTurns: 2,
+		Battlesnakes: map[string]Battlesnake{
+			"recent": {ID: "recent", Color: "#ff0000"},
+			"old":    {ID: "old", Color: "#0000ff"},
+		},
+		eliminatedTurns: map[string]int32{"recent": 4, "old": 3},
+	}
+	state := &rules.BoardState{
+		Width:  3,
+		Height: 1,
+		Snakes: []rules.Snake{
+			{ID: "recent", Body: []rules.Point{{X: 0, Y: 0}}, EliminatedCause: rules.EliminatedByOutOfBounds},
+			{ID: "old", Body: []rules.Point{{X: 2, Y: 0}}, EliminatedCause: rules.EliminatedByOutOfBounds},
+		},
+	}
+	img := renderPNG(o, state, nil)
+	require.Equal(t, fade(color.RGBA{R: 0xff, A: 0xff}), img.At(0, 0))
+	require.Equal(t, pngEmptyColor, img.At(2, 0))
+}
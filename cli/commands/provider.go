@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+	"github.com/google/uuid"
+)
+
+// MoveProvider chooses the moves of a snake played in-process, instead of requesting
+// them from a snake server.
+type MoveProvider interface {
+	Move(payload ResponsePayload) string
+}
+
+// randomSnake moves randomly, but deterministically for its seed, among the moves
+// that keep it on the board. With weights, each move is chosen in proportion to its weight.
+type randomSnake struct {
+	rand    *rand.Rand
+	weights map[string]int
+	// wrapped snakes can also move across the edges of the board.
+	wrapped bool
+}
+
+func (s *randomSnake) Move(p ResponsePayload) string {
+	moves := rules.LegalMoves(payloadState(p), p.You.Id)
+	if s.wrapped {
+		moves = rules.WrappedLegalMoves(payloadState(p), p.You.Id)
+	}
+	if len(moves) == 0 {
+		return rules.MoveUp
+	}
+
+	var total int
+	for _, move := range moves {
+		total += s.weights[move]
+	}
+	if total == 0 {
+		return moves[s.rand.Intn(len(moves))]
+	}
+	n := s.rand.Intn(total)
+	for _, move := range moves {
+		n -= s.weights[move]
+		if n < 0 {
+			return move
+		}
+	}
+	return moves[len(moves)-1]
+}
+
+// parseSnakeWeights parses direction=weight pairs separated by commas, such as "up=4,left=1".
+// Directions that are not listed have no weight.
+func parseSnakeWeights(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	weights := make(map[string]int)
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid weight %q", part)
+		}
+		switch kv[0] {
+		case rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight:
+		default:
+			return nil, fmt.Errorf("unknown direction %q", kv[0])
+		}
+		weight, err := strconv.Atoi(kv[1])
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q", part)
+		}
+		weights[kv[0]] = weight
+	}
+	return weights, nil
+}
+
+// payloadState returns a board state with only the requesting snake, enough to find its legal moves.
+func payloadState(p ResponsePayload) *rules.BoardState {
+	return &rules.BoardState{
+		Width:  p.Board.Width,
+		Height: p.Board.Height,
+		Snakes: []rules.Snake{{ID: p.You.Id, Health: p.You.Health, Body: pointsFromCoords(p.You.Body)}},
+	}
+}
+
+// buildRandomSnakes creates the in-process snakes requested with --random-snakes,
+// following on from the snakes built from names and URLs.
+func buildRandomSnakes(o *Options, offset int) []Battlesnake {
+	var snakes []Battlesnake
+	weights, _ := parseSnakeWeights(o.SnakeWeights)
+	for i := offset; i < offset+o.RandomSnakes; i++ {
+		snake := Battlesnake{
+			Name:      fmt.Sprintf("Random%d", i-offset+1),
+			ID:        uuid.New().String(),
+			LastMove:  "up",
+			Character: bodyChars[i%8],
+			Color:     snakeColors[i%len(snakeColors)],
+			Provider:  &randomSnake{rand: rand.New(rand.NewSource(moveSeed(o, i))), weights: weights, wrapped: isWrapped(o.GameType)},
+		}
+		if o.GameType == "squad" {
+			snake.Squad = strconv.Itoa(i / 2)
+		}
+		snakes = append(snakes, snake)
+	}
+	return snakes
+}
+
+// moveSeed seeds the decisions of the built-in snake at index i, so that they are reproducible
+// for the game seed. With --move-seed, the decisions can be varied while the board stays the same.
+func moveSeed(o *Options, i int) int64 {
+	if o.MoveSeed == 0 {
+		return o.Seed + int64(i)
+	}
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, []int64{o.MoveSeed, o.Seed, int64(i)})
+	return int64(h.Sum64())
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRandomSnakes(t *testing.T) {
+	run := func() Result {
+		return Run(&Options{
+			Width:        rules.BoardSizeMedium,
+			Height:       rules.BoardSizeMedium,
+			GameType:     "standard",
+			Seed:         7,
+			RandomSnakes: 4,
+			Log:          new(testLog).Log,
+		})
+	}
+
+	res := run()
+	require.Len(t, res.Snakes, 4)
+	require.Contains(t, []EndReason{EndReasonLastSnakeStanding, EndReasonAllEliminated}, res.EndReason)
+	if res.EndReason == EndReasonLastSnakeStanding {
+		require.Contains(t, []string{"Random1", "Random2", "Random3", "Random4"}, res.Winner)
+	} else {
+		require.Empty(t, res.Winner)
+	}
+
+	// Random snakes are deterministic for the seed.
+	again := run()
+	require.Equal(t, res.Turn, again.Turn)
+	require.Equal(t, res.Winner, again.Winner)
+	require.Equal(t, res.Snakes, again.Snakes)
+}
+
+func TestRandomSnakeLegalMoves(t *testing.T) {
+	o := &Options{Seed: 1, RandomSnakes: 1}
+	snake := buildRandomSnakes(o, 0)[0]
+	you := SnakeResponse{Id: "you", Health: 100, Head: Coord{0, 0}, Body: []Coord{{0, 0}, {1, 0}, {2, 0}}}
+	p := ResponsePayload{Board: BoardResponse{Width: 3, Height: 3, Snakes: []SnakeResponse{you}}, You: you}
+	for i := 0; i < 20; i++ {
+		require.Equal(t, rules.MoveUp, snake.Provider.Move(p))
+	}
+}
+
+func TestRandomSnakeWrappedLegalMoves(t *testing.T) {
+	o := &Options{Seed: 1, RandomSnakes: 1, GameType: "wrapped"}
+	snake := buildRandomSnakes(o, 0)[0]
+	you := SnakeResponse{Id: "you", Health: 100, Head: Coord{0, 0}, Body: []Coord{{0, 0}, {1, 0}, {2, 0}}}
+	p := ResponsePayload{Board: BoardResponse{Width: 3, Height: 3, Snakes: []SnakeResponse{you}}, You: you}
+	moves := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		moves[snake.Provider.Move(p)] = true
+	}
+	require.Equal(t, map[string]bool{rules.MoveUp: true, rules.MoveDown: true, rules.MoveLeft: true}, moves)
+}
+
+func TestRunBatchMoveSeed(t *testing.T) {
+	run := func(seed int64) []Result {
+		return RunBatch(&Options{
+			Width:        rules.BoardSizeSmall,
+			Height:       rules.BoardSizeSmall,
+			GameType:     "standard",
+			Seed:         3,
+			MoveSeed:     seed,
+			Games:        4,
+			RandomSnakes: 3,
+			Log:          new(testLog).Log,
+		})
+	}
+	summarize := func(results []Result) []interface{} {
+		var summary []interface{}
+		for _, res := range results {
+			summary = append(summary, res.Turn, res.Winner, res.Snakes)
+		}
+		return summary
+	}
+
+	require.Equal(t, summarize(run(42)), summarize(run(42)))
+	require.NotEqual(t, summarize(run(42)), summarize(run(43)))
+}
+
+func TestMoveSeed(t *testing.T) {
+	require.Equal(t, int64(12), moveSeed(&Options{Seed: 10}, 2))
+
+	// Unlike the default, snakes of consecutive games don't share seeds.
+	o := &Options{Seed: 10, MoveSeed: 1}
+	next := &Options{Seed: 11, MoveSeed: 1}
+	require.NotEqual(t, moveSeed(o, 1), moveSeed(next, 0))
+	require.Equal(t, moveSeed(o, 1), moveSeed(&Options{Seed: 10, MoveSeed: 1}, 1))
+}
+
+func TestRandomSnakeWeights(t *testing.T) {
+	o := &Options{Seed: 1, RandomSnakes: 1, SnakeWeights: "up=4,down=1,left=2,right=3"}
+	snake := buildRandomSnakes(o, 0)[0]
+	you := SnakeResponse{Id: "you", Health: 100, Head: Coord{5, 5}, Body: []Coord{{5, 5}}}
+	p := ResponsePayload{Board: BoardResponse{Width: 11, Height: 11, Snakes: []SnakeResponse{you}}, You: you}
+
+	const n = 10000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[snake.Provider.Move(p)]++
+	}
+	require.InDelta(t, 0.4, float64(counts[rules.MoveUp])/n, 0.02)
+	require.InDelta(t, 0.1, float64(counts[rules.MoveDown])/n, 0.02)
+	require.InDelta(t, 0.2, float64(counts[rules.MoveLeft])/n, 0.02)
+	require.InDelta(t, 0.3, float64(counts[rules.MoveRight])/n, 0.02)
+
+	// Unlisted directions are never chosen while a weighted move is legal.
+	o.SnakeWeights = "left=1"
+	snake = buildRandomSnakes(o, 0)[0]
+	for i := 0; i < 20; i++ {
+		require.Equal(t, rules.MoveLeft, snake.Provider.Move(p))
+	}
+}
+
+func TestParseSnakeWeights(t *testing.T) {
+	weights, err := parseSnakeWeights("up=4, left=0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{rules.MoveUp: 4, rules.MoveLeft: 0}, weights)
+
+	_, err = parseSnakeWeights("north=1")
+	require.EqualError(t, err, `unknown direction "north"`)
+	_, err = parseSnakeWeights("up=-1")
+	require.EqualError(t, err, `invalid weight "up=-1"`)
+	_, err = parseSnakeWeights("up")
+	require.EqualError(t, err, `invalid weight "up"`)
+}
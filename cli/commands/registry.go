@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"github.com/corverroos/bsrules"
+)
+
+// RulesetConstructor builds the ruleset for a game type. It is called every turn,
+// with the standard ruleset configured from the options.
+type RulesetConstructor func(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset
+
+var rulesetRegistry = map[string]RulesetConstructor{
+	"standard":       newStandardRuleset,
+	"royale":         newRoyaleRuleset,
+	"squad":          newSquadRuleset,
+	"solo":           newSoloRuleset,
+	"constrictor":    newConstrictorRuleset,
+	"wrapped":        newWrappedRuleset,
+	"walls":          newHazardWallsRuleset,
+	"wrapped-royale": newWrappedRoyaleRuleset,
+}
+
+// RegisterRuleset makes a ruleset available as the given game type, replacing any
+// existing ruleset with that name. It should be called before any games are played.
+func RegisterRuleset(name string, ctor RulesetConstructor) {
+	rulesetRegistry[name] = ctor
+}
+
+const royaleShrinkEveryNTurns = 20
+
+// isWrapped reports whether snakes move across the edges of the board in a game type.
+func isWrapped(gameType string) bool {
+	return gameType == "wrapped" || gameType == "wrapped-royale"
+}
+
+func newStandardRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	return &standard
+}
+
+func newRoyaleRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	shrinkEveryNTurns := int32(royaleShrinkEveryNTurns)
+	if o.AutoScale {
+		_, shrinkEveryNTurns = autoScale(o.Width, o.Height, standard.MinimumFood, shrinkEveryNTurns)
+	}
+	return &rules.RoyaleRuleset{
+		StandardRuleset:   standard,
+		Seed:              boardSeed(o),
+		Turn:              o.Turn,
+		ShrinkEveryNTurns: shrinkEveryNTurns,
+		DamagePerTurn:     15,
+	}
+}
+
+func newWrappedRoyaleRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	royale := newRoyaleRuleset(o, standard, snakes).(*rules.RoyaleRuleset)
+	return &rules.WrappedRoyaleRuleset{RoyaleRuleset: *royale}
+}
+
+func newSquadRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	return &rules.SquadRuleset{
+		StandardRuleset:     standard,
+		SquadMap:            buildSquadMap(snakes),
+		AllowBodyCollisions: enabled(o.SquadBodyCollisions),
+		SharedElimination:   enabled(o.SquadSharedElimination),
+		SharedHealth:        enabled(o.SquadSharedHealth),
+		SharedLength:        enabled(o.SquadSharedLength),
+	}
+}
+
+// enabled reports whether an opt-out option is on, which it is unless set to false.
+func enabled(b *bool) bool {
+	return b == nil || *b
+}
+
+func newSoloRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	return &rules.SoloRuleset{
+		StandardRuleset: standard,
+	}
+}
+
+func newConstrictorRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	return &rules.ConstrictorRuleset{
+		StandardRuleset: standard,
+	}
+}
+
+func newWrappedRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	return &rules.WrappedRuleset{
+		StandardRuleset: standard,
+	}
+}
+
+func newHazardWallsRuleset(o *Options, standard rules.StandardRuleset, snakes []Battlesnake) rules.Ruleset {
+	wallDamage := o.WallDamage
+	if wallDamage == 0 {
+		wallDamage = rules.SnakeMaxHealth
+	}
+	return &rules.HazardWallsRuleset{
+		StandardRuleset: standard,
+		Walls:           o.Walls,
+		WallDamage:      wallDamage,
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRulesetUnknownGameType(t *testing.T) {
+	ruleset, _ := getRuleset(&Options{GameType: "unknown"}, nil)
+	require.IsType(t, &rules.StandardRuleset{}, ruleset)
+}
+
+func TestGetRulesetSquadOptions(t *testing.T) {
+	snakes := []Battlesnake{{ID: "a1", Squad: "a"}, {ID: "a2", Squad: "a"}, {ID: "b1", Squad: "b"}}
+	state := &rules.BoardState{
+		Width:  rules.BoardSizeSmall,
+		Height: rules.BoardSizeSmall,
+		Snakes: []rules.Snake{
+			{ID: "a1", Health: 20, Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 0}}},
+			{ID: "a2", Health: 90, Body: []rules.Point{{X: 3, Y: 1}, {X: 3, Y: 0}, {X: 3, Y: 0}}},
+			{ID: "b1", Health: 90, Body: []rules.Point{{X: 5, Y: 1}, {X: 5, Y: 0}, {X: 5, Y: 0}}},
+		},
+	}
+	moves := []rules.SnakeMove{{ID: "a1", Move: rules.MoveUp}, {ID: "a2", Move: rules.MoveUp}, {ID: "b1", Move: rules.MoveUp}}
+	// A zero Options plays with every squad rule.
+	o := &Options{GameType: "squad"}
+
+	ruleset, _ := getRuleset(o, snakes)
+	next, err := ruleset.CreateNextBoardState(state, moves)
+	require.NoError(t, err)
+	require.Equal(t, int32(89), next.Snakes[0].Health)
+	require.Equal(t, int32(89), next.Snakes[1].Health)
+
+	off := false
+	o.SquadSharedHealth = &off
+	ruleset, _ = getRuleset(o, snakes)
+	squad := ruleset.(*rules.SquadRuleset)
+	require.False(t, squad.SharedHealth)
+	require.True(t, squad.SharedElimination)
+	next, err = ruleset.CreateNextBoardState(state, moves)
+	require.NoError(t, err)
+	require.Equal(t, int32(19), next.Snakes[0].Health)
+	require.Equal(t, int32(89), next.Snakes[1].Health)
+}
+
+func TestGetRulesetWrappedRoyale(t *testing.T) {
+	ruleset, royale := getRuleset(&Options{GameType: "wrapped-royale", Seed: 1, Turn: 3}, nil)
+	require.IsType(t, &rules.WrappedRoyaleRuleset{}, ruleset)
+	require.Equal(t, int32(3), royale.Turn)
+	require.Equal(t, int32(royaleShrinkEveryNTurns), royale.ShrinkEveryNTurns)
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMapWrappedSeam(t *testing.T) {
+	o := &Options{
+		GameType: "wrapped",
+		Battlesnakes: map[string]Battlesnake{
+			"one": {ID: "one", Name: "one", Character: '■'},
+		},
+	}
+	prev := &rules.BoardState{
+		Width:  4,
+		Height: 3,
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 100, Body: []rules.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}},
+		},
+	}
+
+	ruleset, _ := getRuleset(o, nil)
+	state, err := ruleset.CreateNextBoardState(prev, []rules.SnakeMove{{ID: "one", Move: rules.MoveLeft}})
+	require.NoError(t, err)
+	state.Food = nil
+
+	rendered := renderMap(o, state, nil)
+	require.Contains(t, rendered, "\n◦◦◦◦\n■■◦■\n◦◦◦◦\n")
+}
+
+func TestCompactStateLine(t *testing.T) {
+	o := &Options{
+		Turn: 12,
+		Battlesnakes: map[string]Battlesnake{
+			"one":   {ID: "one", Name: "Snake1"},
+			"two":   {ID: "two", Name: "Snake2"},
+			"three": {ID: "three", Name: "Snake3"},
+		},
+	}
+	state := &rules.BoardState{
+		Width:  11,
+		Height: 11,
+		Food:   []rules.Point{{X: 1, Y: 1}, {X: 2, Y: 2}},
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 88, Body: []rules.Point{{X: 3, Y: 4}, {X: 3, Y: 3}, {X: 3, Y: 2}}},
+			{ID: "two", Health: 5, Body: []rules.Point{{X: 0, Y: 0}}, EliminatedCause: rules.EliminatedByOutOfHealth},
+			{ID: "three", Health: 100, Body: []rules.Point{{X: 9, Y: 10}, {X: 9, Y: 9}, {X: 9, Y: 8}, {X: 9, Y: 8}}},
+		},
+	}
+	hazards := []rules.Point{{X: 0, Y: 10}}
+
+	require.Equal(t,
+		"[12]: Alive: 2 Food: 2 Hazards: 1 | Snake1 (3,4) len=3 hp=88 | Snake3 (9,10) len=4 hp=100",
+		compactStateLine(o, state, hazards))
+
+	state.Snakes = nil
+	require.Equal(t, "[12]: Alive: 0 Food: 2 Hazards: 0", compactStateLine(o, state, nil))
+}
+
+func TestRenderMapHeads(t *testing.T) {
+	o := &Options{
+		GameType: "standard",
+		Battlesnakes: map[string]Battlesnake{
+			"one":   {ID: "one", Name: "one", Character: '■'},
+			"two":   {ID: "two", Name: "two", Character: '⌀'},
+			"three": {ID: "three", Name: "three", Character: '●'},
+		},
+	}
+	state := &rules.BoardState{
+		Width:  4,
+		Height: 3,
+		Snakes: []rules.Snake{
+			{ID: "one", Health: 100, Body: []rules.Point{{X: 0, Y: 2}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+			{ID: "two", Health: 100, Body: []rules.Point{{X: 2, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 0}}},
+			{ID: "three", Health: 100, Body: []rules.Point{{X: 2, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 2}}},
+		},
+	}
+
+	require.Contains(t, renderMap(o, state, nil), "\n■◦●●\n■◦●⌀\n■◦◦⌀\n")
+
+	o.RenderHeads = true
+	require.Contains(t, renderMap(o, state, nil), "\n▣◦●●\n■◦✖⌀\n■◦◦⌀\n")
+
+	state.Snakes = state.Snakes[:2]
+	require.Contains(t, renderMap(o, state, nil), "\n▣◦◦◦\n■◦⊘⌀\n■◦◦⌀\n")
+}
+
+func TestRunGhostTurns(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	l := new(testLog)
+	res := Run(&Options{
+		Width:      rules.BoardSizeMedium,
+		Height:     rules.BoardSizeMedium,
+		Names:      []string{"square", "up"},
+		URLs:       []string{square.URL, up.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		MaxTurns:   15,
+		ViewMap:    true,
+		GhostTurns: 3,
+		Log:        l.Log,
+	})
+
+	var died int32
+	for _, sr := range res.Snakes {
+		if sr.Name == "up" {
+			died = sr.EliminatedTurn
+		}
+	}
+	require.NotZero(t, died)
+	require.Greater(t, res.Turn, died+3)
+
+	ghosts := make(map[int32]bool)
+	for _, line := range l.lines {
+		if !strings.HasPrefix(line, "Ruleset: ") {
+			continue
+		}
+		var turn int32
+		_, err := fmt.Sscanf(line[strings.Index(line, "Turn: "):], "Turn: %d", &turn)
+		require.NoError(t, err)
+		ghosts[turn] = strings.ContainsRune(line, ghostChar)
+	}
+	for turn := int32(1); turn <= res.Turn; turn++ {
+		require.Equal(t, turn >= died && turn < died+3, ghosts[turn], "turn %v", turn)
+	}
+}
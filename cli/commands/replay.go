@@ -0,0 +1,331 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+
+	"github.com/corverroos/bsrules"
+	"github.com/spf13/cobra"
+)
+
+// Frame is a single turn of a recorded game. Games are recorded as NDJSON, one frame per line.
+// Like the API payload, the board only contains snakes that have not been eliminated.
+type Frame struct {
+	Turn  int32         `json:"turn"`
+	Board BoardResponse `json:"board"`
+}
+
+// Header is the first line of a recorded game, describing how it was played.
+// It is written as {"header": {...}} so it can't be mistaken for a frame.
+type Header struct {
+	GameType  string          `json:"game_type"`
+	Seed      int64           `json:"seed"`
+	BoardSeed int64           `json:"board_seed,omitempty"`
+	Width     int32           `json:"width"`
+	Height    int32           `json:"height"`
+	Timeout   int32           `json:"timeout"`
+	Ruleset   json.RawMessage `json:"ruleset"`
+	Rules     HeaderRules     `json:"rules"`
+	Snakes    []HeaderSnake   `json:"snakes"`
+}
+
+// HeaderRules are the options that change how moves are applied, beyond the game type, seeds
+// and board size, which are needed to reconstruct a game from its moves.
+type HeaderRules struct {
+	AutoScale              bool   `json:"auto_scale,omitempty"`
+	AllowBodyCollisions    bool   `json:"allow_body_collisions,omitempty"`
+	EliminateTrapped       bool   `json:"eliminate_trapped,omitempty"`
+	HealthDecay            int32  `json:"health_decay,omitempty"`
+	FoodPerSpawn           int32  `json:"food_per_spawn,omitempty"`
+	FoodChance             int32  `json:"food_chance,omitempty"`
+	FoodSchedule           string `json:"food_schedule,omitempty"`
+	MaxFood                int32  `json:"max_food,omitempty"`
+	HazardPattern          string `json:"hazard_pattern,omitempty"`
+	HazardGrowth           int32  `json:"hazard_growth,omitempty"`
+	WallDamage             int32  `json:"wall_damage,omitempty"`
+	SquadBodyCollisions    *bool  `json:"squad_body_collisions,omitempty"`
+	SquadSharedElimination *bool  `json:"squad_shared_elimination,omitempty"`
+	SquadSharedHealth      *bool  `json:"squad_shared_health,omitempty"`
+	SquadSharedLength      *bool  `json:"squad_shared_length,omitempty"`
+}
+
+type HeaderSnake struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Squad   string `json:"squad,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type headerLine struct {
+	Header *Header `json:"header"`
+}
+
+type ReplayOptions struct {
+	Path            string
+	UntilEliminated string
+	Log             func(string, ...interface{})
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Replay a recorded game of Battlesnake.",
+	Long:  "Replay a game of Battlesnake recorded with the play --output or --export-moves-only flags.",
+	Args:  cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	var o ReplayOptions
+
+	replayCmd.Flags().StringVar(&o.UntilEliminated, "until-eliminated", "", "Stop Replaying at the Turn the Named Snake is Eliminated")
+
+	replayCmd.Run = func(cmd *cobra.Command, args []string) {
+		o.Path = args[0]
+		if err := Replay(&o); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func Replay(o *ReplayOptions) error {
+	if o.Log == nil {
+		o.Log = log.Printf
+	}
+
+	data, err := ioutil.ReadFile(o.Path)
+	if err != nil {
+		return err
+	}
+
+	var frames []Frame
+	if isMovesOnlyRecording(data) {
+		// The rules the game was played with are restored from the recording's header.
+		_, frames, err = ReconstructFrames(&Options{Log: o.Log}, bytes.NewReader(data))
+	} else {
+		frames, err = readFrames(bytes.NewReader(data))
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.UntilEliminated != "" {
+		turn, ok := eliminationTurn(frames, o.UntilEliminated)
+		if !ok {
+			return fmt.Errorf("snake %v is not eliminated in %v", o.UntilEliminated, o.Path)
+		}
+		frames = framesUntilTurn(frames, turn)
+	}
+
+	chars := frameCharacters(frames)
+	for _, frame := range frames {
+		o.Log("%s", renderFrame(frame, chars))
+	}
+	return nil
+}
+
+func buildFrame(o *Options, state *rules.BoardState, outOfBounds []rules.Point) Frame {
+	var alive []rules.Snake
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			alive = append(alive, snake)
+		}
+	}
+	return Frame{
+		Turn: o.Turn,
+		Board: BoardResponse{
+			Height:  state.Height,
+			Width:   state.Width,
+			Food:    coordFromPointArray(state.Food),
+			Hazards: coordFromPointArray(outOfBounds),
+			Snakes:  buildSnakesResponse(o, alive),
+		},
+	}
+}
+
+// buildHeader describes the game with the effective settings of its ruleset.
+func buildHeader(o *Options, ruleset rules.Ruleset, snakes []Battlesnake) Header {
+	header := Header{
+		GameType:  o.GameType,
+		Seed:      o.Seed,
+		BoardSeed: o.BoardSeed,
+		Width:     o.Width,
+		Height:    o.Height,
+		Timeout:   o.Timeout,
+		Rules: HeaderRules{
+			AutoScale:              o.AutoScale,
+			AllowBodyCollisions:    o.AllowBodyCollisions,
+			EliminateTrapped:       o.EliminateTrapped,
+			HealthDecay:            o.HealthDecay,
+			FoodPerSpawn:           o.FoodPerSpawn,
+			FoodChance:             o.FoodChance,
+			FoodSchedule:           o.FoodSchedule,
+			MaxFood:                o.MaxFood,
+			HazardPattern:          o.HazardPattern,
+			HazardGrowth:           o.HazardGrowth,
+			WallDamage:             o.WallDamage,
+			SquadBodyCollisions:    o.SquadBodyCollisions,
+			SquadSharedElimination: o.SquadSharedElimination,
+			SquadSharedHealth:      o.SquadSharedHealth,
+			SquadSharedLength:      o.SquadSharedLength,
+		},
+	}
+	if b, err := json.Marshal(ruleset); err == nil {
+		header.Ruleset = b
+	}
+	for _, snake := range snakes {
+		header.Snakes = append(header.Snakes, HeaderSnake{
+			ID:      snake.ID,
+			Name:    snake.Name,
+			URL:     snake.URL,
+			Squad:   snake.Squad,
+			Version: snake.Version,
+		})
+	}
+	return header
+}
+
+// applyHeaderRules sets the rule options of o to those a game was recorded with.
+func applyHeaderRules(o *Options, hr HeaderRules) {
+	o.AutoScale = hr.AutoScale
+	o.AllowBodyCollisions = hr.AllowBodyCollisions
+	o.EliminateTrapped = hr.EliminateTrapped
+	o.HealthDecay = hr.HealthDecay
+	o.FoodPerSpawn = hr.FoodPerSpawn
+	o.FoodChance = hr.FoodChance
+	o.FoodSchedule = hr.FoodSchedule
+	o.MaxFood = hr.MaxFood
+	o.HazardPattern = hr.HazardPattern
+	o.HazardGrowth = hr.HazardGrowth
+	o.WallDamage = hr.WallDamage
+	o.SquadBodyCollisions = hr.SquadBodyCollisions
+	o.SquadSharedElimination = hr.SquadSharedElimination
+	o.SquadSharedHealth = hr.SquadSharedHealth
+	o.SquadSharedLength = hr.SquadSharedLength
+}
+
+func writeHeader(w io.Writer, header Header) error {
+	return json.NewEncoder(w).Encode(headerLine{Header: &header})
+}
+
+func writeFrame(w io.Writer, frame Frame) error {
+	return json.NewEncoder(w).Encode(frame)
+}
+
+func readFrames(r io.Reader) ([]Frame, error) {
+	_, frames, err := readRecording(r)
+	return frames, err
+}
+
+// readRecording reads the frames of a recorded game, and its header if it has one.
+// The output format is detected from the content.
+func readRecording(r io.Reader) (*Header, []Frame, error) {
+	r, isMsgpack, err := detectRecording(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isMsgpack {
+		return readMsgpackRecording(r)
+	}
+
+	var header *Header
+	var frames []Frame
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if header == nil && len(frames) == 0 {
+			var line headerLine
+			if err := json.Unmarshal(scanner.Bytes(), &line); err == nil && line.Header != nil {
+				header = line.Header
+				continue
+			}
+		}
+		var frame Frame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return header, frames, scanner.Err()
+}
+
+// eliminationTurn returns the turn of the first frame in which the named snake
+// no longer appears on the board, after having appeared in an earlier frame.
+func eliminationTurn(frames []Frame, name string) (int32, bool) {
+	var seen bool
+	for _, frame := range frames {
+		var found bool
+		for _, snake := range frame.Board.Snakes {
+			if snake.Name == name {
+				found = true
+				break
+			}
+		}
+		if found {
+			seen = true
+		} else if seen {
+			return frame.Turn, true
+		}
+	}
+	return 0, false
+}
+
+func framesUntilTurn(frames []Frame, turn int32) []Frame {
+	var res []Frame
+	for _, frame := range frames {
+		if frame.Turn > turn {
+			break
+		}
+		res = append(res, frame)
+	}
+	return res
+}
+
+// frameCharacters assigns body characters by order of first appearance, matching play.
+func frameCharacters(frames []Frame) map[string]rune {
+	chars := make(map[string]rune)
+	for _, frame := range frames {
+		for _, snake := range frame.Board.Snakes {
+			if _, ok := chars[snake.Id]; !ok {
+				chars[snake.Id] = bodyChars[len(chars)%len(bodyChars)]
+			}
+		}
+	}
+	return chars
+}
+
+func renderFrame(frame Frame, chars map[string]rune) string {
+	var b bytes.Buffer
+	b.WriteString(fmt.Sprintf("Turn: %v\n", frame.Turn))
+	board := newBoardGrid(frame.Board.Width, frame.Board.Height, pointsFromCoords(frame.Board.Hazards), pointsFromCoords(frame.Board.Food))
+	b.WriteString(fmt.Sprintf("Hazards ░: %v\n", frame.Board.Hazards))
+	b.WriteString(fmt.Sprintf("Food ⚕: %v\n", frame.Board.Food))
+	for _, s := range frame.Board.Snakes {
+		for _, c := range s.Body {
+			if c.X < 0 || c.Y < 0 || c.X >= frame.Board.Width || c.Y >= frame.Board.Height {
+				continue
+			}
+			board[c.X][c.Y] = chars[s.Id]
+		}
+		b.WriteString(fmt.Sprintf("%v %c: health %v, length %v\n", s.Name, chars[s.Id], s.Health, s.Length))
+	}
+	writeBoardGrid(&b, board)
+	return b.String()
+}
+
+func pointsFromCoords(coords []Coord) []rules.Point {
+	a := make([]rules.Point, 0, len(coords))
+	for _, c := range coords {
+		a = append(a, rules.Point{X: c.X, Y: c.Y})
+	}
+	return a
+}
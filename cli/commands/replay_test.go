@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFrames(t *testing.T, frames []Frame) string {
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "game.ndjson")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	for _, frame := range frames {
+		require.NoError(t, writeFrame(f, frame))
+	}
+	return path
+}
+
+func testFrame(turn int32, names ...string) Frame {
+	frame := Frame{Turn: turn, Board: BoardResponse{Width: 5, Height: 5}}
+	for i, name := range names {
+		body := []Coord{{X: int32(i), Y: 0}}
+		frame.Board.Snakes = append(frame.Board.Snakes, SnakeResponse{Id: name, Name: name, Body: body, Head: body[0], Length: 1})
+	}
+	return frame
+}
+
+func TestReplayUntilEliminated(t *testing.T) {
+	path := writeTestFrames(t, []Frame{
+		testFrame(0, "one", "two", "three"),
+		testFrame(1, "one", "two", "three"),
+		testFrame(2, "one", "three"),
+		testFrame(3, "one"),
+		testFrame(4),
+	})
+
+	tests := []struct {
+		Name     string
+		LastTurn int32
+	}{
+		{"two", 2},
+		{"three", 3},
+		{"one", 4},
+	}
+	for _, test := range tests {
+		l := new(testLog)
+		err := Replay(&ReplayOptions{Path: path, UntilEliminated: test.Name, Log: l.Log})
+		require.NoError(t, err)
+		require.Len(t, l.lines, int(test.LastTurn)+1, test.Name)
+		require.Equal(t, 1, l.Count("Turn: 0\n"))
+		require.Equal(t, 1, l.Count(fmt.Sprintf("Turn: %v\n", test.LastTurn)))
+	}
+
+	err := Replay(&ReplayOptions{Path: path, UntilEliminated: "four", Log: new(testLog).Log})
+	require.Error(t, err)
+}
+
+func TestRunRecordsFrames(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	path := writeTestFrames(t, nil)
+
+	o := &Options{
+		Width:      2,
+		Height:     2,
+		Names:      []string{"starver"},
+		URLs:       []string{srv.URL},
+		GameType:   "solo",
+		Sequential: true,
+		Seed:       1,
+		Output:     path,
+		Log:        new(testLog).Log,
+	}
+	res := Run(o)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	frames, err := readFrames(f)
+	require.NoError(t, err)
+	require.Len(t, frames, int(res.Turn)+1)
+	for i, frame := range frames {
+		require.Equal(t, int32(i), frame.Turn)
+	}
+
+	turn, ok := eliminationTurn(frames, "starver")
+	require.True(t, ok)
+	require.Equal(t, res.Turn, turn)
+	require.Equal(t, rules.EliminatedByOutOfHealth, res.Board.Snakes[0].EliminatedCause)
+}
+
+func TestRunRecordsHeader(t *testing.T) {
+	one, two := newTestSnake(t, squareMove), newTestSnake(t, upMove)
+	path := writeTestFrames(t, nil)
+
+	res := Run(&Options{
+		Width:      rules.BoardSizeMedium,
+		Height:     rules.BoardSizeMedium,
+		Names:      []string{"one", "two"},
+		URLs:       []string{one.URL, two.URL},
+		GameType:   "royale",
+		Sequential: true,
+		Seed:       5,
+		Output:     path,
+		Log:        new(testLog).Log,
+	})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	header, frames, err := readRecording(f)
+	require.NoError(t, err)
+	require.Len(t, frames, int(res.Turn)+1)
+
+	require.NotNil(t, header)
+	require.Equal(t, "royale", header.GameType)
+	require.Equal(t, int64(5), header.Seed)
+	require.Equal(t, int32(rules.BoardSizeMedium), header.Width)
+	require.Len(t, header.Snakes, 2)
+	require.Equal(t, "one", header.Snakes[0].Name)
+	require.Equal(t, one.URL, header.Snakes[0].URL)
+	require.Equal(t, "two", header.Snakes[1].Name)
+	require.Equal(t, two.URL, header.Snakes[1].URL)
+	require.Equal(t, frames[0].Board.Snakes[0].Id, header.Snakes[0].ID)
+
+	var royale rules.RoyaleRuleset
+	require.NoError(t, json.Unmarshal(header.Ruleset, &royale))
+	require.Equal(t, int32(20), royale.ShrinkEveryNTurns)
+	require.Equal(t, int32(1), royale.MinimumFood)
+
+	// Recordings without a header can still be read.
+	path = writeTestFrames(t, frames)
+	f, err = os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	header, withoutHeader, err := readRecording(f)
+	require.NoError(t, err)
+	require.Nil(t, header)
+	require.Equal(t, frames, withoutHeader)
+}
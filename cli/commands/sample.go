@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/corverroos/bsrules"
+	"github.com/spf13/cobra"
+)
+
+var sampleCmd = &cobra.Command{
+	Use:   "serve-sample",
+	Short: "Serve a built-in sample Battlesnake.",
+	Long:  "Serve a simple built-in Battlesnake that avoids walls and bodies, for testing games without a separate snake.",
+}
+
+func init() {
+	rootCmd.AddCommand(sampleCmd)
+
+	var port int
+
+	sampleCmd.Flags().IntVarP(&port, "port", "p", 8000, "Port to Listen on")
+
+	sampleCmd.Run = func(cmd *cobra.Command, args []string) {
+		addr := fmt.Sprintf(":%d", port)
+		log.Printf("Sample snake listening on %v", addr)
+		log.Fatal(http.ListenAndServe(addr, SampleHandler()))
+	}
+}
+
+// SampleHandler returns the handler of the built-in sample snake.
+func SampleHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, PingResponse{APIVersion: "1", Author: "battlesnake-cli", Color: "#888888", Head: "default", Tail: "default", Version: "sample"})
+	})
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/move", func(w http.ResponseWriter, r *http.Request) {
+		var payload ResponsePayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, PlayerResponse{Move: sampleMove(payload)})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// sampleMove picks the first move that stays on the board and out of every snake's body,
+// falling back to any move that stays on the board.
+func sampleMove(p ResponsePayload) string {
+	occupied := make(map[rules.Point]bool)
+	for _, s := range p.Board.Snakes {
+		body := pointsFromCoords(s.Body)
+		// Tails move out of the way, unless the snake just ate.
+		if len(body) > 1 && body[len(body)-1] != body[len(body)-2] {
+			body = body[:len(body)-1]
+		}
+		for _, point := range body {
+			occupied[point] = true
+		}
+	}
+
+	moves := rules.LegalMoves(payloadState(p), p.You.Id)
+	if len(moves) == 0 {
+		return rules.MoveUp
+	}
+	head := rules.Point{X: p.You.Head.X, Y: p.You.Head.Y}
+	for _, move := range moves {
+		if !occupied[nextPoint(head, move)] {
+			return move
+		}
+	}
+	return moves[0]
+}
+
+func nextPoint(p rules.Point, move string) rules.Point {
+	dx, dy, _ := rules.DirectionVector(move)
+	return rules.Point{X: p.X + dx, Y: p.Y + dy}
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleHandlerMove(t *testing.T) {
+	srv := httptest.NewServer(SampleHandler())
+	defer srv.Close()
+
+	// Down leaves the board, right is the neck and up runs into the other snake.
+	you := SnakeResponse{Id: "you", Health: 90, Head: Coord{1, 0}, Body: []Coord{{1, 0}, {2, 0}, {3, 0}}, Length: 3}
+	other := SnakeResponse{Id: "other", Health: 90, Head: Coord{1, 2}, Body: []Coord{{1, 2}, {1, 1}, {2, 1}}, Length: 3}
+	payload := ResponsePayload{
+		Turn:  5,
+		Board: BoardResponse{Width: 5, Height: 5, Snakes: []SnakeResponse{you, other}},
+		You:   you,
+	}
+
+	move := func(p ResponsePayload) string {
+		b, err := json.Marshal(p)
+		require.NoError(t, err)
+		res, err := http.Post(srv.URL+"/move", "application/json", bytes.NewReader(b))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var pr PlayerResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+		return pr.Move
+	}
+
+	require.Equal(t, rules.MoveLeft, move(payload))
+
+	// The other snake's tail moves out of the way.
+	other.Body = []Coord{{1, 2}, {2, 2}, {2, 1}, {1, 1}}
+	payload.Board.Snakes = []SnakeResponse{you, other}
+	require.Equal(t, rules.MoveUp, move(payload))
+
+	you.Head, you.Body = Coord{2, 2}, []Coord{{2, 2}, {2, 3}, {2, 4}}
+	payload.You, payload.Board.Snakes = you, []SnakeResponse{you}
+	m := move(payload)
+	state := &rules.BoardState{Width: 5, Height: 5, Snakes: []rules.Snake{{ID: "you", Body: pointsFromCoords(you.Body)}}}
+	require.Contains(t, rules.LegalMoves(state, "you"), m)
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/corverroos/bsrules"
+)
+
+// loadInitialState reads a board state from a JSON frame, as written by --output, and assigns
+// the IDs of the snakes in the frame to the given snakes in order. Bodies of games whose board
+// wraps may cross its edges.
+func loadInitialState(filename string, gameType string, snakes []Battlesnake) (*rules.BoardState, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var frame Frame
+	if err := json.Unmarshal(b, &frame); err != nil {
+		return nil, fmt.Errorf("invalid initial state %v: %v", filename, err)
+	}
+
+	for _, s := range frame.Board.Snakes {
+		if err := validateSnakeBody(s, frame.Board.Width, frame.Board.Height, isWrapped(gameType)); err != nil {
+			return nil, fmt.Errorf("invalid initial state %v: %v", filename, err)
+		}
+	}
+
+	state := &rules.BoardState{
+		Height: frame.Board.Height,
+		Width:  frame.Board.Width,
+		Food:   pointsFromCoords(frame.Board.Food),
+	}
+	for _, s := range frame.Board.Snakes {
+		state.Snakes = append(state.Snakes, rules.Snake{
+			ID:     s.Id,
+			Body:   pointsFromCoords(s.Body),
+			Health: s.Health,
+		})
+	}
+
+	if err := assignSnakeIDs(state, snakes); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// validateSnakeBody checks that each segment of the body is adjacent to the next and that
+// the body doesn't cross itself. Consecutive segments may be stacked, as after eating. On a
+// wrapped board, segments on opposite edges are adjacent too.
+func validateSnakeBody(s SnakeResponse, width, height int32, wrapped bool) error {
+	seen := make(map[Coord]int)
+	for i, c := range s.Body {
+		if i > 0 {
+			prev := s.Body[i-1]
+			if c == prev {
+				continue
+			}
+			dx, dy := abs(c.X-prev.X), abs(c.Y-prev.Y)
+			if wrapped && dx == width-1 {
+				dx = 1
+			}
+			if wrapped && dy == height-1 {
+				dy = 1
+			}
+			if dx+dy != 1 {
+				return fmt.Errorf("snake %v body is not contiguous between segment %v %v and segment %v %v", s.Name, i-1, prev, i, c)
+			}
+		}
+		if j, ok := seen[c]; ok {
+			return fmt.Errorf("snake %v body intersects itself at segment %v %v (segment %v)", s.Name, i, c, j)
+		}
+		seen[c] = i
+	}
+	return nil
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Snapshot is a game stopped before it was over, which can be resumed with --resume.
+// Unlike a frame, it includes eliminated snakes.
+type Snapshot struct {
+	Turn  int32             `json:"turn"`
+	Seed  int64             `json:"seed"`
+	State *rules.BoardState `json:"state"`
+	// RandDraws are the numbers drawn from the board seed up to the snapshot, which are skipped
+	// when resuming so that food and hazards spawn as if the game hadn't been stopped.
+	RandDraws int64 `json:"rand_draws,omitempty"`
+	// EliminatedTurns are the turns the snakes eliminated before the snapshot were eliminated on.
+	EliminatedTurns map[string]int32 `json:"eliminated_turns,omitempty"`
+}
+
+func writeSnapshot(filename string, snapshot Snapshot) error {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+func readSnapshot(filename string) (Snapshot, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot %v: %v", filename, err)
+	}
+	if snapshot.State == nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot %v: missing state", filename)
+	}
+	return snapshot, nil
+}
+
+// assignSnakeIDs assigns the IDs of the snakes in the state to the given snakes in order.
+func assignSnakeIDs(state *rules.BoardState, snakes []Battlesnake) error {
+	if len(state.Snakes) != len(snakes) {
+		return fmt.Errorf("initial state has %v snakes but %v were provided", len(state.Snakes), len(snakes))
+	}
+	for i, s := range state.Snakes {
+		snakes[i].ID = s.ID
+	}
+	return validateSnakeIDs(snakes)
+}
+
+func validateSnakeIDs(snakes []Battlesnake) error {
+	names := make(map[string]string)
+	for _, snake := range snakes {
+		if snake.ID == "" {
+			return fmt.Errorf("snake %v has no ID", snake.Name)
+		}
+		if other, ok := names[snake.ID]; ok {
+			return fmt.Errorf("duplicate snake ID %v for %v and %v", snake.ID, other, snake.Name)
+		}
+		names[snake.ID] = snake.Name
+	}
+	return nil
+}
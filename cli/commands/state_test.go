@@ -0,0 +1,293 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadInitialState(t *testing.T) {
+	frame := Frame{Board: BoardResponse{
+		Width:  7,
+		Height: 7,
+		Food:   []Coord{{X: 3, Y: 3}},
+		Snakes: []SnakeResponse{
+			{Id: "a", Name: "one", Health: 90, Body: []Coord{{X: 1, Y: 1}, {X: 1, Y: 0}}},
+			{Id: "b", Name: "two", Health: 80, Body: []Coord{{X: 5, Y: 5}, {X: 5, Y: 6}}},
+		},
+	}}
+	path := writeTestFrames(t, []Frame{frame})
+	snakes := []Battlesnake{{Name: "one", ID: "x"}, {Name: "two", ID: "y"}}
+
+	state, err := loadInitialState(path, "standard", snakes)
+	require.NoError(t, err)
+	require.Equal(t, "a", snakes[0].ID)
+	require.Equal(t, "b", snakes[1].ID)
+	require.Equal(t, int32(7), state.Width)
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}}, state.Food)
+	require.Equal(t, rules.Snake{ID: "b", Health: 80, Body: []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 6}}}, state.Snakes[1])
+
+	_, err = loadInitialState(path, "standard", snakes[:1])
+	require.EqualError(t, err, "initial state has 2 snakes but 1 were provided")
+}
+
+func TestLoadInitialStateDuplicateIDs(t *testing.T) {
+	frame := Frame{Board: BoardResponse{
+		Width:  7,
+		Height: 7,
+		Snakes: []SnakeResponse{
+			{Id: "same", Name: "one", Health: 100, Body: []Coord{{X: 1, Y: 1}}},
+			{Id: "same", Name: "two", Health: 100, Body: []Coord{{X: 5, Y: 5}}},
+		},
+	}}
+	path := writeTestFrames(t, []Frame{frame})
+	snakes := []Battlesnake{{Name: "one"}, {Name: "two"}}
+
+	_, err := loadInitialState(path, "standard", snakes)
+	require.EqualError(t, err, "duplicate snake ID same for one and two")
+}
+
+func TestRunSnapshotResume(t *testing.T) {
+	srv := newTestSnake(t, circleMove)
+	path := writeTestFrames(t, nil)
+	newOptions := func() (*Options, *testLog) {
+		l := new(testLog)
+		return &Options{
+			Width:      2,
+			Height:     2,
+			Names:      []string{"circler"},
+			URLs:       []string{srv.URL},
+			GameType:   "solo",
+			Sequential: true,
+			Seed:       5,
+			LogMoves:   true,
+			Log:        l.Log,
+		}, l
+	}
+
+	o, _ := newOptions()
+	full := Run(o)
+
+	o, _ = newOptions()
+	o.MaxTurns = 20
+	o.Snapshot = path
+	stopped := Run(o)
+	require.Equal(t, EndReasonTurnLimit, stopped.EndReason)
+
+	snapshot, err := readSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, int32(20), snapshot.Turn)
+	require.Equal(t, int64(5), snapshot.Seed)
+	require.Equal(t, stopped.Board, snapshot.State)
+
+	o, l := newOptions()
+	o.Seed = 0
+	o.Resume = path
+	resumed := Run(o)
+	require.Equal(t, int64(5), o.Seed)
+	require.Equal(t, 1, l.Count(`[MOVE]: {"turn":21,`))
+	require.Equal(t, 0, l.Count(`[MOVE]: {"turn":20,`))
+	require.Equal(t, EndReasonSoloEliminated, resumed.EndReason)
+	require.Equal(t, full.Turn, resumed.Turn)
+	require.Equal(t, full.Snakes[0].Length, resumed.Snakes[0].Length)
+	require.Equal(t, full.Snakes[0].EliminatedTurn, resumed.Snakes[0].EliminatedTurn)
+}
+
+func TestRunSnapshotResumeRandomness(t *testing.T) {
+	srv := newTestSnake(t, squareMove)
+	path := writeTestFrames(t, nil)
+	newOptions := func() *Options {
+		return &Options{
+			Width:      rules.BoardSizeMedium,
+			Height:     rules.BoardSizeMedium,
+			Names:      []string{"square"},
+			URLs:       []string{srv.URL},
+			GameType:   "solo",
+			Sequential: true,
+			Seed:       3,
+			MaxTurns:   30,
+			Log:        new(testLog).Log,
+		}
+	}
+
+	full := Run(newOptions())
+	require.Equal(t, int32(30), full.Turn)
+
+	o := newOptions()
+	o.MaxTurns = 15
+	o.Snapshot = path
+	Run(o)
+	snapshot, err := readSnapshot(path)
+	require.NoError(t, err)
+	require.NotZero(t, snapshot.RandDraws)
+
+	// Food spawns the same after resuming as in the game that wasn't stopped.
+	o = newOptions()
+	o.Resume = path
+	resumed := Run(o)
+	require.Equal(t, full.Turn, resumed.Turn)
+	require.NotEqual(t, snapshot.State.Food, full.Board.Food)
+	require.Equal(t, full.Board.Food, resumed.Board.Food)
+	require.Equal(t, full.Board.Snakes[0].Body, resumed.Board.Snakes[0].Body)
+}
+
+func TestRunSnapshotResumeEliminated(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	path := writeTestFrames(t, nil)
+	newOptions := func() (*Options, *testLog) {
+		l := new(testLog)
+		return &Options{
+			Width:               rules.BoardSizeMedium,
+			Height:              rules.BoardSizeMedium,
+			Names:               []string{"square1", "square2", "up"},
+			URLs:                []string{square.URL, square.URL, up.URL},
+			GameType:            "standard",
+			Sequential:          true,
+			Seed:                1,
+			MaxTurns:            20,
+			VerboseEliminations: true,
+			Log:                 l.Log,
+		}, l
+	}
+
+	o, _ := newOptions()
+	full := Run(o)
+
+	// The up snake is already eliminated when the game is stopped.
+	o, _ = newOptions()
+	o.MaxTurns = 15
+	o.Snapshot = path
+	stopped := Run(o)
+	require.Len(t, stopped.Eliminations, 1)
+	snapshot, err := readSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int32{stopped.Eliminations[0].SnakeID: stopped.Eliminations[0].Turn}, snapshot.EliminatedTurns)
+
+	o, l := newOptions()
+	o.Resume = path
+	resumed := Run(o)
+	require.Equal(t, 0, l.Count("[ELIMINATED]: "))
+	require.Equal(t, stopped.Eliminations, resumed.Eliminations)
+	require.Equal(t, full.Snakes, resumed.Snakes)
+}
+
+func TestLoadInitialStateInvalidBody(t *testing.T) {
+	tests := []struct {
+		Body  []Coord
+		Error string
+	}{
+		{
+			Body:  []Coord{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 3, Y: 2}},
+			Error: "snake broken body is not contiguous between segment 1 {1 2} and segment 2 {3 2}",
+		},
+		{
+			Body:  []Coord{{X: 1, Y: 1}, {X: 2, Y: 2}},
+			Error: "snake broken body is not contiguous between segment 0 {1 1} and segment 1 {2 2}",
+		},
+		{
+			Body:  []Coord{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}},
+			Error: "snake broken body intersects itself at segment 4 {1 1} (segment 0)",
+		},
+	}
+	for _, test := range tests {
+		frame := Frame{Board: BoardResponse{
+			Width:  7,
+			Height: 7,
+			Snakes: []SnakeResponse{
+				{Id: "a", Name: "fine", Health: 100, Body: []Coord{{X: 5, Y: 5}, {X: 5, Y: 4}, {X: 5, Y: 4}}},
+				{Id: "b", Name: "broken", Health: 100, Body: test.Body},
+			},
+		}}
+		path := writeTestFrames(t, []Frame{frame})
+
+		_, err := loadInitialState(path, "standard", []Battlesnake{{Name: "fine"}, {Name: "broken"}})
+		require.EqualError(t, err, "invalid initial state "+path+": "+test.Error)
+	}
+}
+
+func TestLoadInitialStateWrappedBody(t *testing.T) {
+	// The body crosses the left and bottom edges of the board.
+	frame := Frame{Board: BoardResponse{
+		Width:  7,
+		Height: 7,
+		Snakes: []SnakeResponse{
+			{Id: "a", Name: "wrapped", Health: 100, Body: []Coord{{X: 0, Y: 3}, {X: 6, Y: 3}, {X: 6, Y: 4}}},
+			{Id: "b", Name: "seam", Health: 100, Body: []Coord{{X: 2, Y: 0}, {X: 2, Y: 6}, {X: 2, Y: 5}}},
+		},
+	}}
+	path := writeTestFrames(t, []Frame{frame})
+	snakes := []Battlesnake{{Name: "wrapped"}, {Name: "seam"}}
+
+	state, err := loadInitialState(path, "wrapped", snakes)
+	require.NoError(t, err)
+	require.Equal(t, []rules.Point{{X: 0, Y: 3}, {X: 6, Y: 3}, {X: 6, Y: 4}}, state.Snakes[0].Body)
+	_, err = loadInitialState(path, "standard", snakes)
+	require.EqualError(t, err, "invalid initial state "+path+": snake wrapped body is not contiguous between segment 0 {0 3} and segment 1 {6 3}")
+}
+
+func TestRunBoardSeed(t *testing.T) {
+	initialFrame := func(seed, boardSeed int64) Frame {
+		path := writeTestFrames(t, nil)
+		Run(&Options{
+			Width:        rules.BoardSizeMedium,
+			Height:       rules.BoardSizeMedium,
+			GameType:     "standard",
+			Seed:         seed,
+			BoardSeed:    boardSeed,
+			RandomSnakes: 4,
+			MaxTurns:     1,
+			Output:       path,
+			Log:          new(testLog).Log,
+		})
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		frames, err := readFrames(f)
+		require.NoError(t, err)
+		return frames[0]
+	}
+	heads := func(frame Frame) []Coord {
+		var heads []Coord
+		for _, snake := range frame.Board.Snakes {
+			heads = append(heads, snake.Head)
+		}
+		return heads
+	}
+
+	a, b := initialFrame(1, 42), initialFrame(2, 42)
+	require.Equal(t, a.Board.Food, b.Board.Food)
+	require.Equal(t, heads(a), heads(b))
+
+	c := initialFrame(1, 43)
+	require.NotEqual(t, a.Board.Food, c.Board.Food)
+}
+
+func TestRunRequireSymmetricStart(t *testing.T) {
+	srv := newTestSnake(t, upMove)
+	newOptions := func(b Coord) *Options {
+		path := writeTestFrames(t, []Frame{{Board: BoardResponse{
+			Width:  7,
+			Height: 7,
+			Snakes: []SnakeResponse{
+				{Id: "a", Health: 100, Body: []Coord{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}},
+				{Id: "b", Health: 100, Body: []Coord{b, b, b}},
+			},
+		}}})
+		return &Options{
+			Names:            []string{"a", "b"},
+			URLs:             []string{srv.URL, srv.URL},
+			GameType:         "standard",
+			Sequential:       true,
+			MaxTurns:         1,
+			InitialState:     path,
+			RequireSymmetric: true,
+			Log:              new(testLog).Log,
+		}
+	}
+
+	require.NotPanics(t, func() { Run(newOptions(Coord{X: 5, Y: 1})) })
+	require.Panics(t, func() { Run(newOptions(Coord{X: 1, Y: 3})) })
+}
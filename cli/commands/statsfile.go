@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// StatsFile is the cumulative record of games kept with --stats-file across runs.
+type StatsFile struct {
+	Snakes map[string]*SnakeRecord `json:"snakes"`
+}
+
+type SnakeRecord struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+// statsLockTimeout is how long to wait for another process to release the stats file.
+var statsLockTimeout = 5 * time.Second
+
+// updateStatsFile adds the result of a game to the stats file, creating it if needed.
+// Solo games have no opponents, so they aren't recorded. With drawAsLoss, a draw is recorded
+// as a loss for every snake.
+func updateStatsFile(path string, gameType string, res Result, drawAsLoss bool) error {
+	if gameType == "solo" {
+		return nil
+	}
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stats, err := readStatsFile(path)
+	if err != nil {
+		return err
+	}
+	for _, sr := range res.Snakes {
+		record, ok := stats.Snakes[sr.Name]
+		if !ok {
+			record = new(SnakeRecord)
+			stats.Snakes[sr.Name] = record
+		}
+		switch {
+		case res.Winner == "" && !drawAsLoss:
+			record.Draws++
+		case res.Winner == sr.Name:
+			record.Wins++
+		default:
+			record.Losses++
+		}
+	}
+
+	b, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so the stats are never left half written.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readStatsFile(path string) (StatsFile, error) {
+	stats := StatsFile{Snakes: make(map[string]*SnakeRecord)}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	} else if err != nil {
+		return stats, err
+	}
+	if err := json.Unmarshal(b, &stats); err != nil {
+		return stats, fmt.Errorf("invalid stats file %v: %v", path, err)
+	}
+	if stats.Snakes == nil {
+		stats.Snakes = make(map[string]*SnakeRecord)
+	}
+	return stats, nil
+}
+
+// lockFile takes an exclusive lock on path by creating a lock file next to it,
+// which works across processes on every platform.
+func lockFile(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(statsLockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %v, remove it if no other game is running", lock)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStatsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stats.json")
+
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	for i := 0; i < 2; i++ {
+		res := Run(&Options{
+			Width:      rules.BoardSizeSmall,
+			Height:     rules.BoardSizeSmall,
+			Names:      []string{"square", "up"},
+			URLs:       []string{square.URL, up.URL},
+			GameType:   "standard",
+			Sequential: true,
+			Seed:       1,
+			StatsFile:  path,
+			Log:        new(testLog).Log,
+		})
+		require.Equal(t, "square", res.Winner)
+	}
+
+	stats, err := readStatsFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]*SnakeRecord{
+		"square": {Wins: 2},
+		"up":     {Losses: 2},
+	}, stats.Snakes)
+
+	_, err = os.Stat(path + ".lock")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestUpdateStatsFileConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stats.json")
+
+	results := []Result{
+		{Winner: "a", Snakes: []SnakeResult{{Name: "a"}, {Name: "b"}}},
+		{Winner: "b", Snakes: []SnakeResult{{Name: "a"}, {Name: "b"}}},
+		{Snakes: []SnakeResult{{Name: "a"}, {Name: "b"}}},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, res := range results {
+			wg.Add(1)
+			go func(res Result) {
+				defer wg.Done()
+				require.NoError(t, updateStatsFile(path, "standard", res, false))
+			}(res)
+		}
+	}
+	wg.Wait()
+	require.NoError(t, updateStatsFile(path, "solo", results[0], false))
+
+	stats, err := readStatsFile(path)
+	require.NoError(t, err)
+	require.Equal(t, &SnakeRecord{Wins: 10, Losses: 10, Draws: 10}, stats.Snakes["a"])
+	require.Equal(t, &SnakeRecord{Wins: 10, Losses: 10, Draws: 10}, stats.Snakes["b"])
+}
+
+func TestUpdateStatsFileDrawAsLoss(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	draw := Result{Snakes: []SnakeResult{{Name: "a"}, {Name: "b"}}}
+	require.NoError(t, updateStatsFile(path, "standard", draw, true))
+	require.NoError(t, updateStatsFile(path, "standard", Result{Winner: "a", Snakes: draw.Snakes}, true))
+
+	stats, err := readStatsFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]*SnakeRecord{
+		"a": {Wins: 1, Losses: 1},
+		"b": {Losses: 2},
+	}, stats.Snakes)
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+
+	"github.com/corverroos/bsrules"
+	"github.com/spf13/cobra"
+)
+
+// StepInput is the JSON read by step-json: a board state and the moves to apply to it.
+type StepInput struct {
+	Turn  int32             `json:"turn"`
+	State *rules.BoardState `json:"state"`
+	Moves []rules.SnakeMove `json:"moves"`
+}
+
+var stepCmd = &cobra.Command{
+	Use:   "step-json",
+	Short: "Apply a single turn to a board state read as JSON.",
+	Long:  "Read a board state and moves as JSON from stdin, apply one turn of the chosen rules and write the next board state as JSON to stdout.",
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(stepCmd)
+
+	var o Options
+
+	stepCmd.Flags().StringVarP(&o.GameType, "gametype", "g", "standard", "Type of Game Rules")
+	stepCmd.Flags().Int64VarP(&o.Seed, "seed", "r", 0, "Random Seed for Food Spawns")
+
+	stepCmd.Run = func(cmd *cobra.Command, args []string) {
+		if err := StepJSON(&o, os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// StepJSON reads a StepInput from r, applies its moves under the rules of o.GameType and
+// writes the next board state to w.
+func StepJSON(o *Options, r io.Reader, w io.Writer) error {
+	var in StepInput
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return fmt.Errorf("invalid step input: %v", err)
+	}
+	if in.State == nil {
+		return fmt.Errorf("invalid step input: missing state")
+	}
+
+	o.Turn = in.Turn
+	o.Width, o.Height = in.State.Width, in.State.Height
+	o.rand = rand.New(rand.NewSource(o.Seed))
+	ruleset, _ := getRuleset(o, nil)
+
+	state, err := ruleset.CreateNextBoardState(in.State, in.Moves)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(state)
+}
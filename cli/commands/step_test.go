@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepJSON(t *testing.T) {
+	in := `{
+		"turn": 3,
+		"state": {
+			"Width": 5,
+			"Height": 5,
+			"Food": [{"X": 1, "Y": 2}],
+			"Snakes": [
+				{"ID": "one", "Health": 50, "Body": [{"X": 1, "Y": 1}, {"X": 1, "Y": 0}, {"X": 0, "Y": 0}]},
+				{"ID": "two", "Health": 50, "Body": [{"X": 3, "Y": 4}, {"X": 3, "Y": 3}, {"X": 3, "Y": 2}]}
+			]
+		},
+		"moves": [{"ID": "one", "Move": "up"}, {"ID": "two", "Move": "up"}]
+	}`
+
+	var out bytes.Buffer
+	require.NoError(t, StepJSON(&Options{GameType: "standard", Seed: 1}, strings.NewReader(in), &out))
+
+	var state rules.BoardState
+	require.NoError(t, json.Unmarshal(out.Bytes(), &state))
+	require.Equal(t, rules.Snake{
+		ID:     "one",
+		Health: 100,
+		Body:   []rules.Point{{X: 1, Y: 2}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 0}},
+	}, state.Snakes[0])
+	require.Equal(t, rules.Snake{
+		ID:              "two",
+		Health:          49,
+		Body:            []rules.Point{{X: 3, Y: 5}, {X: 3, Y: 4}, {X: 3, Y: 3}},
+		EliminatedCause: rules.EliminatedByOutOfBounds,
+	}, state.Snakes[1])
+	require.NotContains(t, state.Food, rules.Point{X: 1, Y: 2})
+
+	err := StepJSON(&Options{GameType: "standard"}, strings.NewReader(`{"moves": []}`), &out)
+	require.EqualError(t, err, "invalid step input: missing state")
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+)
+
+// SquadResult is the combined result of the snakes of a squad, for --team-summary.
+type SquadResult struct {
+	Squad  string   `json:"squad"`
+	Snakes []string `json:"snakes"`
+	Alive  int      `json:"alive"`
+	Length int32    `json:"length"`
+	// TurnsSurvived is the sum of the turns each snake of the squad survived.
+	TurnsSurvived int32 `json:"turns_survived"`
+}
+
+// buildSquadMap maps the ID of each snake to its squad, the way the squad ruleset groups them.
+func buildSquadMap(snakes []Battlesnake) map[string]string {
+	squadMap := map[string]string{}
+	for _, snake := range snakes {
+		squadMap[snake.ID] = snake.Squad
+	}
+	return squadMap
+}
+
+// buildSquadResults combines the results of the snakes of each squad, ordered by squad.
+func buildSquadResults(o *Options, state *rules.BoardState, squadMap map[string]string, eliminatedTurns map[string]int32) []SquadResult {
+	bySquad := make(map[string]*SquadResult)
+	var squads []string
+	for _, snake := range state.Snakes {
+		squad := squadMap[snake.ID]
+		sr, ok := bySquad[squad]
+		if !ok {
+			sr = &SquadResult{Squad: squad}
+			bySquad[squad] = sr
+			squads = append(squads, squad)
+		}
+		sr.Snakes = append(sr.Snakes, o.Battlesnakes[snake.ID].Name)
+		sr.Length += int32(len(snake.Body))
+		if snake.EliminatedCause == rules.NotEliminated {
+			sr.Alive++
+			sr.TurnsSurvived += o.Turn
+		} else {
+			sr.TurnsSurvived += eliminatedTurns[snake.ID]
+		}
+	}
+	sort.Strings(squads)
+
+	results := make([]SquadResult, 0, len(squads))
+	for _, squad := range squads {
+		results = append(results, *bySquad[squad])
+	}
+	return results
+}
+
+// winningSquad returns the squad of the snakes left in the game, if they are all in one squad.
+func winningSquad(squads []SquadResult) string {
+	var winner string
+	for _, sr := range squads {
+		if sr.Alive == 0 {
+			continue
+		}
+		if winner != "" {
+			return ""
+		}
+		winner = sr.Squad
+	}
+	return winner
+}
+
+func logTeamSummary(o *Options, squads []SquadResult, winner string) {
+	for _, sr := range squads {
+		o.Log("[SQUAD]: %v (%v) finished with %v of %v snakes alive, combined length %v and %v turns survived.", sr.Squad, strings.Join(sr.Snakes, ", "), sr.Alive, len(sr.Snakes), sr.Length, sr.TurnsSurvived)
+	}
+	if winner != "" {
+		o.Log("[DONE]: Squad %v is the winner.", winner)
+	} else {
+		o.Log("[DONE]: No squad won.")
+	}
+}
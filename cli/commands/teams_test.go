@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTeamSummary(t *testing.T) {
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	l := new(testLog)
+	o := &Options{
+		Width:       rules.BoardSizeMedium,
+		Height:      rules.BoardSizeMedium,
+		Names:       []string{"square1", "square2", "up1", "up2"},
+		URLs:        []string{square.URL, square.URL, up.URL, up.URL},
+		Squads:      []string{"squares", "squares", "ups", "ups"},
+		GameType:    "squad",
+		Sequential:  true,
+		Seed:        1,
+		TeamSummary: true,
+		Log:         l.Log,
+	}
+	res := Run(o)
+
+	require.Equal(t, "squares", res.WinningSquad)
+	require.Len(t, res.Squads, 2)
+	squares, ups := res.Squads[0], res.Squads[1]
+
+	require.Equal(t, "squares", squares.Squad)
+	require.Equal(t, []string{"square1", "square2"}, squares.Snakes)
+	require.Equal(t, 2, squares.Alive)
+	var length int32
+	for _, snake := range res.Snakes {
+		if snake.Name == "square1" || snake.Name == "square2" {
+			length += snake.Length
+		}
+	}
+	require.Equal(t, length, squares.Length)
+	require.Equal(t, 2*res.Turn, squares.TurnsSurvived)
+
+	// The ups are eliminated together as soon as one of them hits the wall.
+	require.Equal(t, "ups", ups.Squad)
+	require.Equal(t, 0, ups.Alive)
+	require.Equal(t, 2*res.Turn, ups.TurnsSurvived)
+
+	require.Equal(t, 1, l.Count("[SQUAD]: squares (square1, square2) finished with 2 of 2 snakes alive"))
+	require.Equal(t, 1, l.Count("[SQUAD]: ups (up1, up2) finished with 0 of 2 snakes alive"))
+	require.Equal(t, 1, l.Count("[DONE]: Squad squares is the winner."))
+}
+
+func TestWinningSquad(t *testing.T) {
+	require.Equal(t, "", winningSquad(nil))
+	require.Equal(t, "a", winningSquad([]SquadResult{{Squad: "a", Alive: 1}, {Squad: "b"}}))
+	require.Equal(t, "", winningSquad([]SquadResult{{Squad: "a", Alive: 1}, {Squad: "b", Alive: 2}}))
+	require.Equal(t, "", winningSquad([]SquadResult{{Squad: "a"}, {Squad: "b"}}))
+}
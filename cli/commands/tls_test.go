@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p)})
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(caFile, certPEM, 0644))
+
+	run := func(o *Options) *testLog {
+		l := new(testLog)
+		o.Width, o.Height = 2, 2
+		o.Names, o.URLs = []string{"secure"}, []string{srv.URL}
+		o.GameType, o.Sequential, o.Seed = "solo", true, 1
+		o.LogMoves, o.MaxTurns = true, 3
+		o.Log = l.Log
+		Run(o)
+		return l
+	}
+
+	l := run(&Options{CAFile: caFile})
+	require.Equal(t, 0, l.Count("failed"))
+	require.Equal(t, 3, l.Count(`"fallback":false`))
+
+	l = run(&Options{InsecureSkipVerify: true})
+	require.Equal(t, 0, l.Count("failed"))
+	require.Equal(t, 3, l.Count(`"fallback":false`))
+
+	l = run(&Options{})
+	require.Equal(t, 1, l.Count("[WARN]: Request to "+srv.URL+"/move failed"))
+	require.Equal(t, 1, l.Count(`"fallback":true`))
+	require.Equal(t, 0, l.Count(`"fallback":false`))
+}
+
+func TestBuildTLSConfigInvalidCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caFile, []byte("not a certificate"), 0644))
+
+	_, err = buildTLSConfig(&Options{CAFile: caFile})
+	require.EqualError(t, err, "no certificates found in "+caFile)
+
+	_, err = buildTLSConfig(&Options{CAFile: filepath.Join(dir, "missing.pem")})
+	require.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/corverroos/bsrules"
+)
+
+// ViewerGame is a game in the format of the Battlesnake engine API, as served from its game and
+// frames endpoints, which is what the official board viewer loads. Unlike the recordings of
+// --output, every frame includes the eliminated snakes along with how they died.
+type ViewerGame struct {
+	Game   ViewerGameInfo `json:"Game"`
+	Frames []ViewerFrame  `json:"Frames"`
+}
+
+type ViewerGameInfo struct {
+	ID           string            `json:"ID"`
+	Status       string            `json:"Status"`
+	Width        int32             `json:"Width"`
+	Height       int32             `json:"Height"`
+	Ruleset      map[string]string `json:"Ruleset"`
+	SnakeTimeout int32             `json:"SnakeTimeout"`
+}
+
+type ViewerFrame struct {
+	Turn    int32         `json:"Turn"`
+	Snakes  []ViewerSnake `json:"Snakes"`
+	Food    []ViewerPoint `json:"Food"`
+	Hazards []ViewerPoint `json:"Hazards"`
+}
+
+type ViewerSnake struct {
+	ID       string        `json:"ID"`
+	Name     string        `json:"Name"`
+	URL      string        `json:"URL"`
+	Body     []ViewerPoint `json:"Body"`
+	Health   int32         `json:"Health"`
+	Death    *ViewerDeath  `json:"Death"`
+	Color    string        `json:"Color"`
+	HeadType string        `json:"HeadType"`
+	TailType string        `json:"TailType"`
+	Latency  string        `json:"Latency"`
+	Shout    string        `json:"Shout"`
+	Squad    string        `json:"Squad"`
+	Author   string        `json:"Author"`
+}
+
+// ViewerDeath is how and on which turn a snake was eliminated.
+type ViewerDeath struct {
+	Cause        string `json:"Cause"`
+	Turn         int32  `json:"Turn"`
+	EliminatedBy string `json:"EliminatedBy"`
+}
+
+type ViewerPoint struct {
+	X int32 `json:"X"`
+	Y int32 `json:"Y"`
+}
+
+// buildViewerFrame returns the board viewer frame of the current turn. Latencies and shouts are
+// those of the snakes' last moves, with latencies in milliseconds.
+func buildViewerFrame(o *Options, state *rules.BoardState, outOfBounds []rules.Point, infos map[string]InfoResponse, eliminatedTurns map[string]int32) ViewerFrame {
+	frame := ViewerFrame{
+		Turn:    o.Turn,
+		Snakes:  make([]ViewerSnake, 0, len(state.Snakes)),
+		Food:    viewerPoints(state.Food),
+		Hazards: viewerPoints(outOfBounds),
+	}
+	for _, snake := range state.Snakes {
+		latency := "0"
+		if l := o.latencies[snake.ID]; len(l) > 0 {
+			latency = fmt.Sprint(l[len(l)-1].Milliseconds())
+		}
+		var death *ViewerDeath
+		if snake.EliminatedCause != rules.NotEliminated {
+			death = &ViewerDeath{
+				Cause:        snake.EliminatedCause,
+				Turn:         eliminatedTurns[snake.ID],
+				EliminatedBy: snake.EliminatedBy,
+			}
+		}
+		// Infos are keyed by name.
+		info := infos[o.Battlesnakes[snake.ID].Name]
+		frame.Snakes = append(frame.Snakes, ViewerSnake{
+			ID:       snake.ID,
+			Name:     o.Battlesnakes[snake.ID].Name,
+			URL:      o.Battlesnakes[snake.ID].URL,
+			Body:     viewerPoints(snake.Body),
+			Health:   snake.Health,
+			Death:    death,
+			Color:    o.Battlesnakes[snake.ID].Color,
+			HeadType: info.Head,
+			TailType: info.Tail,
+			Latency:  latency,
+			Shout:    o.shouts[snake.ID],
+			Squad:    o.Battlesnakes[snake.ID].Squad,
+			Author:   info.Author,
+		})
+	}
+	return frame
+}
+
+func viewerPoints(points []rules.Point) []ViewerPoint {
+	a := make([]ViewerPoint, 0, len(points))
+	for _, p := range points {
+		a = append(a, ViewerPoint{X: p.X, Y: p.Y})
+	}
+	return a
+}
+
+// writeBoardViewer writes the frames of a finished game to --board-viewer.
+func writeBoardViewer(o *Options, state *rules.BoardState, royale rules.RoyaleRuleset, frames []ViewerFrame) {
+	game := ViewerGame{
+		Game: ViewerGameInfo{
+			ID:           o.GameId,
+			Status:       "complete",
+			Width:        state.Width,
+			Height:       state.Height,
+			Ruleset:      viewerRuleset(o, royale),
+			SnakeTimeout: o.Timeout,
+		},
+		Frames: frames,
+	}
+	b, err := json.Marshal(game)
+	if err == nil {
+		err = ioutil.WriteFile(o.BoardViewer, b, 0644)
+	}
+	if err != nil {
+		o.Log("[WARN]: Unable to write board viewer game %v: %v", o.BoardViewer, err)
+	}
+}
+
+// viewerRuleset returns the name and settings of the rules of the game, as the engine lists them.
+// The shrinking settings are only listed for royale games.
+func viewerRuleset(o *Options, royale rules.RoyaleRuleset) map[string]string {
+	standard := standardRuleset(o)
+	ruleset := map[string]string{
+		"name":            o.GameType,
+		"foodSpawnChance": fmt.Sprint(standard.FoodSpawnChance),
+		"minimumFood":     fmt.Sprint(standard.MinimumFood),
+	}
+	if royale.ShrinkEveryNTurns > 0 {
+		ruleset["shrinkEveryNTurns"] = fmt.Sprint(royale.ShrinkEveryNTurns)
+		ruleset["damagePerTurn"] = fmt.Sprint(royale.DamagePerTurn)
+	}
+	return ruleset
+}
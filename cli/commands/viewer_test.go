@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBoardViewer(t *testing.T) {
+	circler := httptest.NewServer(newTestMux(func(w http.ResponseWriter, p ResponsePayload) {
+		_ = json.NewEncoder(w).Encode(PlayerResponse{Move: circleMove(p), Shout: fmt.Sprintf("turn %v", p.Turn)})
+	}))
+	defer circler.Close()
+	climber := newTestSnake(t, upMove)
+	path := filepath.Join(t.TempDir(), "game.json")
+	o := &Options{
+		Width:       rules.BoardSizeSmall,
+		Height:      rules.BoardSizeSmall,
+		Names:       []string{"circler", "climber"},
+		URLs:        []string{circler.URL, climber.URL},
+		GameType:    "standard",
+		Seed:        1,
+		BoardViewer: path,
+		Log:         new(testLog).Log,
+	}
+	res := Run(o)
+	require.Equal(t, "circler", res.Winner)
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	// The schema is checked on the raw JSON, since the viewer depends on the field names.
+	var game map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b, &game))
+	require.Len(t, game, 2)
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(game["Game"], &info))
+	require.Equal(t, o.GameId, info["ID"])
+	require.Equal(t, "complete", info["Status"])
+	require.Equal(t, float64(rules.BoardSizeSmall), info["Width"])
+	require.Equal(t, float64(rules.BoardSizeSmall), info["Height"])
+	require.Equal(t, map[string]interface{}{"name": "standard", "foodSpawnChance": "15", "minimumFood": "1"}, info["Ruleset"])
+	require.Equal(t, float64(500), info["SnakeTimeout"])
+
+	var frames []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(game["Frames"], &frames))
+	require.Len(t, frames, int(res.Turn)+1)
+	for i, frame := range frames {
+		require.Len(t, frame, 4)
+		var turn int32
+		require.NoError(t, json.Unmarshal(frame["Turn"], &turn))
+		require.Equal(t, int32(i), turn)
+		var food []map[string]int32
+		require.NoError(t, json.Unmarshal(frame["Food"], &food))
+		for _, f := range food {
+			require.Len(t, f, 2)
+			require.Contains(t, f, "X")
+			require.Contains(t, f, "Y")
+		}
+		var snakes []map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(frame["Snakes"], &snakes))
+		require.Len(t, snakes, 2)
+		for _, snake := range snakes {
+			for _, field := range []string{"ID", "Name", "URL", "Body", "Health", "Death", "Color", "HeadType", "TailType", "Latency", "Shout", "Squad", "Author"} {
+				require.Contains(t, snake, field)
+			}
+		}
+	}
+
+	// Eliminated snakes stay in the frames, with how they died.
+	var last ViewerGame
+	require.NoError(t, json.Unmarshal(b, &last))
+	final := last.Frames[len(last.Frames)-1]
+	for _, snake := range final.Snakes {
+		if snake.Name == "circler" {
+			require.Nil(t, snake.Death)
+			require.Equal(t, fmt.Sprintf("turn %v", res.Turn), snake.Shout)
+			continue
+		}
+		require.Equal(t, &ViewerDeath{Cause: rules.EliminatedByOutOfBounds, Turn: res.Turn}, snake.Death)
+	}
+	require.Nil(t, last.Frames[0].Snakes[1].Death)
+	require.Empty(t, last.Frames[0].Snakes[0].Shout)
+}
+
+func TestViewerRuleset(t *testing.T) {
+	_, royale := getRuleset(&Options{GameType: "royale"}, nil)
+	require.Equal(t, map[string]string{
+		"name":              "royale",
+		"foodSpawnChance":   "15",
+		"minimumFood":       "1",
+		"shrinkEveryNTurns": "20",
+		"damagePerTurn":     "15",
+	}, viewerRuleset(&Options{GameType: "royale"}, royale))
+}
+
+func TestBuildViewerFrame(t *testing.T) {
+	o := &Options{
+		Turn:   3,
+		shouts: map[string]string{"one": "hello"},
+		Battlesnakes: map[string]Battlesnake{
+			"one": {ID: "one", Name: "Snake1", URL: "http://one", Color: "#ff0000", Squad: "red"},
+		},
+	}
+	state := &rules.BoardState{
+		Width:  5,
+		Height: 5,
+		Food:   []rules.Point{{X: 4, Y: 4}},
+		Snakes: []rules.Snake{{ID: "one", Health: 90, Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}}}},
+	}
+	infos := map[string]InfoResponse{"Snake1": {Author: "me", Head: "beluga", Tail: "curled"}}
+
+	frame := buildViewerFrame(o, state, []rules.Point{{X: 0, Y: 4}}, infos, nil)
+	require.Equal(t, ViewerFrame{
+		Turn: 3,
+		Snakes: []ViewerSnake{{
+			ID:       "one",
+			Name:     "Snake1",
+			URL:      "http://one",
+			Body:     []ViewerPoint{{X: 1, Y: 1}, {X: 1, Y: 0}},
+			Health:   90,
+			Color:    "#ff0000",
+			HeadType: "beluga",
+			TailType: "curled",
+			Latency:  "0",
+			Shout:    "hello",
+			Squad:    "red",
+			Author:   "me",
+		}},
+		Food:    []ViewerPoint{{X: 4, Y: 4}},
+		Hazards: []ViewerPoint{{X: 0, Y: 4}},
+	}, frame)
+}
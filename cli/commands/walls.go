@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/corverroos/bsrules"
+)
+
+// wallChar marks a hazard wall in layout files.
+const wallChar = '#'
+
+// loadWalls reads a board layout for the walls game type, where each line is a row of
+// the board from the top down, with # for walls and any other character for open cells.
+// It returns the walls along with the width and height of the board.
+func loadWalls(filename string) ([]rules.Point, int32, int32, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	var rows []string
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\r\n"), "\n") {
+		rows = append(rows, strings.TrimRight(line, "\r"))
+	}
+	height := int32(len(rows))
+	width := int32(len([]rune(rows[0])))
+	if width == 0 {
+		return nil, 0, 0, fmt.Errorf("invalid layout %v: empty first row", filename)
+	}
+
+	walls := []rules.Point{}
+	for i, row := range rows {
+		cells := []rune(row)
+		if int32(len(cells)) != width {
+			return nil, 0, 0, fmt.Errorf("invalid layout %v: row %v has %v cells but the first row has %v", filename, i+1, len(cells), width)
+		}
+		for x, c := range cells {
+			if c == wallChar {
+				walls = append(walls, rules.Point{X: int32(x), Y: height - 1 - int32(i)})
+			}
+		}
+	}
+	return walls, width, height, nil
+}
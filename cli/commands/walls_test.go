@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestLayout(t *testing.T, layout string) string {
+	dir, err := ioutil.TempDir("", "walls")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "layout.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte(layout), 0644))
+	return path
+}
+
+func TestLoadWalls(t *testing.T) {
+	path := writeTestLayout(t, "#..\n.#.\n..#\n...\n")
+	walls, width, height, err := loadWalls(path)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), width)
+	require.Equal(t, int32(4), height)
+	require.Equal(t, []rules.Point{{X: 0, Y: 3}, {X: 1, Y: 2}, {X: 2, Y: 1}}, walls)
+
+	path = writeTestLayout(t, "#..\n.#\n")
+	_, _, _, err = loadWalls(path)
+	require.EqualError(t, err, "invalid layout "+path+": row 2 has 2 cells but the first row has 3")
+}
+
+func TestRunHazardWalls(t *testing.T) {
+	up1, up2 := newTestSnake(t, upMove), newTestSnake(t, upMove)
+	l := new(testLog)
+
+	// The top row is a wall, which snakes moving up reach before the edge of the board.
+	o := &Options{
+		Names:      []string{"climber1", "climber2"},
+		URLs:       []string{up1.URL, up2.URL},
+		GameType:   "walls",
+		WallsFile:  writeTestLayout(t, "#######\n.......\n.......\n.......\n.......\n.......\n.......\n"),
+		Sequential: true,
+		Seed:       1,
+		ViewMap:    true,
+		Log:        l.Log,
+	}
+	res := Run(o)
+
+	require.Equal(t, int32(7), res.Board.Width)
+	require.Equal(t, int32(7), res.Board.Height)
+	for _, snake := range res.Board.Snakes {
+		require.Equal(t, rules.EliminatedByOutOfHealth, snake.EliminatedCause)
+		require.Equal(t, int32(6), snake.Body[0].Y)
+	}
+	require.Equal(t, int(res.Turn), l.Count("Walls ▓: "))
+	// Until the snakes hit the wall on the last turn
+	require.Equal(t, int(res.Turn)-1, l.Count("\n▓▓▓▓▓▓▓\n"))
+}
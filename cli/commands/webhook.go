@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const webhookAttempts = 3
+
+// webhookRetryDelay is the delay before the first retry, doubling with each retry.
+var webhookRetryDelay = time.Second
+
+// postResult posts the result of a game as JSON to the result webhook, retrying
+// failed requests and non-2xx responses.
+func postResult(o *Options, res Result) {
+	b, err := json.Marshal(res)
+	if err != nil {
+		o.Log("[WARN]: Unable to marshal result: %v", err)
+		return
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		err = postJSON(o, o.ResultWebhook, b)
+		if err == nil {
+			return
+		}
+		if attempt == webhookAttempts {
+			o.Log("[WARN]: Unable to post result to %v after %v attempts: %v", o.ResultWebhook, attempt, err)
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func postJSON(o *Options, url string, b []byte) error {
+	res, err := o.HttpClient.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %v", res.Status)
+	}
+	return nil
+}
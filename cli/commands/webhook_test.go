@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/corverroos/bsrules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunResultWebhook(t *testing.T) {
+	defer func(d time.Duration) { webhookRetryDelay = d }(webhookRetryDelay)
+	webhookRetryDelay = time.Millisecond
+
+	var mu sync.Mutex
+	var attempts int
+	var body []byte
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer webhook.Close()
+
+	square := newTestSnake(t, squareMove)
+	up := newTestSnake(t, upMove)
+	res := Run(&Options{
+		Width:         rules.BoardSizeSmall,
+		Height:        rules.BoardSizeSmall,
+		Names:         []string{"square", "up"},
+		URLs:          []string{square.URL, up.URL},
+		GameType:      "standard",
+		Sequential:    true,
+		Seed:          1,
+		ResultWebhook: webhook.URL,
+		Log:           new(testLog).Log,
+	})
+	require.Equal(t, "square", res.Winner)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 2, attempts)
+
+	var posted struct {
+		Turn      int32         `json:"turn"`
+		Winner    string        `json:"winner"`
+		Snakes    []SnakeResult `json:"snakes"`
+		EndReason string        `json:"end_reason"`
+	}
+	require.NoError(t, json.Unmarshal(body, &posted))
+	require.Equal(t, "square", posted.Winner)
+	require.Equal(t, res.Turn, posted.Turn)
+	require.Equal(t, res.Snakes, posted.Snakes)
+	require.Equal(t, "last-snake-standing", posted.EndReason)
+}
@@ -0,0 +1,63 @@
+package rules
+
+// StatesEqual reports whether two board states are logically equal, ignoring the order
+// of food and matching snakes by ID rather than by position in the slice.
+func StatesEqual(a, b *BoardState) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Width != b.Width || a.Height != b.Height || !PointsEqual(a.Food, b.Food) {
+		return false
+	}
+	if len(a.Snakes) != len(b.Snakes) {
+		return false
+	}
+	snakes := make(map[string]Snake, len(b.Snakes))
+	for _, snake := range b.Snakes {
+		snakes[snake.ID] = snake
+	}
+	if len(snakes) != len(b.Snakes) {
+		return false
+	}
+	for _, snake := range a.Snakes {
+		other, ok := snakes[snake.ID]
+		if !ok || !snakesEqual(snake, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// PointsEqual reports whether two slices hold the same points, in any order, such as
+// food or hazards. Repeated points must be repeated equally often.
+func PointsEqual(a, b []Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[Point]int, len(a))
+	for _, p := range a {
+		counts[p]++
+	}
+	for _, p := range b {
+		if counts[p] == 0 {
+			return false
+		}
+		counts[p]--
+	}
+	return true
+}
+
+func snakesEqual(a, b Snake) bool {
+	if a.ID != b.ID || a.Health != b.Health || a.EliminatedCause != b.EliminatedCause || a.EliminatedBy != b.EliminatedBy {
+		return false
+	}
+	if len(a.Body) != len(b.Body) {
+		return false
+	}
+	for i := range a.Body {
+		if a.Body[i] != b.Body[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEqualState() *BoardState {
+	return &BoardState{
+		Width:  7,
+		Height: 7,
+		Food:   []Point{{1, 1}, {2, 2}, {3, 3}},
+		Snakes: []Snake{
+			{ID: "one", Health: 90, Body: []Point{{0, 1}, {0, 0}}},
+			{ID: "two", Health: 80, Body: []Point{{5, 5}, {5, 6}}, EliminatedCause: EliminatedByCollision, EliminatedBy: "one"},
+		},
+	}
+}
+
+func TestStatesEqual(t *testing.T) {
+	a, b := testEqualState(), testEqualState()
+	require.True(t, StatesEqual(a, b))
+
+	b.Food = []Point{{3, 3}, {1, 1}, {2, 2}}
+	require.True(t, StatesEqual(a, b))
+
+	b.Snakes = []Snake{b.Snakes[1], b.Snakes[0]}
+	require.True(t, StatesEqual(a, b))
+
+	require.True(t, StatesEqual(nil, nil))
+	require.False(t, StatesEqual(a, nil))
+}
+
+func TestStatesNotEqual(t *testing.T) {
+	tests := []func(b *BoardState){
+		func(b *BoardState) { b.Width = 8 },
+		func(b *BoardState) { b.Food = b.Food[1:] },
+		func(b *BoardState) { b.Food[0] = Point{4, 4} },
+		func(b *BoardState) { b.Snakes = b.Snakes[1:] },
+		func(b *BoardState) { b.Snakes[0].ID = "three" },
+		func(b *BoardState) { b.Snakes[0].Health = 89 },
+		func(b *BoardState) { b.Snakes[0].Body = []Point{{0, 0}, {0, 1}} },
+		func(b *BoardState) { b.Snakes[1].EliminatedCause = NotEliminated },
+		func(b *BoardState) { b.Snakes[1].EliminatedBy = "" },
+		func(b *BoardState) { b.Snakes[1].ID = "one" },
+	}
+	for i, mutate := range tests {
+		a, b := testEqualState(), testEqualState()
+		mutate(b)
+		require.False(t, StatesEqual(a, b), "test %v", i)
+		require.False(t, StatesEqual(b, a), "test %v", i)
+	}
+}
+
+func TestPointsEqual(t *testing.T) {
+	require.True(t, PointsEqual(nil, []Point{}))
+	require.True(t, PointsEqual([]Point{{1, 1}, {1, 1}, {2, 2}}, []Point{{2, 2}, {1, 1}, {1, 1}}))
+	require.False(t, PointsEqual([]Point{{1, 1}, {1, 1}, {2, 2}}, []Point{{2, 2}, {2, 2}, {1, 1}}))
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// FoodSchedulePoint sets the minimum food from a turn on, until the next point.
+type FoodSchedulePoint struct {
+	Turn        int32
+	MinimumFood int32
+}
+
+// FoodScheduleRuleset wraps another ruleset and tops up food to a minimum that changes
+// over the course of the game, for example to add food late in the game to avoid stalemates.
+// The wrapped ruleset's own minimum food should be lower than the schedule's for it to decrease.
+type FoodScheduleRuleset struct {
+	Ruleset
+
+	Turn int32
+	// Schedule must be sorted by turn.
+	Schedule []FoodSchedulePoint
+	// Blocked are points food is never spawned on, such as hazard walls.
+	Blocked []Point
+	// Rand is the source of randomness, as for StandardRuleset.
+	Rand *rand.Rand `json:"-"`
+}
+
+func (r *FoodScheduleRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	for i := 1; i < len(r.Schedule); i++ {
+		if r.Schedule[i].Turn <= r.Schedule[i-1].Turn {
+			return nil, errors.New("food schedule must be sorted by turn")
+		}
+	}
+
+	nextBoardState, err := r.Ruleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	numCurrentFood := int32(len(nextBoardState.Food))
+	if minimumFood := r.MinimumFood(r.Turn); numCurrentFood < minimumFood {
+		standard := StandardRuleset{Rand: r.Rand, blocked: r.Blocked}
+		err = standard.spawnFood(nextBoardState, minimumFood-numCurrentFood)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nextBoardState, nil
+}
+
+// MinimumFood returns the minimum food scheduled for the turn, or zero before the first point.
+func (r *FoodScheduleRuleset) MinimumFood(turn int32) int32 {
+	var minimumFood int32
+	for _, p := range r.Schedule {
+		if p.Turn > turn {
+			break
+		}
+		minimumFood = p.MinimumFood
+	}
+	return minimumFood
+}
@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoodScheduleRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*FoodScheduleRuleset)(nil)
+}
+
+func TestFoodScheduleMinimumFood(t *testing.T) {
+	r := FoodScheduleRuleset{Schedule: []FoodSchedulePoint{{Turn: 10, MinimumFood: 1}, {Turn: 50, MinimumFood: 4}, {Turn: 100, MinimumFood: 2}}}
+
+	tests := []struct {
+		Turn        int32
+		MinimumFood int32
+	}{
+		{0, 0},
+		{9, 0},
+		{10, 1},
+		{49, 1},
+		{50, 4},
+		{99, 4},
+		{100, 2},
+		{1000, 2},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.MinimumFood, r.MinimumFood(test.Turn), "turn %v", test.Turn)
+	}
+}
+
+func TestFoodScheduleSpawn(t *testing.T) {
+	prev := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Snakes: []Snake{{ID: "one", Health: 100, Body: []Point{{1, 1}, {1, 0}, {1, 0}}}},
+	}
+	moves := []SnakeMove{{ID: "one", Move: MoveUp}}
+	schedule := []FoodSchedulePoint{{Turn: 0, MinimumFood: 1}, {Turn: 5, MinimumFood: 3}, {Turn: 10, MinimumFood: 0}}
+
+	tests := []struct {
+		Turn         int32
+		ExpectedFood int
+	}{
+		{1, 1},
+		{4, 1},
+		{5, 3},
+		{9, 3},
+		{10, 0},
+	}
+	for _, test := range tests {
+		r := FoodScheduleRuleset{
+			Ruleset:  &StandardRuleset{},
+			Turn:     test.Turn,
+			Schedule: schedule,
+		}
+		next, err := r.CreateNextBoardState(prev, moves)
+		require.NoError(t, err)
+		require.Len(t, next.Food, test.ExpectedFood, "turn %v", test.Turn)
+	}
+
+	r := FoodScheduleRuleset{Ruleset: &StandardRuleset{}, Schedule: []FoodSchedulePoint{{Turn: 5}, {Turn: 5}}}
+	_, err := r.CreateNextBoardState(prev, moves)
+	require.Equal(t, errors.New("food schedule must be sorted by turn"), err)
+}
+
+func TestFoodScheduleBlocked(t *testing.T) {
+	// Everything right of the snake is blocked, except for two points.
+	var blocked []Point
+	for x := int32(2); x < 5; x++ {
+		for y := int32(0); y < 5; y++ {
+			if p := (Point{x, y}); p != (Point{4, 0}) && p != (Point{4, 4}) {
+				blocked = append(blocked, p)
+			}
+		}
+	}
+	r := FoodScheduleRuleset{
+		Ruleset:  &StandardRuleset{},
+		Schedule: []FoodSchedulePoint{{Turn: 0, MinimumFood: 10}},
+		Blocked:  blocked,
+	}
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{{ID: "one", Health: 100, Body: []Point{{1, 1}, {1, 0}, {1, 0}}}},
+	}
+
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{{ID: "one", Move: MoveUp}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Point{{0, 0}, {0, 1}, {0, 3}, {0, 4}, {1, 4}, {4, 0}, {4, 4}}, next.Food)
+}
@@ -0,0 +1,50 @@
+package rules
+
+import (
+	"math/rand"
+)
+
+// FoodSpawnRuleset wraps another ruleset and allows more than one food to spawn per turn.
+type FoodSpawnRuleset struct {
+	Ruleset
+
+	// FoodPerSpawn is the number of food the board is topped up to when food spawns.
+	FoodPerSpawn    int32
+	FoodSpawnChance int32 // [0, 100]
+	// Blocked are points food is never spawned on, such as hazard walls.
+	Blocked []Point
+	// Rand is the source of randomness, as for StandardRuleset.
+	Rand *rand.Rand `json:"-"`
+}
+
+func (r *FoodSpawnRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	nextBoardState, err := r.Ruleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.maybeSpawnMultipleFood(nextBoardState)
+	if err != nil {
+		return nil, err
+	}
+
+	return nextBoardState, nil
+}
+
+func (r *FoodSpawnRuleset) maybeSpawnMultipleFood(b *BoardState) error {
+	if r.FoodPerSpawn < 1 || r.FoodSpawnChance <= 0 {
+		return nil
+	}
+	missing := r.FoodPerSpawn - int32(len(b.Food))
+	if missing <= 0 {
+		return nil
+	}
+	standard := StandardRuleset{Rand: r.Rand, blocked: r.Blocked}
+	if int32(standard.intn(100)) >= r.FoodSpawnChance {
+		return nil
+	}
+
+	// Eliminated snakes are ignored by getUnoccupiedPoints, so only live bodies (and their next moves) are avoided.
+	return standard.spawnFood(b, missing)
+}
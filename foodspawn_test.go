@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoodSpawnRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*FoodSpawnRuleset)(nil)
+}
+
+func TestFoodSpawnMultiplePerTurn(t *testing.T) {
+	r := FoodSpawnRuleset{
+		Ruleset:         &StandardRuleset{},
+		FoodPerSpawn:    3,
+		FoodSpawnChance: 100,
+	}
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{
+				ID:     "one",
+				Body:   []Point{{2, 2}, {2, 1}, {2, 0}},
+				Health: 100,
+			},
+		},
+	}
+
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{{ID: "one", Move: MoveUp}})
+	require.NoError(t, err)
+	require.Len(t, next.Food, 3)
+	for _, food := range next.Food {
+		for _, p := range next.Snakes[0].Body {
+			require.NotEqual(t, p, food)
+		}
+	}
+}
+
+func TestFoodSpawnTopsUpToTarget(t *testing.T) {
+	r := FoodSpawnRuleset{
+		Ruleset:         &StandardRuleset{},
+		FoodPerSpawn:    3,
+		FoodSpawnChance: 100,
+	}
+	state := &BoardState{
+		Width:  7,
+		Height: 7,
+		Food:   []Point{{6, 6}},
+		Snakes: []Snake{
+			{
+				ID:     "one",
+				Body:   []Point{{2, 2}, {2, 1}, {2, 0}},
+				Health: 100,
+			},
+		},
+	}
+
+	// The food already on the board counts towards the target, and no more spawns once it is reached.
+	moves := []string{MoveRight, MoveDown, MoveLeft, MoveUp}
+	for turn := 0; turn < 8; turn++ {
+		next, err := r.CreateNextBoardState(state, []SnakeMove{{ID: "one", Move: moves[turn%len(moves)]}})
+		require.NoError(t, err)
+		require.Len(t, next.Food, 3, "turn %v", turn)
+		state = next
+	}
+}
+
+func TestFoodSpawnZeroChance(t *testing.T) {
+	r := FoodSpawnRuleset{
+		Ruleset:         &StandardRuleset{},
+		FoodPerSpawn:    3,
+		FoodSpawnChance: 0,
+	}
+	b := &BoardState{Width: 5, Height: 5}
+	require.NoError(t, r.maybeSpawnMultipleFood(b))
+	require.Len(t, b.Food, 0)
+}
+
+func TestFoodSpawnBlocked(t *testing.T) {
+	// Everything right of the snake is blocked, except for two points.
+	var blocked []Point
+	for x := int32(2); x < 5; x++ {
+		for y := int32(0); y < 5; y++ {
+			if p := (Point{x, y}); p != (Point{4, 0}) && p != (Point{4, 4}) {
+				blocked = append(blocked, p)
+			}
+		}
+	}
+	r := FoodSpawnRuleset{
+		Ruleset:         &StandardRuleset{},
+		FoodPerSpawn:    10,
+		FoodSpawnChance: 100,
+		Blocked:         blocked,
+	}
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{{ID: "one", Health: 100, Body: []Point{{1, 1}, {1, 0}, {1, 0}}}},
+	}
+
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{{ID: "one", Move: MoveUp}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Point{{0, 0}, {0, 1}, {0, 3}, {0, 4}, {1, 4}, {4, 0}, {4, 4}}, next.Food)
+}
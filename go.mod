@@ -8,4 +8,5 @@ require (
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.4.0
+	github.com/vmihailenco/msgpack/v4 v4.3.12
 )
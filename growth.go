@@ -0,0 +1,44 @@
+package rules
+
+import "fmt"
+
+// GrowthError reports a snake whose length changed other than by eating.
+type GrowthError struct {
+	SnakeID  string
+	From     int
+	To       int
+	Expected int
+}
+
+func (e *GrowthError) Error() string {
+	return fmt.Sprintf("snake %v length changed from %v to %v, expected %v", e.SnakeID, e.From, e.To, e.Expected)
+}
+
+// CheckGrowth returns a *GrowthError if a snake still in the game didn't grow by exactly one
+// on food or keep its length otherwise. Constrictor and shared squad lengths fail by design.
+func CheckGrowth(prev, next *BoardState) error {
+	food := make(map[Point]bool, len(prev.Food))
+	for _, f := range prev.Food {
+		food[f] = true
+	}
+	prevLengths := make(map[string]int, len(prev.Snakes))
+	for _, snake := range prev.Snakes {
+		if snake.EliminatedCause == NotEliminated {
+			prevLengths[snake.ID] = len(snake.Body)
+		}
+	}
+	for _, snake := range next.Snakes {
+		prevLength, ok := prevLengths[snake.ID]
+		if !ok || snake.EliminatedCause != NotEliminated || len(snake.Body) == 0 {
+			continue
+		}
+		expected := prevLength
+		if food[snake.Body[0]] {
+			expected++
+		}
+		if len(snake.Body) != expected {
+			return &GrowthError{SnakeID: snake.ID, From: prevLength, To: len(snake.Body), Expected: expected}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGrowth(t *testing.T) {
+	r := StandardRuleset{}
+	prev := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Food:   []Point{{1, 2}},
+		Snakes: []Snake{
+			{ID: "eater", Health: 50, Body: []Point{{1, 1}, {1, 0}, {0, 0}}},
+			{ID: "other", Health: 50, Body: []Point{{5, 1}, {5, 0}, {6, 0}}},
+		},
+	}
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{{ID: "eater", Move: MoveUp}, {ID: "other", Move: MoveUp}})
+	require.NoError(t, err)
+	require.NoError(t, CheckGrowth(prev, next))
+
+	// A rule bug that drops a segment is flagged.
+	next.Snakes[1].Body = next.Snakes[1].Body[:2]
+	require.EqualError(t, CheckGrowth(prev, next), "snake other length changed from 3 to 2, expected 3")
+
+	// So is a snake that ate without growing.
+	next, err = r.CreateNextBoardState(prev, []SnakeMove{{ID: "eater", Move: MoveUp}, {ID: "other", Move: MoveUp}})
+	require.NoError(t, err)
+	next.Snakes[0].Body = next.Snakes[0].Body[:3]
+	require.EqualError(t, CheckGrowth(prev, next), "snake eater length changed from 3 to 3, expected 4")
+}
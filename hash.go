@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+)
+
+// HashState returns a hex SHA-256 digest of a board state and the hazards on it, which aren't
+// part of the state. States that are equal according to StatesEqual have the same hash with
+// the same hazards, so the order of food, hazards and snakes doesn't matter.
+func HashState(state *BoardState, hazards []Point) string {
+	h := sha256.New()
+	write := func(v interface{}) {
+		_ = binary.Write(h, binary.LittleEndian, v)
+	}
+	writeString := func(s string) {
+		write(int32(len(s)))
+		h.Write([]byte(s))
+	}
+	writePoints := func(points []Point) {
+		write(int32(len(points)))
+		for _, p := range points {
+			write(p.X)
+			write(p.Y)
+		}
+	}
+
+	write(state.Width)
+	write(state.Height)
+
+	writePoints(sortedPoints(state.Food))
+	writePoints(sortedPoints(hazards))
+
+	snakes := append([]Snake{}, state.Snakes...)
+	sort.Slice(snakes, func(i, j int) bool { return snakes[i].ID < snakes[j].ID })
+	write(int32(len(snakes)))
+	for _, snake := range snakes {
+		writeString(snake.ID)
+		write(snake.Health)
+		writeString(snake.EliminatedCause)
+		writeString(snake.EliminatedBy)
+		writePoints(snake.Body)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortedPoints(points []Point) []Point {
+	sorted := append([]Point{}, points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	return sorted
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashState(t *testing.T) {
+	state := func() *BoardState {
+		return &BoardState{
+			Width:  7,
+			Height: 7,
+			Food:   []Point{{1, 1}, {5, 5}},
+			Snakes: []Snake{
+				{ID: "one", Health: 90, Body: []Point{{2, 2}, {2, 1}}},
+				{ID: "two", Health: 80, Body: []Point{{4, 4}, {4, 3}}},
+			},
+		}
+	}
+
+	hazards := []Point{{0, 0}, {0, 6}}
+
+	a := HashState(state(), hazards)
+	require.Len(t, a, 64)
+	require.Equal(t, a, HashState(state(), hazards))
+
+	// Order doesn't matter, like in StatesEqual.
+	reordered := state()
+	reordered.Food[0], reordered.Food[1] = reordered.Food[1], reordered.Food[0]
+	reordered.Snakes[0], reordered.Snakes[1] = reordered.Snakes[1], reordered.Snakes[0]
+	require.Equal(t, a, HashState(reordered, []Point{{0, 6}, {0, 0}}))
+
+	// Hazards are part of the hash, and no hazards hash the same whether nil or empty.
+	require.NotEqual(t, a, HashState(state(), hazards[:1]))
+	require.Equal(t, HashState(state(), nil), HashState(state(), []Point{}))
+
+	changes := []func(*BoardState){
+		func(s *BoardState) { s.Width++ },
+		func(s *BoardState) { s.Food = s.Food[:1] },
+		func(s *BoardState) { s.Snakes[0].Health-- },
+		func(s *BoardState) { s.Snakes[1].Body[1] = Point{4, 5} },
+		func(s *BoardState) { s.Snakes[1].EliminatedCause = EliminatedByCollision },
+		func(s *BoardState) { s.Snakes[1].EliminatedBy = "one" },
+		func(s *BoardState) { s.Snakes[0].ID = "three" },
+	}
+	for i, change := range changes {
+		changed := state()
+		change(changed)
+		require.NotEqual(t, a, HashState(changed, hazards), "change %v", i)
+	}
+}
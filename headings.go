@@ -0,0 +1,53 @@
+package rules
+
+import "math/rand"
+
+// RandomizeHeadings gives snakes that start stacked on a single point a random heading, by
+// moving their neck and tail one point behind the head, so that snakes don't all effectively
+// start facing the same way. Snakes are left stacked if no point behind the head is free.
+func RandomizeHeadings(b *BoardState, r *rand.Rand) {
+	occupied := make(map[Point]bool)
+	for _, f := range b.Food {
+		occupied[f] = true
+	}
+	for _, snake := range b.Snakes {
+		for _, p := range snake.Body {
+			occupied[p] = true
+		}
+	}
+
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		if len(snake.Body) < 2 || !isStacked(snake.Body) {
+			continue
+		}
+		head := snake.Body[0]
+		behind := make([]Point, len(directions))
+		for j, heading := range directions {
+			dx, dy, _ := DirectionVector(heading)
+			behind[j] = Point{head.X - dx, head.Y - dy}
+		}
+		r.Shuffle(len(behind), func(i, j int) {
+			behind[i], behind[j] = behind[j], behind[i]
+		})
+		for _, neck := range behind {
+			if neck.X < 0 || neck.X >= b.Width || neck.Y < 0 || neck.Y >= b.Height || occupied[neck] {
+				continue
+			}
+			for j := 1; j < len(snake.Body); j++ {
+				snake.Body[j] = neck
+			}
+			occupied[neck] = true
+			break
+		}
+	}
+}
+
+func isStacked(body []Point) bool {
+	for _, p := range body[1:] {
+		if p != body[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomizeHeadings(t *testing.T) {
+	r := StandardRuleset{}
+	headings := make(map[Point]bool)
+	for seed := int64(0); seed < 10; seed++ {
+		rand.Seed(seed)
+		state, err := r.CreateInitialBoardState(BoardSizeSmall, BoardSizeSmall, []string{"one", "two", "three"})
+		require.NoError(t, err)
+
+		RandomizeHeadings(state, rand.New(rand.NewSource(seed)))
+		occupied := make(map[Point]string)
+		for _, f := range state.Food {
+			occupied[f] = "food"
+		}
+		for _, snake := range state.Snakes {
+			require.Len(t, snake.Body, SnakeStartSize)
+			head, neck := snake.Body[0], snake.Body[1]
+			heading := Point{head.X - neck.X, head.Y - neck.Y}
+			require.Contains(t, []Point{{0, 1}, {0, -1}, {1, 0}, {-1, 0}}, heading)
+			require.Equal(t, neck, snake.Body[2])
+			require.True(t, neck.X >= 0 && neck.X < state.Width && neck.Y >= 0 && neck.Y < state.Height)
+			for _, p := range []Point{head, neck} {
+				require.Empty(t, occupied[p])
+				occupied[p] = snake.ID
+			}
+			headings[heading] = true
+		}
+	}
+	require.Greater(t, len(headings), 1)
+}
+
+func TestRandomizeHeadingsNoRoom(t *testing.T) {
+	state := &BoardState{
+		Width:  1,
+		Height: 1,
+		Snakes: []Snake{{ID: "one", Body: []Point{{0, 0}, {0, 0}, {0, 0}}}},
+	}
+	RandomizeHeadings(state, rand.New(rand.NewSource(1)))
+	require.Equal(t, []Point{{0, 0}, {0, 0}, {0, 0}}, state.Snakes[0].Body)
+}
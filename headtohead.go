@@ -0,0 +1,84 @@
+package rules
+
+import "sort"
+
+// GameRecord is the board state of every turn of a completed game, starting with the
+// initial board. States[i] is the board at turn StartTurn+i.
+type GameRecord struct {
+	StartTurn int32
+	States    []*BoardState
+}
+
+// HeadToHead is a single head-to-head collision between two or more snakes.
+type HeadToHead struct {
+	Turn  int32
+	Point Point
+	// Winner is the ID of the snake that survived the collision, or empty if all were eliminated.
+	Winner string
+	// LengthDifference is how much longer the winner was than the longest loser, 0 for a draw.
+	LengthDifference int32
+	Snakes           []HeadToHeadSnake
+}
+
+// HeadToHeadSnake is a snake in a head-to-head collision, with its length on arrival.
+type HeadToHeadSnake struct {
+	ID     string
+	Length int32
+}
+
+// AuditHeadToHeads lists every head-to-head collision in a game, in turn order, to show
+// how often snakes rely on winning (or risking) head-to-heads.
+func AuditHeadToHeads(record GameRecord) []HeadToHead {
+	var audit []HeadToHead
+	for i := 1; i < len(record.States); i++ {
+		prev, next := record.States[i-1], record.States[i]
+
+		alive := make(map[string]bool, len(prev.Snakes))
+		for _, snake := range prev.Snakes {
+			if snake.EliminatedCause == NotEliminated {
+				alive[snake.ID] = true
+			}
+		}
+
+		heads := make(map[Point][]Snake)
+		var points []Point
+		for _, snake := range next.Snakes {
+			if !alive[snake.ID] || len(snake.Body) == 0 {
+				continue
+			}
+			head := snake.Body[0]
+			if len(heads[head]) == 0 {
+				points = append(points, head)
+			}
+			heads[head] = append(heads[head], snake)
+		}
+
+		for _, point := range points {
+			snakes := heads[point]
+			if len(snakes) < 2 {
+				continue
+			}
+			collision := HeadToHead{Turn: record.StartTurn + int32(i), Point: point}
+			var winnerLength, longestLoser int32
+			for _, snake := range snakes {
+				length := int32(len(snake.Body))
+				collision.Snakes = append(collision.Snakes, HeadToHeadSnake{ID: snake.ID, Length: length})
+				// A snake eliminated for another reason, e.g. starving on the same turn, did not win.
+				if snake.EliminatedCause == NotEliminated {
+					collision.Winner = snake.ID
+					winnerLength = length
+				} else if length > longestLoser {
+					longestLoser = length
+				}
+			}
+			if collision.Winner != "" {
+				collision.LengthDifference = winnerLength - longestLoser
+			}
+			sort.Slice(collision.Snakes, func(a, b int) bool {
+				return collision.Snakes[a].ID < collision.Snakes[b].ID
+			})
+			audit = append(audit, collision)
+		}
+	}
+	return audit
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHeadToHeads(t *testing.T) {
+	r := StandardRuleset{}
+	initial := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Snakes: []Snake{
+			{ID: "long", Health: 100, Body: []Point{{1, 3}, {0, 3}, {0, 2}, {0, 1}, {0, 0}}},
+			{ID: "short", Health: 100, Body: []Point{{3, 3}, {4, 3}, {5, 3}}},
+			{ID: "other", Health: 100, Body: []Point{{6, 6}, {6, 5}, {6, 4}}},
+		},
+	}
+	next, err := r.CreateNextBoardState(initial, []SnakeMove{
+		{ID: "long", Move: MoveRight},
+		{ID: "short", Move: MoveLeft},
+		{ID: "other", Move: MoveLeft},
+	})
+	require.NoError(t, err)
+	require.Equal(t, EliminatedByHeadToHeadCollision, next.Snakes[1].EliminatedCause)
+
+	audit := AuditHeadToHeads(GameRecord{StartTurn: 10, States: []*BoardState{initial, next}})
+	require.Equal(t, []HeadToHead{{
+		Turn:             11,
+		Point:            Point{2, 3},
+		Winner:           "long",
+		LengthDifference: 2,
+		Snakes:           []HeadToHeadSnake{{ID: "long", Length: 5}, {ID: "short", Length: 3}},
+	}}, audit)
+}
+
+func TestAuditHeadToHeadsDraw(t *testing.T) {
+	r := StandardRuleset{}
+	initial := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Snakes: []Snake{
+			{ID: "one", Health: 100, Body: []Point{{1, 3}, {0, 3}, {0, 2}}},
+			{ID: "two", Health: 100, Body: []Point{{3, 3}, {4, 3}, {5, 3}}},
+		},
+	}
+	next, err := r.CreateNextBoardState(initial, []SnakeMove{
+		{ID: "one", Move: MoveRight},
+		{ID: "two", Move: MoveLeft},
+	})
+	require.NoError(t, err)
+
+	// Eliminated snakes stay on the board, but are not audited again.
+	audit := AuditHeadToHeads(GameRecord{States: []*BoardState{initial, next, next}})
+	require.Len(t, audit, 1)
+	require.Equal(t, "", audit[0].Winner)
+	require.Equal(t, int32(0), audit[0].LengthDifference)
+	require.Equal(t, int32(1), audit[0].Turn)
+}
+
+func TestAuditHeadToHeadsOtherElimination(t *testing.T) {
+	initial := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Snakes: []Snake{
+			{ID: "long", Health: 1, Body: []Point{{1, 3}, {0, 3}, {0, 2}, {0, 1}}},
+			{ID: "short", Health: 100, Body: []Point{{3, 3}, {4, 3}, {5, 3}}},
+		},
+	}
+	// The longer snake starved on the turn it would have won the head-to-head.
+	next := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Snakes: []Snake{
+			{ID: "long", Health: 0, Body: []Point{{2, 3}, {1, 3}, {0, 3}, {0, 2}}, EliminatedCause: EliminatedByOutOfHealth},
+			{ID: "short", Health: 99, Body: []Point{{2, 3}, {3, 3}, {4, 3}}, EliminatedCause: EliminatedByHeadToHeadCollision},
+		},
+	}
+
+	audit := AuditHeadToHeads(GameRecord{States: []*BoardState{initial, next}})
+	require.Len(t, audit, 1)
+	require.Equal(t, "", audit[0].Winner)
+	require.Equal(t, int32(0), audit[0].LengthDifference)
+}
@@ -0,0 +1,41 @@
+package rules
+
+// HealthDecayRuleset wraps another ruleset and changes how much health snakes lose each turn
+// they don't eat, from the standard 1 to HealthDecay, which should be at least 1. Snakes that
+// run out of health because of the extra decay are eliminated after the wrapped ruleset's
+// collisions are resolved.
+type HealthDecayRuleset struct {
+	Ruleset
+
+	HealthDecay int32
+}
+
+func (r *HealthDecayRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	nextBoardState, err := r.Ruleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	r.decayHealth(nextBoardState)
+
+	return nextBoardState, nil
+}
+
+func (r *HealthDecayRuleset) decayHealth(b *BoardState) {
+	if r.HealthDecay <= 1 {
+		return
+	}
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		// Snakes that ate are back at full health, and the wrapped ruleset already took 1.
+		if snake.EliminatedCause != NotEliminated || snake.Health >= SnakeMaxHealth {
+			continue
+		}
+		snake.Health -= r.HealthDecay - 1
+		if snake.Health <= 0 {
+			snake.Health = 0
+			snake.EliminatedCause = EliminatedByOutOfHealth
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthDecayRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*HealthDecayRuleset)(nil)
+}
+
+func TestHealthDecay(t *testing.T) {
+	r := HealthDecayRuleset{
+		Ruleset:     &StandardRuleset{},
+		HealthDecay: 5,
+	}
+	state := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Food:   []Point{{5, 2}},
+		Snakes: []Snake{
+			{ID: "hungry", Health: 100, Body: []Point{{1, 1}, {1, 0}, {1, 0}}},
+			{ID: "eater", Health: 50, Body: []Point{{5, 1}, {5, 0}, {5, 0}}},
+		},
+	}
+	moves := []SnakeMove{{ID: "hungry", Move: MoveUp}, {ID: "eater", Move: MoveUp}}
+
+	next, err := r.CreateNextBoardState(state, moves)
+	require.NoError(t, err)
+	require.Equal(t, int32(95), next.Snakes[0].Health)
+	require.Equal(t, int32(SnakeMaxHealth), next.Snakes[1].Health)
+
+	next, err = r.CreateNextBoardState(next, moves)
+	require.NoError(t, err)
+	require.Equal(t, int32(90), next.Snakes[0].Health)
+	require.Equal(t, int32(95), next.Snakes[1].Health)
+}
+
+func TestHealthDecayOutOfHealth(t *testing.T) {
+	r := HealthDecayRuleset{
+		Ruleset:     &StandardRuleset{},
+		HealthDecay: 5,
+	}
+	state := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Snakes: []Snake{
+			{ID: "one", Health: 4, Body: []Point{{1, 1}, {1, 0}, {1, 0}}},
+			{ID: "two", Health: 100, Body: []Point{{5, 1}, {5, 0}, {5, 0}}},
+		},
+	}
+	next, err := r.CreateNextBoardState(state, []SnakeMove{{ID: "one", Move: MoveUp}, {ID: "two", Move: MoveUp}})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), next.Snakes[0].Health)
+	require.Equal(t, EliminatedByOutOfHealth, next.Snakes[0].EliminatedCause)
+	require.Equal(t, NotEliminated, next.Snakes[1].EliminatedCause)
+}
@@ -0,0 +1,39 @@
+package rules
+
+// WinnerMargin returns by how much the winner of a finished game leads the runner-up in length
+// and in health, for scoring systems that reward winning by a wide margin. The winner is the
+// only snake left in the game, and the runner-up is the longest of the other snakes, the
+// healthiest one among equally long snakes, with the length and health it was eliminated with.
+// Both margins are zero if the game has no single winner or the winner had no opponents.
+func WinnerMargin(final *BoardState) (lengthMargin, healthMargin int32) {
+	var winner *Snake
+	for i := range final.Snakes {
+		if final.Snakes[i].EliminatedCause != NotEliminated {
+			continue
+		}
+		if winner != nil {
+			return 0, 0
+		}
+		winner = &final.Snakes[i]
+	}
+	if winner == nil {
+		return 0, 0
+	}
+
+	var runnerUp *Snake
+	for i := range final.Snakes {
+		snake := &final.Snakes[i]
+		if snake == winner {
+			continue
+		}
+		if runnerUp == nil || len(snake.Body) > len(runnerUp.Body) ||
+			(len(snake.Body) == len(runnerUp.Body) && snake.Health > runnerUp.Health) {
+			runnerUp = snake
+		}
+	}
+	if runnerUp == nil {
+		return 0, 0
+	}
+
+	return int32(len(winner.Body) - len(runnerUp.Body)), winner.Health - runnerUp.Health
+}
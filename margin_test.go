@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWinnerMargin(t *testing.T) {
+	final := &BoardState{
+		Width:  11,
+		Height: 11,
+		Snakes: []Snake{
+			{ID: "one", Health: 40, Body: []Point{{1, 1}, {1, 2}, {1, 3}}, EliminatedCause: EliminatedByCollision, EliminatedBy: "two"},
+			{ID: "two", Health: 85, Body: []Point{{5, 5}, {5, 6}, {5, 7}, {5, 8}, {5, 9}, {6, 9}}},
+			{ID: "three", Health: 70, Body: []Point{{8, 1}, {8, 2}, {8, 3}, {8, 4}}, EliminatedCause: EliminatedByOutOfBounds},
+			{ID: "four", Health: 90, Body: []Point{{9, 8}, {9, 9}, {9, 10}, {8, 10}}, EliminatedCause: EliminatedByHeadToHeadCollision, EliminatedBy: "two"},
+		},
+	}
+
+	// "four" is the runner-up, as long as "three" but healthier.
+	lengthMargin, healthMargin := WinnerMargin(final)
+	require.Equal(t, int32(2), lengthMargin)
+	require.Equal(t, int32(-5), healthMargin)
+
+	// A starved runner-up had no health left.
+	final.Snakes[3].Health = 0
+	final.Snakes[2].Health = 0
+	lengthMargin, healthMargin = WinnerMargin(final)
+	require.Equal(t, int32(2), lengthMargin)
+	require.Equal(t, int32(85), healthMargin)
+}
+
+func TestWinnerMarginNoWinner(t *testing.T) {
+	final := &BoardState{
+		Snakes: []Snake{
+			{ID: "one", Health: 40, Body: []Point{{1, 1}}},
+			{ID: "two", Health: 85, Body: []Point{{5, 5}}},
+		},
+	}
+	lengthMargin, healthMargin := WinnerMargin(final)
+	require.Zero(t, lengthMargin)
+	require.Zero(t, healthMargin)
+
+	// A draw, with every snake eliminated.
+	final.Snakes[0].EliminatedCause = EliminatedByHeadToHeadCollision
+	final.Snakes[1].EliminatedCause = EliminatedByHeadToHeadCollision
+	lengthMargin, healthMargin = WinnerMargin(final)
+	require.Zero(t, lengthMargin)
+	require.Zero(t, healthMargin)
+
+	// A solo game has no runner-up.
+	lengthMargin, healthMargin = WinnerMargin(&BoardState{Snakes: []Snake{{ID: "one", Health: 50, Body: []Point{{1, 1}}}}})
+	require.Zero(t, lengthMargin)
+	require.Zero(t, healthMargin)
+}
@@ -0,0 +1,53 @@
+package rules
+
+// MaxFoodRuleset wraps another ruleset and stops food spawning once the board holds
+// MaxFood food, so that boards don't flood with food in long games.
+type MaxFoodRuleset struct {
+	Ruleset
+
+	MaxFood int32
+}
+
+func (r *MaxFoodRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	nextBoardState, err := r.Ruleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	r.removeSpawnedFood(prevState, nextBoardState)
+
+	return nextBoardState, nil
+}
+
+// removeSpawnedFood removes food spawned this turn beyond the cap. Food that was
+// already on the board is never removed.
+func (r *MaxFoodRuleset) removeSpawnedFood(prev *BoardState, b *BoardState) {
+	if r.MaxFood < 0 || int32(len(b.Food)) <= r.MaxFood {
+		return
+	}
+	existing := make(map[Point]bool, len(prev.Food))
+	for _, f := range prev.Food {
+		existing[f] = true
+	}
+	numSpawned := int32(0)
+	for _, f := range b.Food {
+		if !existing[f] {
+			numSpawned++
+		}
+	}
+	excess := int32(len(b.Food)) - r.MaxFood
+	if excess > numSpawned {
+		excess = numSpawned
+	}
+
+	// Remove the most recently spawned food first.
+	food := append([]Point{}, b.Food...)
+	for i := len(food) - 1; i >= 0 && excess > 0; i-- {
+		if !existing[food[i]] {
+			food = append(food[:i], food[i+1:]...)
+			excess--
+		}
+	}
+	b.Food = food
+}
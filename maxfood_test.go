@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxFoodRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*MaxFoodRuleset)(nil)
+}
+
+func TestMaxFoodKeepsExistingFood(t *testing.T) {
+	r := MaxFoodRuleset{
+		Ruleset: &StandardRuleset{MinimumFood: 5},
+		MaxFood: 2,
+	}
+	prev := &BoardState{
+		Width:  BoardSizeSmall,
+		Height: BoardSizeSmall,
+		Food:   []Point{{0, 6}, {6, 6}, {6, 0}},
+		Snakes: []Snake{{ID: "one", Health: 100, Body: []Point{{3, 3}, {3, 2}, {3, 1}}}},
+	}
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{{ID: "one", Move: MoveUp}})
+	require.NoError(t, err)
+	require.Equal(t, prev.Food, next.Food)
+}
+
+func TestMaxFoodCap(t *testing.T) {
+	rand.Seed(1)
+	r := MaxFoodRuleset{
+		Ruleset: &FoodSpawnRuleset{
+			Ruleset:         &StandardRuleset{FoodSpawnChance: 100, MinimumFood: 1},
+			FoodPerSpawn:    3,
+			FoodSpawnChance: 100,
+		},
+		MaxFood: 4,
+	}
+	state := &BoardState{
+		Width:  BoardSizeMedium,
+		Height: BoardSizeMedium,
+		Snakes: []Snake{{ID: "one", Health: 100, Body: []Point{{1, 1}, {1, 0}, {1, 0}}}},
+	}
+
+	square := []string{MoveUp, MoveRight, MoveDown, MoveLeft}
+	var maxFood int
+	for turn := 0; turn < 100; turn++ {
+		next, err := r.CreateNextBoardState(state, []SnakeMove{{ID: "one", Move: square[turn%4]}})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(next.Food), 4, "turn %v", turn)
+		if len(next.Food) > maxFood {
+			maxFood = len(next.Food)
+		}
+		state = next
+	}
+	require.Equal(t, 4, maxFood)
+}
@@ -0,0 +1,90 @@
+package rules
+
+// LegalMoves returns the moves that don't immediately take the snake's head out of bounds
+// or back into its own neck. Other collisions aren't considered.
+func LegalMoves(state *BoardState, snakeID string) []string {
+	return legalMoves(state, snakeID, false)
+}
+
+// WrappedLegalMoves is LegalMoves on a wrapped board, where moving off one edge of the board
+// continues from the opposite edge, so only moving back into the neck is illegal.
+func WrappedLegalMoves(state *BoardState, snakeID string) []string {
+	return legalMoves(state, snakeID, true)
+}
+
+func legalMoves(state *BoardState, snakeID string, wrap bool) []string {
+	var snake *Snake
+	for i := 0; i < len(state.Snakes); i++ {
+		if state.Snakes[i].ID == snakeID {
+			snake = &state.Snakes[i]
+			break
+		}
+	}
+	if snake == nil || snake.EliminatedCause != NotEliminated || len(snake.Body) == 0 {
+		return nil
+	}
+
+	head := snake.Body[0]
+	moves := []string{}
+	for _, move := range directions {
+		dx, dy, _ := DirectionVector(move)
+		p := Point{head.X + dx, head.Y + dy}
+		if wrap && state.Width > 0 && state.Height > 0 {
+			p = WrapPoint(p, state.Width, state.Height)
+		}
+		if p.X < 0 || p.X >= state.Width || p.Y < 0 || p.Y >= state.Height {
+			continue
+		}
+		if len(snake.Body) > 1 && snake.Body[1] == p {
+			continue
+		}
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+// directions are the four moves, in the order moves are considered.
+var directions = []string{MoveUp, MoveDown, MoveLeft, MoveRight}
+
+// DirectionVector returns the change in coordinates of a move, where y points up.
+// It returns false for anything other than the four moves.
+func DirectionVector(move string) (dx, dy int32, ok bool) {
+	switch move {
+	case MoveUp:
+		return 0, 1, true
+	case MoveDown:
+		return 0, -1, true
+	case MoveLeft:
+		return -1, 0, true
+	case MoveRight:
+		return 1, 0, true
+	}
+	return 0, 0, false
+}
+
+// HeadingAfter returns the direction a snake is facing, which is the move that took its neck
+// to its head. A neck more than one point away is taken to have wrapped around the board. It
+// returns an empty string for bodies that are stacked, like at the start of a game.
+func HeadingAfter(body []Point) string {
+	if len(body) < 2 {
+		return ""
+	}
+	dx, dy := body[0].X-body[1].X, body[0].Y-body[1].Y
+	if dx > 1 || dx < -1 {
+		dx = -dx
+	}
+	if dy > 1 || dy < -1 {
+		dy = -dy
+	}
+	switch {
+	case dx == 0 && dy > 0:
+		return MoveUp
+	case dx == 0 && dy < 0:
+		return MoveDown
+	case dy == 0 && dx < 0:
+		return MoveLeft
+	case dy == 0 && dx > 0:
+		return MoveRight
+	}
+	return ""
+}
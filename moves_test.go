@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalMoves(t *testing.T) {
+	state := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "cornered", Body: []Point{{0, 0}, {1, 0}, {2, 0}}},
+			{ID: "stacked", Body: []Point{{2, 2}, {2, 2}, {2, 2}}},
+			{ID: "edge", Body: []Point{{4, 2}, {4, 1}, {4, 0}}},
+			{ID: "eliminated", Body: []Point{{2, 4}, {2, 3}}, EliminatedCause: EliminatedByOutOfHealth},
+		},
+	}
+
+	tests := []struct {
+		ID       string
+		Expected []string
+	}{
+		{"cornered", []string{MoveUp}},
+		{"stacked", []string{MoveUp, MoveDown, MoveLeft, MoveRight}},
+		{"edge", []string{MoveUp, MoveLeft}},
+		{"eliminated", nil},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.Expected, LegalMoves(state, test.ID), test.ID)
+	}
+}
+
+func TestWrappedLegalMoves(t *testing.T) {
+	state := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "cornered", Body: []Point{{0, 0}, {1, 0}, {2, 0}}},
+			{ID: "edge", Body: []Point{{4, 2}, {4, 1}, {4, 0}}},
+			// The neck is across the left edge.
+			{ID: "wrapped", Body: []Point{{4, 3}, {0, 3}, {1, 3}}},
+			{ID: "eliminated", Body: []Point{{2, 4}, {2, 3}}, EliminatedCause: EliminatedByOutOfHealth},
+		},
+	}
+
+	tests := []struct {
+		ID       string
+		Expected []string
+	}{
+		{"cornered", []string{MoveUp, MoveDown, MoveLeft}},
+		{"edge", []string{MoveUp, MoveLeft, MoveRight}},
+		{"wrapped", []string{MoveUp, MoveDown, MoveLeft}},
+		{"eliminated", nil},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.Expected, WrappedLegalMoves(state, test.ID), test.ID)
+	}
+}
+
+func TestDirectionVector(t *testing.T) {
+	tests := []struct {
+		Move   string
+		DX, DY int32
+		OK     bool
+	}{
+		{MoveUp, 0, 1, true},
+		{MoveDown, 0, -1, true},
+		{MoveLeft, -1, 0, true},
+		{MoveRight, 1, 0, true},
+		{"north", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, test := range tests {
+		dx, dy, ok := DirectionVector(test.Move)
+		require.Equal(t, test.DX, dx, test.Move)
+		require.Equal(t, test.DY, dy, test.Move)
+		require.Equal(t, test.OK, ok, test.Move)
+	}
+}
+
+func TestHeadingAfter(t *testing.T) {
+	tests := []struct {
+		Body     []Point
+		Expected string
+	}{
+		{[]Point{{2, 3}, {2, 2}, {2, 1}}, MoveUp},
+		{[]Point{{2, 1}, {2, 2}, {2, 3}}, MoveDown},
+		{[]Point{{1, 2}, {2, 2}}, MoveLeft},
+		{[]Point{{3, 2}, {2, 2}, {2, 1}}, MoveRight},
+		// Necks on the far side of the board wrapped around it.
+		{[]Point{{0, 2}, {10, 2}}, MoveRight},
+		{[]Point{{2, 10}, {2, 0}}, MoveDown},
+		{[]Point{{2, 2}, {2, 2}, {2, 2}}, ""},
+		{[]Point{{2, 2}}, ""},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.Expected, HeadingAfter(test.Body), "%v", test.Body)
+	}
+
+	// Following the heading from the neck leads back to the head.
+	body := []Point{{4, 5}, {4, 4}, {3, 4}}
+	dx, dy, ok := DirectionVector(HeadingAfter(body))
+	require.True(t, ok)
+	require.Equal(t, body[0], Point{body[1].X + dx, body[1].Y + dy})
+}
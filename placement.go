@@ -0,0 +1,73 @@
+package rules
+
+import "math/rand"
+
+// Placement strategies for RepositionSnakes.
+const (
+	// PlacementCorners starts snakes one point in from the corners, opposite corners first.
+	PlacementCorners = "corners"
+	// PlacementEdges starts snakes one point in from the middle of each edge, opposite edges first.
+	PlacementEdges = "edges"
+	// PlacementRandom starts snakes on random distinct points, of the same parity like the
+	// standard random placement, so that no snake can reach another first by parity alone.
+	PlacementRandom = "random"
+)
+
+// IsPlacement reports whether s is a known placement strategy.
+func IsPlacement(s string) bool {
+	return s == PlacementCorners || s == PlacementEdges || s == PlacementRandom
+}
+
+// RepositionSnakes moves the snakes of an initial board to start positions chosen by the
+// strategy, stacked like at the start of a game, and removes any food under them.
+// Corners and edges have room for four snakes. Random placement uses r, or the global source
+// if r is nil.
+func RepositionSnakes(b *BoardState, strategy string, r *rand.Rand) error {
+	mn, mdX, mdY, mxX, mxY := int32(1), (b.Width-1)/2, (b.Height-1)/2, b.Width-2, b.Height-2
+	var points []Point
+	switch strategy {
+	case PlacementCorners:
+		points = []Point{{mn, mn}, {mxX, mxY}, {mn, mxY}, {mxX, mn}}
+	case PlacementEdges:
+		points = []Point{{mn, mdY}, {mxX, mdY}, {mdX, mn}, {mdX, mxY}}
+	case PlacementRandom:
+		for x := int32(0); x < b.Width; x++ {
+			for y := int32(0); y < b.Height; y++ {
+				if (x+y)%2 == 0 {
+					points = append(points, Point{x, y})
+				}
+			}
+		}
+		swap := func(i, j int) {
+			points[i], points[j] = points[j], points[i]
+		}
+		if r != nil {
+			r.Shuffle(len(points), swap)
+		} else {
+			rand.Shuffle(len(points), swap)
+		}
+	default:
+		return RulesetError("unknown placement strategy " + strategy)
+	}
+	if len(b.Snakes) > len(points) {
+		return ErrorTooManySnakes
+	}
+
+	occupied := make(map[Point]bool, len(b.Snakes))
+	for i := range b.Snakes {
+		body := make([]Point, len(b.Snakes[i].Body))
+		for j := range body {
+			body[j] = points[i]
+		}
+		b.Snakes[i].Body = body
+		occupied[points[i]] = true
+	}
+	food := b.Food[:0]
+	for _, f := range b.Food {
+		if !occupied[f] {
+			food = append(food, f)
+		}
+	}
+	b.Food = food
+	return nil
+}
@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositionSnakes(t *testing.T) {
+	board := func() *BoardState {
+		b, err := (&StandardRuleset{Rand: rand.New(rand.NewSource(1))}).CreateInitialBoardState(BoardSizeMedium, BoardSizeMedium, []string{"one", "two"})
+		require.NoError(t, err)
+		return b
+	}
+	heads := func(b *BoardState) []Point {
+		var heads []Point
+		for _, snake := range b.Snakes {
+			require.Len(t, snake.Body, SnakeStartSize)
+			require.True(t, isStacked(snake.Body))
+			heads = append(heads, snake.Body[0])
+		}
+		return heads
+	}
+
+	b := board()
+	require.NoError(t, RepositionSnakes(b, PlacementCorners, nil))
+	require.Equal(t, []Point{{1, 1}, {9, 9}}, heads(b))
+
+	b = board()
+	require.NoError(t, RepositionSnakes(b, PlacementEdges, nil))
+	require.Equal(t, []Point{{1, 5}, {9, 5}}, heads(b))
+
+	b = board()
+	require.NoError(t, RepositionSnakes(b, PlacementRandom, rand.New(rand.NewSource(2))))
+	random := heads(b)
+	require.NotEqual(t, random[0], random[1])
+	for _, p := range random {
+		require.Equal(t, int32(0), (p.X+p.Y)%2)
+		require.NotContains(t, b.Food, p)
+	}
+	again := board()
+	require.NoError(t, RepositionSnakes(again, PlacementRandom, rand.New(rand.NewSource(2))))
+	require.Equal(t, random, heads(again))
+
+	// Without a source, the global one is used.
+	b = board()
+	require.NoError(t, RepositionSnakes(b, PlacementRandom, nil))
+	require.NotEqual(t, heads(b)[0], heads(b)[1])
+
+	b = board()
+	require.EqualError(t, RepositionSnakes(b, "spiral", nil), "unknown placement strategy spiral")
+}
+
+func TestRepositionSnakesTooMany(t *testing.T) {
+	b := &BoardState{Width: 11, Height: 11, Snakes: make([]Snake, 5)}
+	require.Equal(t, ErrorTooManySnakes, RepositionSnakes(b, PlacementCorners, nil))
+}
+
+func TestRepositionSnakesRemovesFood(t *testing.T) {
+	b := &BoardState{
+		Width:  7,
+		Height: 7,
+		Food:   []Point{{1, 1}, {3, 3}},
+		Snakes: []Snake{{ID: "one", Body: []Point{{3, 1}, {3, 1}, {3, 1}}}},
+	}
+	require.NoError(t, RepositionSnakes(b, PlacementCorners, nil))
+	require.Equal(t, []Point{{1, 1}, {1, 1}, {1, 1}}, b.Snakes[0].Body)
+	require.Equal(t, []Point{{3, 3}}, b.Food)
+}
@@ -24,6 +24,7 @@ const (
 	EliminatedByOutOfHealth         = "out-of-health"
 	EliminatedByHeadToHeadCollision = "head-collision"
 	EliminatedByOutOfBounds         = "wall-collision"
+	EliminatedBySelfTrapped         = "self-trapped"
 
 	// TODO - Error consts
 	ErrorTooManySnakes   = RulesetError("too many snakes for fixed start positions")
@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"errors"
+)
+
+// SpiralHazardsRuleset wraps another ruleset and grows hazards in a spiral out from
+// the center of the board, adding a cell every GrowEveryNTurns turns.
+type SpiralHazardsRuleset struct {
+	Ruleset
+
+	Turn            int32
+	GrowEveryNTurns int32
+	DamagePerTurn   int32
+
+	// Output
+	Hazards []Point
+}
+
+func (r *SpiralHazardsRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	if r.GrowEveryNTurns < 1 {
+		return nil, errors.New("spiral hazards must grow at least every turn")
+	}
+
+	nextBoardState, err := r.Ruleset.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like royale, damage is applied for hazards of the previous turn.
+
+	// TODO: LOG?
+	r.populateHazards(nextBoardState, r.Turn-1)
+
+	// TODO: LOG?
+	err = r.damageHazards(nextBoardState)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	r.populateHazards(nextBoardState, r.Turn)
+
+	return nextBoardState, nil
+}
+
+func (r *SpiralHazardsRuleset) populateHazards(b *BoardState, turn int32) {
+	r.Hazards = []Point{}
+
+	numHazards := int(turn / r.GrowEveryNTurns)
+	if numHazards > int(b.Width*b.Height) {
+		numHazards = int(b.Width * b.Height)
+	}
+
+	// Walk right, up, left and down from the center, with each leg of the spiral
+	// one step longer every other turn, skipping cells off the board.
+	p := Point{b.Width / 2, b.Height / 2}
+	directions := []Point{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	for leg := 0; len(r.Hazards) < numHazards; leg++ {
+		d := directions[leg%len(directions)]
+		for i := 0; i <= leg/2 && len(r.Hazards) < numHazards; i++ {
+			if p.X >= 0 && p.X < b.Width && p.Y >= 0 && p.Y < b.Height {
+				r.Hazards = append(r.Hazards, p)
+			}
+			p = Point{p.X + d.X, p.Y + d.Y}
+		}
+	}
+}
+
+func (r *SpiralHazardsRuleset) damageHazards(b *BoardState) error {
+	if r.DamagePerTurn < 1 {
+		return errors.New("spiral hazard damage per turn must be greater than zero")
+	}
+
+	hazards := make(map[Point]bool, len(r.Hazards))
+	for _, p := range r.Hazards {
+		hazards[p] = true
+	}
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		if snake.EliminatedCause != NotEliminated || !hazards[snake.Body[0]] {
+			continue
+		}
+		snake.Health = snake.Health - r.DamagePerTurn
+		if snake.Health <= 0 {
+			snake.Health = 0
+			snake.EliminatedCause = EliminatedByOutOfHealth
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpiralHazardsRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*SpiralHazardsRuleset)(nil)
+}
+
+func TestSpiralHazardsDefaultSanity(t *testing.T) {
+	r := SpiralHazardsRuleset{Ruleset: &StandardRuleset{}}
+	_, err := r.CreateNextBoardState(&BoardState{}, []SnakeMove{})
+	require.Equal(t, errors.New("spiral hazards must grow at least every turn"), err)
+
+	r.GrowEveryNTurns = 1
+	_, err = r.CreateNextBoardState(&BoardState{}, []SnakeMove{})
+	require.Equal(t, errors.New("spiral hazard damage per turn must be greater than zero"), err)
+
+	r.DamagePerTurn = 1
+	_, err = r.CreateNextBoardState(&BoardState{}, []SnakeMove{})
+	require.NoError(t, err)
+}
+
+func TestSpiralHazardsGrowth(t *testing.T) {
+	b := &BoardState{Width: 5, Height: 5}
+	r := SpiralHazardsRuleset{GrowEveryNTurns: 2}
+
+	spiral := []Point{
+		{2, 2}, {3, 2}, {3, 3}, {2, 3}, {1, 3}, {1, 2}, {1, 1}, {2, 1}, {3, 1},
+		{4, 1}, {4, 2}, {4, 3}, {4, 4}, {3, 4}, {2, 4}, {1, 4}, {0, 4}, {0, 3},
+	}
+	for turn := int32(0); turn < int32(2*len(spiral)); turn++ {
+		r.populateHazards(b, turn)
+		require.Equal(t, spiral[:turn/2], r.Hazards, "turn %v", turn)
+	}
+
+	// The spiral stops growing once it covers the board, skipping cells off the board.
+	r.populateHazards(b, 1000)
+	require.Len(t, r.Hazards, 25)
+	seen := make(map[Point]bool)
+	for _, p := range r.Hazards {
+		require.False(t, seen[p])
+		seen[p] = true
+	}
+}
+
+func TestSpiralHazardsDamage(t *testing.T) {
+	r := SpiralHazardsRuleset{
+		Ruleset:         &StandardRuleset{},
+		Turn:            3,
+		GrowEveryNTurns: 1,
+		DamagePerTurn:   15,
+	}
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "one", Health: 100, Body: []Point{{2, 1}, {2, 0}, {1, 0}}},
+			{ID: "two", Health: 10, Body: []Point{{4, 2}, {4, 1}, {4, 0}}},
+			{ID: "three", Health: 100, Body: []Point{{0, 1}, {0, 0}, {1, 0}}},
+		},
+	}
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{
+		{ID: "one", Move: MoveUp},
+		{ID: "two", Move: MoveLeft},
+		{ID: "three", Move: MoveUp},
+	})
+	require.NoError(t, err)
+
+	// Damage is for the two hazards of the previous turn, the hazards are then grown for this turn.
+	require.Equal(t, int32(84), next.Snakes[0].Health)
+	require.Equal(t, int32(0), next.Snakes[1].Health)
+	require.Equal(t, EliminatedByOutOfHealth, next.Snakes[1].EliminatedCause)
+	require.Equal(t, int32(99), next.Snakes[2].Health)
+	require.Equal(t, []Point{{2, 2}, {3, 2}, {3, 3}}, r.Hazards)
+}
@@ -8,6 +8,13 @@ import (
 type StandardRuleset struct {
 	FoodSpawnChance int32 // [0, 100]
 	MinimumFood     int32
+
+	// Rand is used to place snakes and food, so that games can be played concurrently
+	// and still be reproduced. If nil, the global math/rand source is used.
+	Rand *rand.Rand `json:"-"`
+
+	// blocked are points that snakes and food are never placed on, such as hazard walls.
+	blocked []Point
 }
 
 func (r *StandardRuleset) CreateInitialBoardState(width int32, height int32, snakeIDs []string) (*BoardState, error) {
@@ -64,10 +71,24 @@ func (r *StandardRuleset) placeSnakesFixed(b *BoardState) error {
 	}
 
 	// Randomly order them
-	rand.Shuffle(len(startPoints), func(i int, j int) {
+	r.shuffle(len(startPoints), func(i int, j int) {
 		startPoints[i], startPoints[j] = startPoints[j], startPoints[i]
 	})
 
+	// Blocked points are skipped after shuffling, so the order is the same as without them
+	if len(r.blocked) > 0 {
+		open := []Point{}
+		for _, p := range startPoints {
+			if !r.isBlocked(p) {
+				open = append(open, p)
+			}
+		}
+		if len(b.Snakes) > len(open) {
+			return ErrorNoRoomForSnake
+		}
+		startPoints = open
+	}
+
 	// Assign to snakes in order given
 	for i := 0; i < len(b.Snakes); i++ {
 		for j := 0; j < SnakeStartSize; j++ {
@@ -85,7 +106,7 @@ func (r *StandardRuleset) placeSnakesRandomly(b *BoardState) error {
 		if len(unoccupiedPoints) <= 0 {
 			return ErrorNoRoomForSnake
 		}
-		p := unoccupiedPoints[rand.Intn(len(unoccupiedPoints))]
+		p := unoccupiedPoints[r.intn(len(unoccupiedPoints))]
 		for j := 0; j < SnakeStartSize; j++ {
 			b.Snakes[i].Body = append(b.Snakes[i].Body, p)
 		}
@@ -121,7 +142,7 @@ func (r *StandardRuleset) placeFoodFixed(b *BoardState) error {
 				}
 			}
 
-			if !isOccupiedAlready {
+			if !isOccupiedAlready && !r.isBlocked(p) {
 				availableFoodLocations = append(availableFoodLocations, p)
 			}
 		}
@@ -131,13 +152,16 @@ func (r *StandardRuleset) placeFoodFixed(b *BoardState) error {
 		}
 
 		// Select randomly from available locations
-		placedFood := availableFoodLocations[rand.Intn(len(availableFoodLocations))]
+		placedFood := availableFoodLocations[r.intn(len(availableFoodLocations))]
 		b.Food = append(b.Food, placedFood)
 	}
 
 	// Finally, always place 1 food in center of board for dramatic purposes
 	isCenterOccupied := true
 	centerCoord := Point{(b.Width - 1) / 2, (b.Height - 1) / 2}
+	if r.isBlocked(centerCoord) {
+		return nil
+	}
 	unoccupiedPoints := r.getUnoccupiedPoints(b, true)
 	for _, point := range unoccupiedPoints {
 		if point == centerCoord {
@@ -257,36 +281,16 @@ func (r *StandardRuleset) moveSnakes(b *BoardState, moves []SnakeMove) error {
 
 		for _, move := range moves {
 			if move.ID == snake.ID {
-				var newHead = Point{}
-				switch move.Move {
-				case MoveDown:
-					newHead.X = snake.Body[0].X
-					newHead.Y = snake.Body[0].Y - 1
-				case MoveLeft:
-					newHead.X = snake.Body[0].X - 1
-					newHead.Y = snake.Body[0].Y
-				case MoveRight:
-					newHead.X = snake.Body[0].X + 1
-					newHead.Y = snake.Body[0].Y
-				case MoveUp:
-					newHead.X = snake.Body[0].X
-					newHead.Y = snake.Body[0].Y + 1
-				default:
-					// Default to UP
-					var dX int32 = 0
-					var dY int32 = 1
-					// If neck is available, use neck to determine last direction
-					if len(snake.Body) >= 2 {
-						dX = snake.Body[0].X - snake.Body[1].X
-						dY = snake.Body[0].Y - snake.Body[1].Y
-						if dX == 0 && dY == 0 {
-							dY = 1 // Move up if no last move was made
-						}
+				dX, dY, ok := DirectionVector(move.Move)
+				if !ok {
+					// Invalid moves continue in the direction of the neck, or up if no last move was made
+					heading := HeadingAfter(snake.Body)
+					if heading == "" {
+						heading = MoveUp
 					}
-					// Apply
-					newHead.X = snake.Body[0].X + dX
-					newHead.Y = snake.Body[0].Y + dY
+					dX, dY, _ = DirectionVector(heading)
 				}
+				newHead := Point{X: snake.Body[0].X + dX, Y: snake.Body[0].Y + dY}
 
 				// Append new head, pop old tail
 				snake.Body = append([]Point{newHead}, snake.Body[:len(snake.Body)-1]...)
@@ -502,7 +506,7 @@ func (r *StandardRuleset) maybeSpawnFood(b *BoardState) error {
 	numCurrentFood := int32(len(b.Food))
 	if numCurrentFood < r.MinimumFood {
 		return r.spawnFood(b, r.MinimumFood-numCurrentFood)
-	} else if r.FoodSpawnChance > 0 && int32(rand.Intn(100)) < r.FoodSpawnChance {
+	} else if r.FoodSpawnChance > 0 && int32(r.intn(100)) < r.FoodSpawnChance {
 		return r.spawnFood(b, 1)
 	}
 	return nil
@@ -512,7 +516,7 @@ func (r *StandardRuleset) spawnFood(b *BoardState, n int32) error {
 	for i := int32(0); i < n; i++ {
 		unoccupiedPoints := r.getUnoccupiedPoints(b, false)
 		if len(unoccupiedPoints) > 0 {
-			newFood := unoccupiedPoints[rand.Intn(len(unoccupiedPoints))]
+			newFood := unoccupiedPoints[r.intn(len(unoccupiedPoints))]
 			b.Food = append(b.Food, newFood)
 		}
 	}
@@ -520,49 +524,42 @@ func (r *StandardRuleset) spawnFood(b *BoardState, n int32) error {
 }
 
 func (r *StandardRuleset) getUnoccupiedPoints(b *BoardState, includePossibleMoves bool) []Point {
-	pointIsOccupied := map[int32]map[int32]bool{}
-	for _, p := range b.Food {
-		if _, xExists := pointIsOccupied[p.X]; !xExists {
-			pointIsOccupied[p.X] = map[int32]bool{}
+	// A flat grid keeps this linear in the board area, which matters on 25x25 boards
+	// where food spawning runs it every turn.
+	pointIsOccupied := make([]bool, b.Width*b.Height)
+	markOccupied := func(p Point) {
+		if p.X < 0 || p.X >= b.Width || p.Y < 0 || p.Y >= b.Height {
+			return
 		}
-		pointIsOccupied[p.X][p.Y] = true
+		pointIsOccupied[p.X*b.Height+p.Y] = true
+	}
+	for _, p := range b.Food {
+		markOccupied(p)
+	}
+	for _, p := range r.blocked {
+		markOccupied(p)
 	}
 	for _, snake := range b.Snakes {
 		if snake.EliminatedCause != NotEliminated {
 			continue
 		}
 		for i, p := range snake.Body {
-			if _, xExists := pointIsOccupied[p.X]; !xExists {
-				pointIsOccupied[p.X] = map[int32]bool{}
-			}
-			pointIsOccupied[p.X][p.Y] = true
+			markOccupied(p)
 
 			if i == 0 && !includePossibleMoves {
-				nextMovePoints := []Point{
-					{X: p.X - 1, Y: p.Y},
-					{X: p.X + 1, Y: p.Y},
-					{X: p.X, Y: p.Y - 1},
-					{X: p.X, Y: p.Y + 1},
-				}
-				for _, nextP := range nextMovePoints {
-					if _, xExists := pointIsOccupied[nextP.X]; !xExists {
-						pointIsOccupied[nextP.X] = map[int32]bool{}
-					}
-					pointIsOccupied[nextP.X][nextP.Y] = true
-				}
+				markOccupied(Point{X: p.X - 1, Y: p.Y})
+				markOccupied(Point{X: p.X + 1, Y: p.Y})
+				markOccupied(Point{X: p.X, Y: p.Y - 1})
+				markOccupied(Point{X: p.X, Y: p.Y + 1})
 			}
 		}
 	}
 
-	unoccupiedPoints := []Point{}
+	unoccupiedPoints := make([]Point, 0, len(pointIsOccupied))
 	for x := int32(0); x < b.Width; x++ {
 		for y := int32(0); y < b.Height; y++ {
-			if _, xExists := pointIsOccupied[x]; xExists {
-				if isOccupied, yExists := pointIsOccupied[x][y]; yExists {
-					if isOccupied {
-						continue
-					}
-				}
+			if pointIsOccupied[x*b.Height+y] {
+				continue
 			}
 			unoccupiedPoints = append(unoccupiedPoints, Point{X: x, Y: y})
 		}
@@ -594,3 +591,27 @@ func (r *StandardRuleset) IsGameOver(b *BoardState) (bool, error) {
 	}
 	return numSnakesRemaining <= 1, nil
 }
+
+func (r *StandardRuleset) isBlocked(p Point) bool {
+	for _, blocked := range r.blocked {
+		if p == blocked {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *StandardRuleset) intn(n int) int {
+	if r.Rand != nil {
+		return r.Rand.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+func (r *StandardRuleset) shuffle(n int, swap func(i, j int)) {
+	if r.Rand != nil {
+		r.Rand.Shuffle(n, swap)
+		return
+	}
+	rand.Shuffle(n, swap)
+}
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"testing"
@@ -2011,3 +2012,52 @@ func TestIsGameOver(t *testing.T) {
 		require.Equal(t, test.Expected, actual)
 	}
 }
+
+// largeBoardState returns an official 25x25 board with 8 snakes of length 20 laid
+// out in horizontal rows, each with a free square above its head.
+func largeBoardState() (*BoardState, []SnakeMove) {
+	state := &BoardState{Width: 25, Height: 25}
+	var moves []SnakeMove
+	for i := int32(0); i < 8; i++ {
+		snake := Snake{ID: fmt.Sprintf("snake%d", i), Health: 100}
+		y := 1 + i*3
+		for x := int32(22); x > 2; x-- {
+			snake.Body = append(snake.Body, Point{X: x, Y: y})
+		}
+		state.Snakes = append(state.Snakes, snake)
+		moves = append(moves, SnakeMove{ID: snake.ID, Move: MoveUp})
+		state.Food = append(state.Food, Point{X: 0, Y: y}, Point{X: 24, Y: y + 1})
+	}
+	return state, moves
+}
+
+func TestStandardLargeBoardAllocs(t *testing.T) {
+	r := StandardRuleset{FoodSpawnChance: 100, Rand: rand.New(rand.NewSource(1))}
+	state, moves := largeBoardState()
+
+	var next *BoardState
+	allocs := testing.AllocsPerRun(100, func() {
+		var err error
+		next, err = r.CreateNextBoardState(state, moves)
+		require.NoError(t, err)
+	})
+	require.Len(t, next.Snakes, 8)
+	for _, snake := range next.Snakes {
+		require.Equal(t, NotEliminated, snake.EliminatedCause)
+	}
+	// A turn clones the board and moves every snake, so a handful of allocations per
+	// snake is expected; anything beyond this budget points at a per-square hotspot.
+	require.Less(t, allocs, float64(64))
+}
+
+func BenchmarkStandardCreateNextBoardState25x25(b *testing.B) {
+	r := StandardRuleset{FoodSpawnChance: 100, Rand: rand.New(rand.NewSource(1))}
+	state, moves := largeBoardState()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.CreateNextBoardState(state, moves); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
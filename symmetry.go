@@ -0,0 +1,91 @@
+package rules
+
+// IsStartSymmetric reports whether the snakes start in equivalent positions, that is whether
+// for every two snakes a rotation or reflection of the board maps the snakes onto each other
+// and the first snake onto the second. Boards with fewer than two snakes are symmetric.
+func IsStartSymmetric(state *BoardState) bool {
+	if len(state.Snakes) < 2 {
+		return true
+	}
+
+	var symmetries []func(Point) Point
+	for _, sym := range boardSymmetries(state.Width, state.Height) {
+		if mapsSnakesOntoSnakes(state.Snakes, sym) {
+			symmetries = append(symmetries, sym)
+		}
+	}
+
+	// The symmetries that keep the snakes in place form a group, so it's enough that the
+	// first snake can be moved onto every other snake.
+	for _, snake := range state.Snakes[1:] {
+		reached := false
+		for _, sym := range symmetries {
+			if bodiesEqual(mapBody(state.Snakes[0].Body, sym), snake.Body) {
+				reached = true
+				break
+			}
+		}
+		if !reached {
+			return false
+		}
+	}
+	return true
+}
+
+// boardSymmetries returns the rotations and reflections of the board, including the identity.
+// Rotations by a quarter turn and diagonal reflections only apply to square boards.
+func boardSymmetries(width, height int32) []func(Point) Point {
+	mx, my := width-1, height-1
+	symmetries := []func(Point) Point{
+		func(p Point) Point { return p },
+		func(p Point) Point { return Point{mx - p.X, p.Y} },
+		func(p Point) Point { return Point{p.X, my - p.Y} },
+		func(p Point) Point { return Point{mx - p.X, my - p.Y} },
+	}
+	if width == height {
+		symmetries = append(symmetries,
+			func(p Point) Point { return Point{p.Y, p.X} },
+			func(p Point) Point { return Point{mx - p.Y, my - p.X} },
+			func(p Point) Point { return Point{p.Y, mx - p.X} },
+			func(p Point) Point { return Point{my - p.Y, p.X} },
+		)
+	}
+	return symmetries
+}
+
+func mapsSnakesOntoSnakes(snakes []Snake, sym func(Point) Point) bool {
+	for _, snake := range snakes {
+		mapped := mapBody(snake.Body, sym)
+		found := false
+		for _, other := range snakes {
+			if bodiesEqual(mapped, other.Body) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func mapBody(body []Point, sym func(Point) Point) []Point {
+	mapped := make([]Point, len(body))
+	for i, p := range body {
+		mapped[i] = sym(p)
+	}
+	return mapped
+}
+
+func bodiesEqual(a, b []Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
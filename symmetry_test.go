@@ -0,0 +1,45 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stackedSnake(id string, p Point) Snake {
+	return Snake{ID: id, Health: SnakeMaxHealth, Body: []Point{p, p, p}}
+}
+
+func TestIsStartSymmetric(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int32
+		height    int32
+		snakes    []Snake
+		symmetric bool
+	}{
+		{"opposite corners", 7, 7, []Snake{stackedSnake("a", Point{1, 1}), stackedSnake("b", Point{5, 5})}, true},
+		{"mirrored", 11, 7, []Snake{stackedSnake("a", Point{1, 3}), stackedSnake("b", Point{9, 3})}, true},
+		{"quarter turns", 7, 7, []Snake{
+			stackedSnake("a", Point{1, 1}), stackedSnake("b", Point{5, 1}),
+			stackedSnake("c", Point{5, 5}), stackedSnake("d", Point{1, 5}),
+		}, true},
+		{"asymmetric", 7, 7, []Snake{stackedSnake("a", Point{1, 1}), stackedSnake("b", Point{1, 3})}, false},
+		{"diagonal on a rectangle", 11, 7, []Snake{stackedSnake("a", Point{1, 2}), stackedSnake("b", Point{2, 1})}, false},
+		{"one snake", 7, 7, []Snake{stackedSnake("a", Point{1, 3})}, true},
+		{"bodies", 7, 7, []Snake{
+			{ID: "a", Body: []Point{{1, 2}, {1, 1}, {1, 0}}},
+			{ID: "b", Body: []Point{{5, 2}, {5, 1}, {5, 0}}},
+		}, true},
+		{"bodies facing differently", 7, 7, []Snake{
+			{ID: "a", Body: []Point{{1, 2}, {1, 1}, {1, 0}}},
+			{ID: "b", Body: []Point{{5, 0}, {5, 1}, {5, 2}}},
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &BoardState{Width: test.width, Height: test.height, Snakes: test.snakes}
+			require.Equal(t, test.symmetric, IsStartSymmetric(state))
+		})
+	}
+}
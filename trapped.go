@@ -0,0 +1,87 @@
+package rules
+
+// SelfTrappedRuleset wraps another ruleset and eliminates snakes that have no safe move
+// before any snakes are moved, rather than letting them move into a wall or a body.
+type SelfTrappedRuleset struct {
+	Ruleset
+
+	// Wrapped is set for boards whose edges wrap around, where moving off an edge is safe.
+	Wrapped bool
+	// Blocked are points that are never safe to move to, like hazard walls.
+	Blocked []Point
+}
+
+func (r *SelfTrappedRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	// We specifically want to copy prevState, so as not to alter it directly.
+	state := *prevState
+	state.Snakes = append([]Snake{}, prevState.Snakes...)
+
+	// TODO: LOG?
+	err := r.eliminateTrappedSnakes(&state)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Ruleset.CreateNextBoardState(&state, moves)
+}
+
+func (r *SelfTrappedRuleset) eliminateTrappedSnakes(b *BoardState) error {
+	// Snakes are checked against the board before any eliminations are applied.
+	var trapped []int
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		if snake.EliminatedCause != NotEliminated {
+			continue
+		}
+		if len(snake.Body) == 0 {
+			return ErrorZeroLengthSnake
+		}
+		if !r.hasSafeMove(b, snake) {
+			trapped = append(trapped, i)
+		}
+	}
+
+	for _, i := range trapped {
+		b.Snakes[i].EliminatedCause = EliminatedBySelfTrapped
+		b.Snakes[i].EliminatedBy = b.Snakes[i].ID
+	}
+	return nil
+}
+
+func (r *SelfTrappedRuleset) hasSafeMove(b *BoardState, snake *Snake) bool {
+	occupied := map[Point]bool{}
+	for _, p := range r.Blocked {
+		occupied[p] = true
+	}
+	for _, other := range b.Snakes {
+		if other.EliminatedCause != NotEliminated {
+			continue
+		}
+		for i, p := range other.Body {
+			// Tails move out of the way, unless the snake has just eaten.
+			isTail := i == len(other.Body)-1
+			if isTail && i > 0 && other.Body[i-1] != p {
+				continue
+			}
+			occupied[p] = true
+		}
+	}
+
+	head := snake.Body[0]
+	for _, p := range []Point{
+		{head.X, head.Y + 1},
+		{head.X, head.Y - 1},
+		{head.X - 1, head.Y},
+		{head.X + 1, head.Y},
+	} {
+		if r.Wrapped {
+			p = WrapPoint(p, b.Width, b.Height)
+		} else if p.X < 0 || p.X >= b.Width || p.Y < 0 || p.Y >= b.Height {
+			continue
+		}
+		if !occupied[p] {
+			return true
+		}
+	}
+	return false
+}
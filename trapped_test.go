@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTrappedRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*SelfTrappedRuleset)(nil)
+}
+
+func TestSelfTrapped(t *testing.T) {
+	// The head is in the corner, enclosed by the wall and its own body.
+	prev := &BoardState{
+		Width:  3,
+		Height: 3,
+		Snakes: []Snake{
+			{ID: "trapped", Body: []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 2}}, Health: 100},
+			{ID: "free", Body: []Point{{2, 2}, {2, 1}, {2, 0}}, Health: 100},
+		},
+	}
+	moves := []SnakeMove{
+		{ID: "trapped", Move: MoveUp},
+		{ID: "free", Move: MoveLeft},
+	}
+
+	next, err := (&StandardRuleset{}).CreateNextBoardState(prev, moves)
+	require.NoError(t, err)
+	require.Equal(t, EliminatedBySelfCollision, next.Snakes[0].EliminatedCause)
+
+	r := SelfTrappedRuleset{Ruleset: &StandardRuleset{}}
+	next, err = r.CreateNextBoardState(prev, moves)
+	require.NoError(t, err)
+	require.Equal(t, EliminatedBySelfTrapped, next.Snakes[0].EliminatedCause)
+	require.Equal(t, "trapped", next.Snakes[0].EliminatedBy)
+	require.Equal(t, []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 2}}, next.Snakes[0].Body)
+	require.Equal(t, NotEliminated, next.Snakes[1].EliminatedCause)
+
+	// The previous state is not altered
+	require.Equal(t, NotEliminated, prev.Snakes[0].EliminatedCause)
+}
+
+func TestSelfTrappedTailIsSafe(t *testing.T) {
+	r := SelfTrappedRuleset{}
+
+	// The tail will move out of the way
+	b := &BoardState{
+		Width:  2,
+		Height: 2,
+		Snakes: []Snake{
+			{ID: "one", Body: []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, Health: 100},
+		},
+	}
+	require.True(t, r.hasSafeMove(b, &b.Snakes[0]))
+
+	// Unless the snake has just eaten
+	b.Snakes[0].Body = append(b.Snakes[0].Body, Point{0, 1})
+	require.False(t, r.hasSafeMove(b, &b.Snakes[0]))
+}
+
+func TestSelfTrappedWrapped(t *testing.T) {
+	// The only free points are across the left and bottom edges.
+	b := &BoardState{
+		Width:  3,
+		Height: 3,
+		Snakes: []Snake{
+			{ID: "edge", Body: []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 1}}, Health: 100},
+		},
+	}
+	require.False(t, (&SelfTrappedRuleset{}).hasSafeMove(b, &b.Snakes[0]))
+	require.True(t, (&SelfTrappedRuleset{Wrapped: true}).hasSafeMove(b, &b.Snakes[0]))
+
+	// Unless those are walls.
+	r := &SelfTrappedRuleset{Wrapped: true, Blocked: []Point{{2, 0}, {0, 2}}}
+	require.False(t, r.hasSafeMove(b, &b.Snakes[0]))
+}
+
+func TestSelfTrappedBlocked(t *testing.T) {
+	b := &BoardState{
+		Width:  3,
+		Height: 3,
+		Snakes: []Snake{
+			{ID: "one", Body: []Point{{1, 1}, {1, 0}, {1, 0}}, Health: 100},
+		},
+	}
+	r := &SelfTrappedRuleset{Blocked: []Point{{0, 1}, {2, 1}}}
+	require.True(t, r.hasSafeMove(b, &b.Snakes[0]))
+	r.Blocked = append(r.Blocked, Point{1, 2})
+	require.False(t, r.hasSafeMove(b, &b.Snakes[0]))
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"errors"
+)
+
+// HazardWallsRuleset is the standard ruleset with hazard walls, which damage any snake
+// that moves into them. With the default of SnakeMaxHealth damage, walls are lethal
+// and boards become mazes.
+type HazardWallsRuleset struct {
+	StandardRuleset
+
+	Walls      []Point
+	WallDamage int32
+}
+
+func (r *HazardWallsRuleset) CreateInitialBoardState(width int32, height int32, snakeIDs []string) (*BoardState, error) {
+	// Snakes and food are placed off the walls, so that no snake starts inside one.
+	standard := r.StandardRuleset
+	standard.blocked = r.Walls
+	return standard.CreateInitialBoardState(width, height, snakeIDs)
+}
+
+func (r *HazardWallsRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	if r.WallDamage < 1 {
+		return nil, errors.New("hazard wall damage must be greater than zero")
+	}
+
+	// Food is spawned off the walls, as in the initial state.
+	standard := r.StandardRuleset
+	standard.blocked = r.Walls
+	nextBoardState, err := standard.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	r.damageWalls(nextBoardState)
+
+	return nextBoardState, nil
+}
+
+func (r *HazardWallsRuleset) damageWalls(b *BoardState) {
+	walls := r.wallSet()
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		if snake.EliminatedCause != NotEliminated || !walls[snake.Body[0]] {
+			continue
+		}
+		snake.Health = snake.Health - r.WallDamage
+		if snake.Health <= 0 {
+			snake.Health = 0
+			snake.EliminatedCause = EliminatedByOutOfHealth
+		}
+	}
+}
+
+func (r *HazardWallsRuleset) wallSet() map[Point]bool {
+	walls := make(map[Point]bool, len(r.Walls))
+	for _, p := range r.Walls {
+		walls[p] = true
+	}
+	return walls
+}
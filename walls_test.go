@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHazardWallsRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*HazardWallsRuleset)(nil)
+}
+
+func TestHazardWallsDefaultSanity(t *testing.T) {
+	r := HazardWallsRuleset{}
+	_, err := r.CreateNextBoardState(&BoardState{}, []SnakeMove{})
+	require.Equal(t, errors.New("hazard wall damage must be greater than zero"), err)
+
+	r.WallDamage = SnakeMaxHealth
+	_, err = r.CreateNextBoardState(&BoardState{}, []SnakeMove{})
+	require.NoError(t, err)
+}
+
+func TestHazardWallsElimination(t *testing.T) {
+	r := HazardWallsRuleset{
+		Walls:      []Point{{2, 1}, {2, 2}, {2, 3}},
+		WallDamage: SnakeMaxHealth,
+	}
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Food:   []Point{{4, 4}},
+		Snakes: []Snake{
+			{ID: "one", Health: 100, Body: []Point{{1, 1}, {0, 1}, {0, 0}}},
+			{ID: "two", Health: 100, Body: []Point{{3, 4}, {3, 3}, {3, 2}}},
+		},
+	}
+	next, err := r.CreateNextBoardState(prev, []SnakeMove{
+		{ID: "one", Move: MoveRight},
+		{ID: "two", Move: MoveLeft},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, Point{2, 1}, next.Snakes[0].Body[0])
+	require.Equal(t, int32(0), next.Snakes[0].Health)
+	require.Equal(t, EliminatedByOutOfHealth, next.Snakes[0].EliminatedCause)
+	require.Equal(t, int32(99), next.Snakes[1].Health)
+	require.Equal(t, NotEliminated, next.Snakes[1].EliminatedCause)
+	require.Equal(t, []Point{{4, 4}}, next.Food)
+
+	r.WallDamage = 30
+	next, err = r.CreateNextBoardState(prev, []SnakeMove{
+		{ID: "one", Move: MoveRight},
+		{ID: "two", Move: MoveLeft},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(69), next.Snakes[0].Health)
+	require.Equal(t, NotEliminated, next.Snakes[0].EliminatedCause)
+}
+
+func TestHazardWallsFoodSpawn(t *testing.T) {
+	// Walls surround the snake's head, food is topped up around them.
+	walls := []Point{{1, 1}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}, {3, 3}}
+	for seed := int64(0); seed < 20; seed++ {
+		r := HazardWallsRuleset{
+			StandardRuleset: StandardRuleset{Rand: rand.New(rand.NewSource(seed)), MinimumFood: 10},
+			Walls:           walls,
+			WallDamage:      SnakeMaxHealth,
+		}
+		prev := &BoardState{
+			Width:  5,
+			Height: 5,
+			Snakes: []Snake{{ID: "one", Health: 100, Body: []Point{{1, 2}, {0, 2}, {0, 2}}}},
+		}
+		next, err := r.CreateNextBoardState(prev, []SnakeMove{{ID: "one", Move: MoveRight}})
+		require.NoError(t, err)
+		require.Len(t, next.Food, 10)
+		for _, food := range next.Food {
+			require.NotContains(t, walls, food, "seed %v", seed)
+		}
+	}
+}
+
+func TestHazardWallsInitialPlacement(t *testing.T) {
+	// The walls cover the corner start positions and the center food of the small board.
+	walls := []Point{{1, 1}, {1, 5}, {5, 1}, {5, 5}, {3, 3}}
+	for seed := int64(0); seed < 20; seed++ {
+		r := HazardWallsRuleset{
+			StandardRuleset: StandardRuleset{Rand: rand.New(rand.NewSource(seed))},
+			Walls:           walls,
+			WallDamage:      SnakeMaxHealth,
+		}
+		state, err := r.CreateInitialBoardState(BoardSizeSmall, BoardSizeSmall, []string{"one", "two", "three", "four"})
+		require.NoError(t, err)
+		require.Len(t, state.Food, 4)
+		for _, snake := range state.Snakes {
+			require.NotContains(t, walls, snake.Body[0], "seed %v", seed)
+		}
+		for _, food := range state.Food {
+			require.NotContains(t, walls, food, "seed %v", seed)
+		}
+	}
+
+	// Only four start positions are left.
+	r := HazardWallsRuleset{Walls: walls, WallDamage: SnakeMaxHealth}
+	_, err := r.CreateInitialBoardState(BoardSizeSmall, BoardSizeSmall, []string{"1", "2", "3", "4", "5"})
+	require.Equal(t, ErrorNoRoomForSnake, err)
+
+	// Random placement on other board sizes avoids the walls too.
+	walls = nil
+	for x := int32(0); x < 9; x++ {
+		for y := int32(0); y < 9; y++ {
+			if (x+y)%2 == 0 && x != 4 {
+				walls = append(walls, Point{x, y})
+			}
+		}
+	}
+	r = HazardWallsRuleset{StandardRuleset: StandardRuleset{Rand: rand.New(rand.NewSource(1))}, Walls: walls, WallDamage: SnakeMaxHealth}
+	state, err := r.CreateInitialBoardState(9, 9, []string{"one", "two"})
+	require.NoError(t, err)
+	for _, snake := range state.Snakes {
+		require.Equal(t, int32(4), snake.Body[0].X)
+	}
+	for _, food := range state.Food {
+		require.NotContains(t, walls, food)
+	}
+}
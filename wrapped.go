@@ -0,0 +1,91 @@
+package rules
+
+// WrappedRuleset is a standard game where snakes leaving one edge of the board re-enter on the opposite edge.
+type WrappedRuleset struct {
+	StandardRuleset
+}
+
+func (r *WrappedRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	// We specifically want to copy prevState, so as not to alter it directly.
+	nextState := &BoardState{
+		Height: prevState.Height,
+		Width:  prevState.Width,
+		Food:   append([]Point{}, prevState.Food...),
+		Snakes: make([]Snake, len(prevState.Snakes)),
+	}
+	for i := 0; i < len(prevState.Snakes); i++ {
+		nextState.Snakes[i].ID = prevState.Snakes[i].ID
+		nextState.Snakes[i].Health = prevState.Snakes[i].Health
+		nextState.Snakes[i].Body = append([]Point{}, prevState.Snakes[i].Body...)
+		nextState.Snakes[i].EliminatedCause = prevState.Snakes[i].EliminatedCause
+		nextState.Snakes[i].EliminatedBy = prevState.Snakes[i].EliminatedBy
+	}
+
+	// TODO: LOG?
+	err := r.moveSnakes(nextState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrap heads back onto the board before anything looks at their position.
+	// TODO: LOG?
+	err = r.wrapSnakes(nextState)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.reduceSnakeHealth(nextState)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.maybeFeedSnakes(nextState)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.maybeSpawnFood(nextState)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.maybeEliminateSnakes(nextState)
+	if err != nil {
+		return nil, err
+	}
+
+	return nextState, nil
+}
+
+func (r *WrappedRuleset) wrapSnakes(b *BoardState) error {
+	if b.Width < 1 || b.Height < 1 {
+		return nil
+	}
+	for i := 0; i < len(b.Snakes); i++ {
+		snake := &b.Snakes[i]
+		if snake.EliminatedCause != NotEliminated {
+			continue
+		}
+		for j := range snake.Body {
+			snake.Body[j] = WrapPoint(snake.Body[j], b.Width, b.Height)
+		}
+	}
+	return nil
+}
+
+// WrapPoint returns the point on a wrapped board of the given size that p corresponds to.
+func WrapPoint(p Point, width int32, height int32) Point {
+	return Point{X: wrap(p.X, width), Y: wrap(p.Y, height)}
+}
+
+func wrap(v int32, size int32) int32 {
+	v = v % size
+	if v < 0 {
+		v += size
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrappedRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*WrappedRuleset)(nil)
+}
+
+func TestWrappedCreateNextBoardState(t *testing.T) {
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "left", Body: []Point{{0, 2}, {1, 2}, {2, 2}}, Health: 100},
+			{ID: "up", Body: []Point{{3, 4}, {3, 3}, {3, 2}}, Health: 100},
+		},
+	}
+	moves := []SnakeMove{
+		{ID: "left", Move: MoveLeft},
+		{ID: "up", Move: MoveUp},
+	}
+
+	r := WrappedRuleset{}
+	next, err := r.CreateNextBoardState(prev, moves)
+	require.NoError(t, err)
+	require.Equal(t, NotEliminated, next.Snakes[0].EliminatedCause)
+	require.Equal(t, []Point{{4, 2}, {0, 2}, {1, 2}}, next.Snakes[0].Body)
+	require.Equal(t, NotEliminated, next.Snakes[1].EliminatedCause)
+	require.Equal(t, []Point{{3, 0}, {3, 4}, {3, 3}}, next.Snakes[1].Body)
+
+	// The previous state is not altered
+	require.Equal(t, []Point{{0, 2}, {1, 2}, {2, 2}}, prev.Snakes[0].Body)
+}
+
+func TestWrappedCollisionAcrossEdge(t *testing.T) {
+	prev := &BoardState{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "one", Body: []Point{{0, 2}, {1, 2}, {2, 2}}, Health: 100},
+			{ID: "two", Body: []Point{{4, 3}, {4, 2}, {4, 1}, {4, 0}}, Health: 100},
+		},
+	}
+	moves := []SnakeMove{
+		{ID: "one", Move: MoveLeft},
+		{ID: "two", Move: MoveUp},
+	}
+
+	r := WrappedRuleset{}
+	next, err := r.CreateNextBoardState(prev, moves)
+	require.NoError(t, err)
+	require.Equal(t, EliminatedByCollision, next.Snakes[0].EliminatedCause)
+	require.Equal(t, "two", next.Snakes[0].EliminatedBy)
+}
+
+func TestWrapPoint(t *testing.T) {
+	tests := []struct {
+		Point    Point
+		Expected Point
+	}{
+		{Point{0, 0}, Point{0, 0}},
+		{Point{-1, 0}, Point{6, 0}},
+		{Point{7, 4}, Point{0, 4}},
+		{Point{3, -1}, Point{3, 4}},
+		{Point{3, 5}, Point{3, 0}},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.Expected, WrapPoint(test.Point, 7, 5))
+	}
+}
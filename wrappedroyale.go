@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"errors"
+)
+
+// WrappedRoyaleRuleset is a royale game on a wrapped board. Heads are wrapped back onto the
+// board before hazard damage is applied, so a snake wrapping across an edge is damaged by the
+// hazard on the cell it lands on, not the one it left.
+type WrappedRoyaleRuleset struct {
+	RoyaleRuleset
+}
+
+func (r *WrappedRoyaleRuleset) CreateNextBoardState(prevState *BoardState, moves []SnakeMove) (*BoardState, error) {
+	if r.ShrinkEveryNTurns < 1 {
+		return nil, errors.New("royale game must shrink at least every turn")
+	}
+
+	wrapped := WrappedRuleset{StandardRuleset: r.StandardRuleset}
+	nextBoardState, err := wrapped.CreateNextBoardState(prevState, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hazards are damaged and shrink the same as in royale, see RoyaleRuleset.CreateNextBoardState.
+
+	// TODO: LOG?
+	err = r.populateOutOfBounds(nextBoardState, r.Turn-1)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.damageOutOfBounds(nextBoardState)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: LOG?
+	err = r.populateOutOfBounds(nextBoardState, r.Turn)
+	if err != nil {
+		return nil, err
+	}
+
+	return nextBoardState, nil
+}
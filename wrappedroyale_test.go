@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrappedRoyaleRulesetInterface(t *testing.T) {
+	var _ Ruleset = (*WrappedRoyaleRuleset)(nil)
+}
+
+func TestWrappedRoyaleDefaultSanity(t *testing.T) {
+	r := WrappedRoyaleRuleset{}
+	_, err := r.CreateNextBoardState(&BoardState{}, []SnakeMove{})
+	require.Equal(t, errors.New("royale game must shrink at least every turn"), err)
+}
+
+func TestWrappedRoyaleHazardsAcrossEdges(t *testing.T) {
+	// With this seed the first shrink makes the left column hazardous.
+	r := WrappedRoyaleRuleset{RoyaleRuleset: RoyaleRuleset{
+		Seed:              25543234525,
+		Turn:              2,
+		ShrinkEveryNTurns: 1,
+		DamagePerTurn:     15,
+	}}
+	state := &BoardState{
+		Width:  7,
+		Height: 7,
+		Food:   []Point{},
+		Snakes: []Snake{
+			// Wraps from the right edge into the hazard.
+			{ID: "into", Health: 100, Body: []Point{{6, 1}, {5, 1}, {4, 1}}},
+			// Wraps from the hazard across the left edge.
+			{ID: "out", Health: 100, Body: []Point{{0, 5}, {1, 5}, {2, 5}}},
+		},
+	}
+	moves := []SnakeMove{
+		{ID: "into", Move: MoveRight},
+		{ID: "out", Move: MoveLeft},
+	}
+	next, err := r.CreateNextBoardState(state, moves)
+	require.NoError(t, err)
+
+	into, out := next.Snakes[0], next.Snakes[1]
+	require.Equal(t, NotEliminated, into.EliminatedCause)
+	require.Equal(t, []Point{{0, 1}, {6, 1}, {5, 1}}, into.Body)
+	require.Equal(t, int32(100-1-15), into.Health)
+
+	require.Equal(t, NotEliminated, out.EliminatedCause)
+	require.Equal(t, []Point{{6, 5}, {0, 5}, {1, 5}}, out.Body)
+	require.Equal(t, int32(100-1), out.Health)
+
+	require.Contains(t, r.OutOfBounds, Point{0, 1})
+	require.NotContains(t, r.OutOfBounds, Point{6, 5})
+
+	// The hazards are generated from the seed, the same as royale.
+	royale := r.RoyaleRuleset
+	_, err = royale.CreateNextBoardState(state, moves)
+	require.NoError(t, err)
+	require.Equal(t, royale.OutOfBounds, r.OutOfBounds)
+}
+
+func TestWrappedRoyaleEliminatesInHazard(t *testing.T) {
+	r := WrappedRoyaleRuleset{RoyaleRuleset: RoyaleRuleset{
+		Seed:              25543234525,
+		Turn:              2,
+		ShrinkEveryNTurns: 1,
+		DamagePerTurn:     15,
+	}}
+	state := &BoardState{
+		Width:  7,
+		Height: 7,
+		Food:   []Point{},
+		Snakes: []Snake{{ID: "one", Health: 10, Body: []Point{{6, 3}, {5, 3}, {4, 3}}}},
+	}
+	next, err := r.CreateNextBoardState(state, []SnakeMove{{ID: "one", Move: MoveRight}})
+	require.NoError(t, err)
+	require.Equal(t, EliminatedByOutOfHealth, next.Snakes[0].EliminatedCause)
+	require.Equal(t, int32(0), next.Snakes[0].Health)
+}